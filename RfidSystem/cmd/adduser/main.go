@@ -0,0 +1,88 @@
+// Package main implements a command for creating admin accounts.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/repositories"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// main creates an admin account, resets its password with -reset, or changes
+// its role with -set-role. The password is read from standard input so it does
+// not end up in shell history.
+func main() {
+	email := flag.String("email", "", "email address of the account")
+	role := flag.String("role", model.RoleRegistrar, "role of the account: super_admin, registrar, cashier, kiosk or faculty")
+	reset := flag.Bool("reset", false, "reset the password of an existing account")
+	setRole := flag.Bool("set-role", false, "change the role of an existing account to -role")
+	flag.Parse()
+
+	if *email == "" {
+		log.Fatal("-email is required")
+	}
+	if !model.IsValidRole(*role) {
+		log.Fatalf("Unknown role %q", *role)
+	}
+
+	repo, closeDB := openRepository()
+	defer closeDB()
+
+	if *setRole {
+		if err := repo.UpdateUserRole(*email, *role); err != nil {
+			log.Fatalf("Failed to change role: %v", err)
+		}
+		fmt.Printf("Role of %s set to %s\n", *email, *role)
+		return
+	}
+
+	fmt.Print("Password: ")
+	reader := bufio.NewReader(os.Stdin)
+	password, err := reader.ReadString('\n')
+	if err != nil && password == "" {
+		log.Fatalf("Failed to read password: %v", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if len(password) < 8 {
+		log.Fatal("Password must be at least 8 characters")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Fatalf("Failed to hash password: %v", err)
+	}
+
+	if *reset {
+		if err := repo.UpdateUserPassword(*email, string(hash)); err != nil {
+			log.Fatalf("Failed to reset password: %v", err)
+		}
+		fmt.Printf("Password reset for %s\n", *email)
+		return
+	}
+
+	if _, err := repo.CreateUser(*email, string(hash), *role); err != nil {
+		log.Fatalf("Failed to create user: %v", err)
+	}
+	fmt.Printf("Created %s user %s\n", *role, *email)
+}
+
+// openRepository connects to the database configured in the environment.
+func openRepository() (*repositories.RFIDRepository, func()) {
+	dbConfig, err := config.LoadDatabaseConfig()
+	if err != nil {
+		log.Fatalf("Failed to load database config: %v", err)
+	}
+	dbClient, err := repositories.NewDatabaseClient(dbConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	return repositories.NewRFIDRepository(dbClient), func() { dbClient.Close() }
+}
@@ -3,18 +3,25 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"net/http"
 	"time"
 )
 
 func main() {
+	rfid := flag.String("rfid", "ACLC-2023-001", "card UID to scan")
+	readerID := flag.String("reader", "", "registered reader ID the scan comes from")
+	apiKey := flag.String("key", "", "API key issued to the reader via POST /readers/:id/key")
+	flag.Parse()
+
 	client := &http.Client{}
 
 	for {
 		// Simulate RFID scan
 		rfidData := map[string]string{
-			"rfid": "ACLC-2023-001",
+			"rfid":      *rfid,
+			"reader_id": *readerID,
 		}
 
 		jsonData, err := json.Marshal(rfidData)
@@ -30,6 +37,8 @@ func main() {
 		}
 
 		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Reader-ID", *readerID)
+		req.Header.Set("X-Reader-Key", *apiKey)
 
 		resp, err := client.Do(req)
 		if err != nil {
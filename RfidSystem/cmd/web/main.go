@@ -12,8 +12,8 @@ import (
 
 	"rfidsystem/internal/config"
 	"rfidsystem/internal/handlers"
-	"rfidsystem/internal/model"
 	"rfidsystem/internal/repositories"
+	"rfidsystem/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -41,8 +41,10 @@ func main() {
 	app := configureApp(engine)
 
 	// Create handler with repository
+	appConfig := config.LoadAppConfig()
 	rfidRepo := repositories.NewRFIDRepository(dbClient)
-	handler := handlers.NewHandler(dbClient, rfidRepo)
+	rfidRepo.SetReceiptKey(appConfig.Billing.ReceiptKey)
+	handler := handlers.NewHandler(dbClient, rfidRepo, appConfig)
 
 	// Register all routes
 	registerRoutes(app, handler)
@@ -82,15 +84,11 @@ func initViewEngine() *html.Engine {
 	engine.Debug(true)
 
 	engine.AddFunc("lower", strings.ToLower)
-	engine.AddFunc("feesByCategory", func(fees []model.FeeBreakdown, category string) []model.FeeBreakdown {
-		var filtered []model.FeeBreakdown
-		for _, fee := range fees {
-			if fee.Category == category {
-				filtered = append(filtered, fee)
-			}
-		}
-		return filtered
-	})
+	engine.AddFunc("feesByCategory", services.FeesByCategory)
+	engine.AddFunc("feeCategories", services.FeeCategories)
+	engine.AddFunc("sumFees", services.SumFees)
+	engine.AddFunc("amount", services.FormatAmount)
+	engine.AddFunc("grade", services.FormatGrade)
 	// Format time as "YYYY-MM-DD hh:mm am/pm" without seconds
 	engine.AddFunc("formatTime", func(t *time.Time) string {
 		if t == nil {
@@ -148,34 +146,120 @@ func configureApp(engine *html.Engine) *fiber.App {
 }
 
 // registerRoutes maps URL paths to their corresponding handler functions
-// on the provided Fiber application instance.
+// on the provided Fiber application instance. Admin routes are wrapped with
+// the AuthRequired middleware and a permission check for the user's role,
+// card scan routes require reader credentials, and kiosk screens stay public.
 func registerRoutes(app *fiber.App, h *handlers.AppHandler) {
+	auth := handlers.AuthRequired()
+	can := handlers.RequirePermission
+	readerAuth := h.ReaderAuthRequired()
+
 	app.Get("/", h.HandleGetIndex)
 	app.Get("/docs", h.HandleDocs)
 	app.Get("/grades", h.HandleGrades)
 	app.Get("/grades/semester/:studentId", h.HandleSemesterGrades)
 	app.Get("/error", h.HandleError)
 	app.Get("/student-partial/:rfid", h.HandleStudentInfo)
-	app.Get("/students/v1", h.RetrieveStudentsHandler)
-	app.Get("/students/:id", h.GetStudentById)
+	app.Get("/student-photo/:id", h.HandleStudentPhoto)
+	app.Get("/students/:id/grades", auth, can(handlers.PermViewGrades), h.GetGrades)
+	app.Get("/students/:id/terms", auth, can(handlers.PermViewGrades), h.GetStudentTerms)
+	app.Get("/students/:id/transcript", auth, can(handlers.PermViewGrades), h.GetTranscript)
+	app.Get("/students/:id/transcript/print", auth, can(handlers.PermViewGrades), h.HandleTranscript)
+	app.Get("/students/:id/transcript/pdf", auth, can(handlers.PermViewGrades), h.HandleTranscriptPDF)
+	app.Get("/students/:id/bills", auth, can(handlers.PermViewBilling), h.GetBills)
+	app.Get("/students/:id/statement", auth, can(handlers.PermViewBilling), h.HandleStatement)
+	app.Get("/students/:id/statement/pdf", auth, can(handlers.PermViewBilling), h.HandleStatementPDF)
 	app.Get("/stream", h.HandleSSE)
-	app.Get("/log", h.HandleLog)
-	app.Get("/logs", h.HandleLog)
+	app.Get("/log", auth, can(handlers.PermViewLogs), h.HandleLog)
+	app.Get("/logs", auth, can(handlers.PermViewLogs), h.HandleLog)
 	// HTMX polling endpoint for log container
-	app.Get("/log/partial", h.HandleLogPartial)
+	app.Get("/log/partial", auth, can(handlers.PermViewLogs), h.HandleLogPartial)
 	// HTMX polling endpoint for stats cards
-	app.Get("/stats/partial", h.HandleStatsPartial)
+	app.Get("/stats/partial", auth, can(handlers.PermViewLogs), h.HandleStatsPartial)
 	// Endpoints for log controls
-	app.Post("/log/clear", h.HandleClearLogs)
-	app.Get("/log/export", h.HandleExportLogs)
-	app.Post("/card-scan", h.HandleCardScan)
-	app.Get("/card-scan-ws", websocket.New(h.HandleCardScanWS))
+	app.Post("/log/clear", auth, can(handlers.PermClearLogs), h.HandleClearLogs)
+	app.Get("/log/export", auth, can(handlers.PermExportLogs), h.HandleExportLogs)
+	app.Get("/cache/stats", auth, can(handlers.PermViewCacheStats), h.HandleCacheStats)
+	app.Post("/card-scan", readerAuth, h.HandleCardScan)
+	app.Get("/card-scan-ws", readerAuth, websocket.New(h.HandleCardScanWS))
 	app.Get("/ping", func(c *fiber.Ctx) error { return c.SendString("Fiber Web Server is running") })
 	app.Get("/bills", h.HandleBills)
 	// support HTMX POST navigation with hidden RFID
 	app.Post("/student-partial", h.HandleStudentInfo)
 	app.Post("/grades", h.HandleGrades)
 	app.Post("/bills", h.HandleBills)
+	app.Get("/card-blocked", h.HandleCardBlocked)
+
+	// Student records routes
+	app.Get("/api/v1/students", auth, can(handlers.PermViewStudents), h.HandleSearchStudents)
+	app.Post("/api/v1/students", auth, can(handlers.PermManageStudents), h.HandleSaveStudent)
+	app.Get("/api/v1/students/:id", auth, can(handlers.PermViewStudents), h.HandleGetStudent)
+	app.Post("/api/v1/students/:id", auth, can(handlers.PermManageStudents), h.HandleSaveStudent)
+	app.Post("/api/v1/students/:id/deactivate", auth, can(handlers.PermManageStudents), h.HandleDeactivateStudent)
+	app.Post("/api/v1/students/:id/reactivate", auth, can(handlers.PermManageStudents), h.HandleReactivateStudent)
+	app.Post("/api/v1/students/:id/photo", auth, can(handlers.PermManageStudents), h.HandleUploadStudentPhoto)
+	app.Delete("/api/v1/students/:id/photo", auth, can(handlers.PermManageStudents), h.HandleDeleteStudentPhoto)
+
+	// Bulk import routes; each kind of import checks its own permission
+	app.Get("/imports", auth, h.HandleListImports)
+	app.Post("/imports/:type", auth, h.HandleImport)
+
+	// Card registry routes
+	app.Post("/cards", auth, can(handlers.PermManageCards), h.HandleIssueCard)
+	app.Get("/cards/student/:id", auth, can(handlers.PermManageCards), h.HandleGetStudentCards)
+	app.Post("/cards/:uid/revoke", auth, can(handlers.PermManageCards), h.HandleRevokeCard)
+	app.Post("/cards/:uid/reinstate", auth, can(handlers.PermManageCards), h.HandleReinstateCard)
+
+	// Reader and kiosk registry routes
+	app.Get("/kiosks", auth, can(handlers.PermManageDevices), h.HandleListKiosks)
+	app.Post("/kiosks", auth, can(handlers.PermManageDevices), h.HandleSaveKiosk)
+	app.Get("/readers", auth, can(handlers.PermManageDevices), h.HandleListReaders)
+	app.Post("/readers", auth, can(handlers.PermManageDevices), h.HandleSaveReader)
+	app.Post("/readers/:id/key", auth, can(handlers.PermManageDevices), h.HandleIssueReaderKey)
+	app.Post("/readers/:id/key/revoke", auth, can(handlers.PermManageDevices), h.HandleRevokeReaderKey)
+
+	// Payment routes
+	app.Post("/assessments/:id/payments", auth, can(handlers.PermRecordPayments), h.HandleRecordPayment)
+	app.Get("/receipts/:number", auth, can(handlers.PermViewBilling), h.HandleGetReceipt)
+	app.Get("/receipts/:number/pdf", auth, can(handlers.PermPrintReceipts), h.HandleReceiptPDF)
+	app.Get("/billing/delinquent", auth, can(handlers.PermViewBilling), h.HandleDelinquentAccounts)
+	app.Get("/exams/permits", auth, can(handlers.PermViewPermits), h.HandleExamPermits)
+
+	// Discount and scholarship routes
+	app.Get("/discount-types", auth, can(handlers.PermViewBilling), h.HandleListDiscountTypes)
+	app.Post("/discount-types", auth, can(handlers.PermManageDiscounts), h.HandleSaveDiscountType)
+	app.Post("/discount-types/:id", auth, can(handlers.PermManageDiscounts), h.HandleSaveDiscountType)
+	app.Get("/assessments/:id/discounts", auth, can(handlers.PermViewBilling), h.HandleListAssessmentDiscounts)
+	app.Post("/assessments/:id/discounts", auth, can(handlers.PermManageDiscounts), h.HandleGrantDiscount)
+	app.Post("/assessments/:id/discounts/:discountId/revoke", auth, can(handlers.PermManageDiscounts), h.HandleRevokeDiscount)
+
+	// Assessment generation routes
+	app.Post("/students/:id/assessments", auth, can(handlers.PermManageAssessments), h.HandleGenerateAssessment)
+	app.Get("/fee-schedules", auth, can(handlers.PermViewBilling), h.HandleListFeeSchedules)
+	app.Post("/fee-schedules", auth, can(handlers.PermManageAssessments), h.HandleSaveFeeSchedule)
+	app.Post("/fee-schedules/:id", auth, can(handlers.PermManageAssessments), h.HandleSaveFeeSchedule)
+
+	// Academic term routes
+	app.Get("/terms", auth, can(handlers.PermManageTerms), h.HandleListAcademicTerms)
+	app.Post("/terms", auth, can(handlers.PermManageTerms), h.HandleSaveAcademicTerm)
+	app.Post("/terms/active/clear", auth, can(handlers.PermManageTerms), h.HandleClearActiveTerm)
+	app.Post("/terms/:id", auth, can(handlers.PermManageTerms), h.HandleSaveAcademicTerm)
+	app.Delete("/terms/:id", auth, can(handlers.PermManageTerms), h.HandleDeleteAcademicTerm)
+	app.Post("/terms/:id/activate", auth, can(handlers.PermManageTerms), h.HandleActivateTerm)
+
+	// Grade encoding routes
+	app.Get("/grades/roster", auth, can(handlers.PermEncodeGrades), h.HandleClassRoster)
+	app.Post("/grades/roster", auth, can(handlers.PermEncodeGrades), h.HandleSubmitGrades)
+	app.Get("/faculty/classes", auth, can(handlers.PermEncodeGrades), h.HandleFacultyClasses)
+	app.Post("/faculty-assignments", auth, can(handlers.PermManageGrades), h.HandleAssignFaculty)
+	app.Post("/terms/:id/grades/lock", auth, can(handlers.PermManageGrades), h.HandleLockTermGrades)
+	app.Post("/terms/:id/grades/unlock", auth, can(handlers.PermManageGrades), h.HandleUnlockTermGrades)
+	app.Post("/terms/:id/grade-deadline", auth, can(handlers.PermManageGrades), h.HandleSetGradeDeadline)
+
+	// Attendance routes
+	app.Get("/attendance/daily", auth, can(handlers.PermViewAttendance), h.HandleDailyAttendance)
+	app.Get("/attendance/export", auth, can(handlers.PermViewAttendance), h.HandleExportAttendance)
+	app.Get("/attendance/student/:id", auth, can(handlers.PermViewAttendance), h.HandleStudentAttendance)
 
 	// Authentication routes
 	app.Get("/login", h.LoginPageHandler())
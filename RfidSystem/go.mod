@@ -2,7 +2,11 @@ module rfidsystem
 
 go 1.23.4
 
-require github.com/joho/godotenv v1.5.1
+require (
+	github.com/go-pdf/fpdf v0.9.0
+	github.com/joho/godotenv v1.5.1
+	golang.org/x/crypto v0.31.0
+)
 
 require (
 	github.com/fasthttp/websocket v1.5.3 // indirect
@@ -0,0 +1,117 @@
+// Package cache holds the caches of kiosk views. A cache is either kept in the
+// server's memory or shared between servers in Redis; both implement Cache, so
+// the in-memory cache can stand in for Redis in tests and single-server setups.
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Cache backends.
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Cache stores values of type T by key for a limited time. Values can be tagged,
+// e.g. with the student they show, so that every value with a tag is dropped at
+// once when the data behind it changes. Caches are safe for concurrent use.
+type Cache[T any] interface {
+	// Get returns the value stored under key, if it is there and not expired.
+	Get(key string) (T, bool)
+	// Set stores value under key with the given tags, replacing any value already
+	// stored under key.
+	Set(key string, value T, tags ...string)
+	// Delete removes the value stored under key.
+	Delete(key string)
+	Invalidator
+}
+
+// Invalidator is the part of a Cache that does not depend on its value type.
+type Invalidator interface {
+	// InvalidateTag removes every value stored with tag.
+	InvalidateTag(tag string)
+	// Clear removes every value.
+	Clear()
+	// Stats returns the cache's counters since the server started.
+	Stats() Stats
+}
+
+// Stats holds the counters of a cache.
+type Stats struct {
+	Name    string `json:"name"`
+	Backend string `json:"backend"`
+	// Entries is the number of values held, or -1 if the backend cannot tell.
+	Entries int    `json:"entries"`
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	// Evictions counts values dropped to make room for others and Expirations
+	// values dropped because they were too old. Redis evicts and expires values
+	// on its own, so both stay 0 for the Redis backend.
+	Evictions   uint64 `json:"evictions"`
+	Expirations uint64 `json:"expirations"`
+	// Errors counts failed requests to the backend, which are treated as misses.
+	Errors uint64 `json:"errors"`
+}
+
+// counters holds the counters shared by every backend.
+type counters struct {
+	hits, misses, evictions, expirations, errors atomic.Uint64
+}
+
+func (c *counters) stats(name, backend string, entries int) Stats {
+	return Stats{
+		Name:        name,
+		Backend:     backend,
+		Entries:     entries,
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+		Errors:      c.errors.Load(),
+	}
+}
+
+// Registry holds a set of caches so that a tag can be invalidated in all of them.
+type Registry struct {
+	mu     sync.RWMutex
+	caches []Invalidator
+}
+
+// Register adds c to the registry and returns it.
+func Register[C Invalidator](r *Registry, c C) C {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.caches = append(r.caches, c)
+	return c
+}
+
+// InvalidateTag removes every value stored with tag from every cache.
+func (r *Registry) InvalidateTag(tag string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.caches {
+		c.InvalidateTag(tag)
+	}
+}
+
+// Clear removes every value from every cache.
+func (r *Registry) Clear() {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.caches {
+		c.Clear()
+	}
+}
+
+// Stats returns the counters of every cache, in the order they were registered.
+func (r *Registry) Stats() []Stats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stats := make([]Stats, 0, len(r.caches))
+	for _, c := range r.caches {
+		stats = append(stats, c.Stats())
+	}
+	return stats
+}
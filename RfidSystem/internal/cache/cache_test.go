@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testValue struct {
+	Name  *string
+	Fee   *float64
+	Items []int
+}
+
+func newTestValue(name string) *testValue {
+	return &testValue{Name: &name}
+}
+
+// newCaches returns two empty caches of the same backend that keep values for ttl.
+type newCaches func(t *testing.T, ttl time.Duration) (Cache[*testValue], Cache[*testValue])
+
+// testCacheContract checks the behavior every Cache backend must share.
+func testCacheContract(t *testing.T, newCaches newCaches) {
+	t.Run("get and set", func(t *testing.T) {
+		c, _ := newCaches(t, time.Minute)
+		if _, ok := c.Get("a"); ok {
+			t.Fatal("Get hit an empty cache")
+		}
+		zero := 0.0
+		want := &testValue{Name: new(string), Fee: &zero, Items: []int{1, 2}}
+		c.Set("a", want)
+		got, ok := c.Get("a")
+		if !ok || !reflect.DeepEqual(got, want) {
+			t.Fatalf("Get = %+v, %v; want %+v", got, ok, want)
+		}
+
+		c.Set("a", newTestValue("Ben"))
+		if got, ok := c.Get("a"); !ok || *got.Name != "Ben" {
+			t.Fatalf("Get after replacing = %+v, %v; want Ben", got, ok)
+		}
+		c.Delete("a")
+		if _, ok := c.Get("a"); ok {
+			t.Fatal("Get hit a deleted value")
+		}
+
+		stats := c.Stats()
+		if stats.Hits != 2 || stats.Misses != 2 || stats.Errors != 0 {
+			t.Errorf("Stats = %+v, want 2 hits, 2 misses and no errors", stats)
+		}
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		c, _ := newCaches(t, 50*time.Millisecond)
+		c.Set("a", newTestValue("Ana"))
+		if _, ok := c.Get("a"); !ok {
+			t.Fatal("Get missed a fresh value")
+		}
+		time.Sleep(80 * time.Millisecond)
+		if _, ok := c.Get("a"); ok {
+			t.Fatal("Get hit an expired value")
+		}
+	})
+
+	t.Run("invalidate tag", func(t *testing.T) {
+		c, _ := newCaches(t, time.Minute)
+		c.Set("a", newTestValue("Ana"), "student:1")
+		c.Set("b", newTestValue("Ben"), "student:1", "student:2")
+		c.Set("c", newTestValue("Cid"), "student:2")
+		c.Set("d", newTestValue("Dee"))
+
+		c.InvalidateTag("student:1")
+		for key, want := range map[string]bool{"a": false, "b": false, "c": true, "d": true} {
+			if _, ok := c.Get(key); ok != want {
+				t.Errorf("Get(%q) hit = %v, want %v", key, ok, want)
+			}
+		}
+		// Values stored with the tag again are invalidated again
+		c.Set("a", newTestValue("Ana"), "student:1")
+		c.InvalidateTag("student:1")
+		if _, ok := c.Get("a"); ok {
+			t.Error("Get hit a value invalidated a second time")
+		}
+	})
+
+	t.Run("clear", func(t *testing.T) {
+		c, other := newCaches(t, time.Minute)
+		c.Set("a", newTestValue("Ana"), "student:1")
+		c.Set("b", newTestValue("Ben"))
+		other.Set("a", newTestValue("Ana"))
+
+		c.Clear()
+		for _, key := range []string{"a", "b"} {
+			if _, ok := c.Get(key); ok {
+				t.Errorf("Get(%q) hit a cleared cache", key)
+			}
+		}
+		if _, ok := other.Get("a"); !ok {
+			t.Error("Clear removed a value of another cache")
+		}
+		c.Set("a", newTestValue("Ana"))
+		if _, ok := c.Get("a"); !ok {
+			t.Error("Get missed a value stored after Clear")
+		}
+	})
+}
+
+func TestMemoryCacheContract(t *testing.T) {
+	testCacheContract(t, func(t *testing.T, ttl time.Duration) (Cache[*testValue], Cache[*testValue]) {
+		return NewMemory[*testValue]("first", 100, ttl), NewMemory[*testValue]("second", 100, ttl)
+	})
+}
+
+func TestMemoryEvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewMemory[*testValue]("values", 2, time.Minute)
+	c.Set("a", newTestValue("Ana"), "student:1")
+	c.Set("b", newTestValue("Ben"))
+	c.Get("a")
+	c.Set("c", newTestValue("Cid"))
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Get(%q) hit = %v, want %v", key, ok, want)
+		}
+	}
+	if stats := c.Stats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("Stats = %+v, want 1 eviction and 2 entries", stats)
+	}
+}
@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type memoryItem[T any] struct {
+	key       string
+	value     T
+	tags      []string
+	expiresAt time.Time
+}
+
+// Memory is an LRU cache kept in the server's memory, with a time-to-live (TTL)
+// for its values. When it is full, the least recently used value is evicted.
+type Memory[T any] struct {
+	name     string
+	capacity int
+	ttl      time.Duration
+	mu       sync.Mutex
+	items    map[string]*list.Element
+	order    *list.List // Front is most recent
+	// tagged maps each tag to the keys of the values stored with it.
+	tagged map[string]map[string]struct{}
+	counters
+}
+
+// NewMemory creates an in-memory cache that holds at most capacity values, each
+// for at most ttl.
+func NewMemory[T any](name string, capacity int, ttl time.Duration) *Memory[T] {
+	return &Memory[T]{
+		name:     name,
+		capacity: max(capacity, 1),
+		ttl:      ttl,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		tagged:   make(map[string]map[string]struct{}),
+	}
+}
+
+// Get returns the value stored under key, if it is there and not expired.
+func (c *Memory[T]) Get(key string) (T, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var zero T
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses.Add(1)
+		return zero, false
+	}
+	item := elem.Value.(*memoryItem[T])
+	if time.Now().After(item.expiresAt) {
+		c.remove(elem)
+		c.expirations.Add(1)
+		c.misses.Add(1)
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	c.hits.Add(1)
+	return item.value, true
+}
+
+// Set stores value under key with the given tags. If the cache is then over its
+// capacity, the least recently used value is evicted.
+func (c *Memory[T]) Set(key string, value T, tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+	item := &memoryItem[T]{
+		key:       key,
+		value:     value,
+		tags:      tags,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+	c.items[key] = c.order.PushFront(item)
+	for _, tag := range tags {
+		if c.tagged[tag] == nil {
+			c.tagged[tag] = make(map[string]struct{})
+		}
+		c.tagged[tag][key] = struct{}{}
+	}
+	if c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// Delete removes the value stored under key.
+func (c *Memory[T]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+}
+
+// InvalidateTag removes every value stored with tag.
+func (c *Memory[T]) InvalidateTag(tag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.tagged[tag] {
+		c.remove(c.items[key])
+	}
+}
+
+// Clear removes every value.
+func (c *Memory[T]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+	c.tagged = make(map[string]map[string]struct{})
+}
+
+// Stats returns the cache's counters.
+func (c *Memory[T]) Stats() Stats {
+	c.mu.Lock()
+	entries := len(c.items)
+	c.mu.Unlock()
+	return c.stats(c.name, BackendMemory, entries)
+}
+
+// remove unlinks an item from the LRU list, the key index and its tags.
+// The caller must hold c.mu.
+func (c *Memory[T]) remove(elem *list.Element) {
+	item := elem.Value.(*memoryItem[T])
+	c.order.Remove(elem)
+	delete(c.items, item.key)
+	for _, tag := range item.tags {
+		delete(c.tagged[tag], item.key)
+		if len(c.tagged[tag]) == 0 {
+			delete(c.tagged, tag)
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisClient is a minimal client for Redis and servers speaking its protocol
+// (RESP), e.g. Valkey or KeyDB. It keeps a small pool of connections.
+type RedisClient struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// redisPoolSize is the number of idle connections a RedisClient keeps open.
+const redisPoolSize = 8
+
+// NewRedisClient creates a client for the Redis server at addr (host:port). The
+// password may be empty; db selects the logical database. No connection is made
+// until the first command.
+func NewRedisClient(addr, password string, db int) *RedisClient {
+	return &RedisClient{
+		addr:     addr,
+		password: password,
+		db:       db,
+		timeout:  2 * time.Second,
+		idle:     make(chan *redisConn, redisPoolSize),
+	}
+}
+
+// Ping checks that the server can be reached.
+func (c *RedisClient) Ping() error {
+	_, err := c.Do("PING")
+	return err
+}
+
+// Do sends a command and returns its reply: a string, an int64, a []any of
+// replies, or nil for a null reply. Error replies are returned as errors, or as
+// error values within a []any.
+func (c *RedisClient) Do(args ...string) (any, error) {
+	return c.send([][]string{args}, false)
+}
+
+// Transaction runs commands atomically in a MULTI/EXEC block and returns the
+// []any of their replies. It fails if any command is rejected.
+func (c *RedisClient) Transaction(commands ...[]string) ([]any, error) {
+	reply, err := c.send(commands, true)
+	if err != nil {
+		return nil, err
+	}
+	replies, ok := reply.([]any)
+	if !ok {
+		return nil, fmt.Errorf("redis transaction was aborted")
+	}
+	for _, r := range replies {
+		if err, ok := r.(error); ok {
+			return nil, err
+		}
+	}
+	return replies, nil
+}
+
+// send sends commands on one connection, in a MULTI/EXEC block if multi is set,
+// and returns the reply of the last one.
+func (c *RedisClient) send(commands [][]string, multi bool) (any, error) {
+	for {
+		rc, pooled, err := c.conn()
+		if err != nil {
+			return nil, err
+		}
+		reply, err := rc.pipeline(c.timeout, commands, multi)
+		var replyErr redisError
+		if err != nil && !errors.As(err, &replyErr) {
+			// The connection may be out of step with the server; do not reuse it
+			rc.conn.Close()
+			if pooled {
+				// The server may have closed the idle connection; retry on a new one
+				continue
+			}
+			return nil, err
+		}
+		select {
+		case c.idle <- rc:
+		default:
+			rc.conn.Close()
+		}
+		return reply, err
+	}
+}
+
+// conn returns an idle connection and true, or a new connection and false if
+// there is none.
+func (c *RedisClient) conn() (*redisConn, bool, error) {
+	select {
+	case rc := <-c.idle:
+		return rc, true, nil
+	default:
+	}
+	conn, err := net.DialTimeout("tcp", c.addr, c.timeout)
+	if err != nil {
+		return nil, false, fmt.Errorf("error connecting to redis at %s: %v", c.addr, err)
+	}
+	rc := &redisConn{conn: conn, reader: bufio.NewReader(conn)}
+	if c.password != "" {
+		if _, err := rc.do(c.timeout, "AUTH", c.password); err != nil {
+			conn.Close()
+			return nil, false, fmt.Errorf("error authenticating to redis: %v", err)
+		}
+	}
+	if c.db != 0 {
+		if _, err := rc.do(c.timeout, "SELECT", strconv.Itoa(c.db)); err != nil {
+			conn.Close()
+			return nil, false, fmt.Errorf("error selecting redis database %d: %v", c.db, err)
+		}
+	}
+	return rc, false, nil
+}
+
+// redisError is an error reply from the server. The connection stays usable.
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+func (rc *redisConn) do(timeout time.Duration, args ...string) (any, error) {
+	return rc.pipeline(timeout, [][]string{args}, false)
+}
+
+// pipeline writes commands at once, wrapped in MULTI and EXEC if multi is set,
+// then reads every reply and returns the last one. Every reply is read even if
+// an earlier one is an error, so that the connection stays in step.
+func (rc *redisConn) pipeline(timeout time.Duration, commands [][]string, multi bool) (any, error) {
+	if err := rc.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	if multi {
+		commands = append(append([][]string{{"MULTI"}}, commands...), []string{"EXEC"})
+	}
+	var buf bytes.Buffer
+	for _, args := range commands {
+		fmt.Fprintf(&buf, "*%d\r\n", len(args))
+		for _, arg := range args {
+			fmt.Fprintf(&buf, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	if _, err := rc.conn.Write(buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("error writing to redis: %v", err)
+	}
+	var reply any
+	var replyErr error
+	for range commands {
+		r, err := readRESP(rc.reader)
+		var redisErr redisError
+		if err != nil && !errors.As(err, &redisErr) {
+			return nil, err
+		}
+		if err != nil && replyErr == nil {
+			replyErr = err
+		}
+		reply = r
+	}
+	if replyErr != nil {
+		return nil, replyErr
+	}
+	return reply, nil
+}
+
+// readRESP reads one reply of the Redis serialization protocol.
+func readRESP(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("error reading from redis: %v", err)
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("invalid redis reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		n, err := strconv.ParseInt(body, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis integer %q", body)
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < -1 {
+			return nil, fmt.Errorf("invalid redis bulk length %q", body)
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, fmt.Errorf("error reading from redis: %v", err)
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil || n < -1 {
+			return nil, fmt.Errorf("invalid redis array length %q", body)
+		}
+		if n == -1 {
+			return nil, nil
+		}
+		items := make([]any, n)
+		for i := range items {
+			item, err := readRESP(r)
+			var replyErr redisError
+			if errors.As(err, &replyErr) {
+				// Keep reading the rest of the array, e.g. the replies of EXEC
+				items[i] = replyErr
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("invalid redis reply %q", line)
+}
+
+// Redis is a cache stored in Redis, so that several servers share it. Values are
+// encoded as JSON, so only the fields encoding/json marshals are kept. Redis
+// expires values itself and evicts them according to its maxmemory policy. When
+// Redis cannot be reached, every lookup misses and the error is logged.
+type Redis[T any] struct {
+	name   string
+	client *RedisClient
+	ttl    time.Duration
+	// keys and tags are the prefixes of the cache's value keys and tag sets.
+	keys, tags string
+	// logMu limits error logging to one message per minute.
+	logMu     sync.Mutex
+	lastLogAt time.Time
+	counters
+}
+
+// NewRedis creates a cache named name in Redis that keeps each value for ttl.
+// Caches sharing a client must have different names.
+func NewRedis[T any](name string, client *RedisClient, ttl time.Duration) *Redis[T] {
+	return &Redis[T]{
+		name:   name,
+		client: client,
+		ttl:    ttl,
+		keys:   "rfidsystem:" + name + ":k:",
+		tags:   "rfidsystem:" + name + ":t:",
+	}
+}
+
+// Get returns the value stored under key, if it is there and not expired.
+func (c *Redis[T]) Get(key string) (T, bool) {
+	var value T
+	reply, err := c.client.Do("GET", c.keys+key)
+	if err != nil {
+		c.failed("GET", err)
+		c.misses.Add(1)
+		return value, false
+	}
+	data, ok := reply.(string)
+	if !ok {
+		c.misses.Add(1)
+		return value, false
+	}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		c.failed("decode", err)
+		c.misses.Add(1)
+		return value, false
+	}
+	c.hits.Add(1)
+	return value, true
+}
+
+// Set stores value under key with the given tags. Each tag is a Redis set of the
+// keys stored with it, kept as long as the values it lists. The value and its
+// tags are written in one transaction, so a value is never stored without being
+// linked to its tags.
+func (c *Redis[T]) Set(key string, value T, tags ...string) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.failed("encode", err)
+		return
+	}
+	ttl := strconv.FormatInt(max(c.ttl.Milliseconds(), 1), 10)
+	var commands [][]string
+	for _, tag := range tags {
+		commands = append(commands,
+			[]string{"SADD", c.tags + tag, key},
+			[]string{"PEXPIRE", c.tags + tag, ttl})
+	}
+	commands = append(commands, []string{"SET", c.keys + key, string(data), "PX", ttl})
+	if _, err := c.client.Transaction(commands...); err != nil {
+		c.failed("SET", err)
+	}
+}
+
+// Delete removes the value stored under key.
+func (c *Redis[T]) Delete(key string) {
+	if _, err := c.client.Do("DEL", c.keys+key); err != nil {
+		c.failed("DEL", err)
+	}
+}
+
+// InvalidateTag removes every value stored with tag.
+func (c *Redis[T]) InvalidateTag(tag string) {
+	reply, err := c.client.Do("SMEMBERS", c.tags+tag)
+	if err != nil {
+		c.failed("SMEMBERS", err)
+		return
+	}
+	members, _ := reply.([]any)
+	args := []string{"DEL", c.tags + tag}
+	for _, member := range members {
+		if key, ok := member.(string); ok {
+			args = append(args, c.keys+key)
+		}
+	}
+	if _, err := c.client.Do(args...); err != nil {
+		c.failed("DEL", err)
+	}
+}
+
+// Clear removes every value of the cache, leaving other caches in the same
+// database alone.
+func (c *Redis[T]) Clear() {
+	cursor := "0"
+	for {
+		reply, err := c.client.Do("SCAN", cursor, "MATCH", "rfidsystem:"+c.name+":*", "COUNT", "500")
+		if err != nil {
+			c.failed("SCAN", err)
+			return
+		}
+		parts, _ := reply.([]any)
+		if len(parts) != 2 {
+			c.failed("SCAN", fmt.Errorf("unexpected reply %v", reply))
+			return
+		}
+		cursor, _ = parts[0].(string)
+		keys, _ := parts[1].([]any)
+		if len(keys) > 0 {
+			args := []string{"DEL"}
+			for _, key := range keys {
+				if k, ok := key.(string); ok {
+					args = append(args, k)
+				}
+			}
+			if _, err := c.client.Do(args...); err != nil {
+				c.failed("DEL", err)
+				return
+			}
+		}
+		if cursor == "0" || cursor == "" {
+			return
+		}
+	}
+}
+
+// Stats returns the cache's counters. Redis does not tell how many values a
+// single cache holds, so Entries is -1.
+func (c *Redis[T]) Stats() Stats {
+	return c.stats(c.name, BackendRedis, -1)
+}
+
+// failed counts a failed request and logs it, at most once a minute per cache
+// so that an unreachable server does not flood the log.
+func (c *Redis[T]) failed(op string, err error) {
+	c.errors.Add(1)
+	c.logMu.Lock()
+	defer c.logMu.Unlock()
+	if time.Since(c.lastLogAt) < time.Minute {
+		return
+	}
+	c.lastLogAt = time.Now()
+	log.Printf("Cache %s: redis %s failed: %v", c.name, op, err)
+}
@@ -0,0 +1,299 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"reflect"
+	"rfidsystem/internal/model"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a Redis stand-in speaking enough of RESP for the Redis cache:
+// strings with expiry, sets, SCAN and MULTI/EXEC.
+type fakeRedis struct {
+	listener net.Listener
+	mu       sync.Mutex
+	strings  map[string]string
+	sets     map[string]map[string]bool
+	expiry   map[string]time.Time
+	conns    map[net.Conn]bool
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &fakeRedis{
+		listener: listener,
+		strings:  make(map[string]string),
+		sets:     make(map[string]map[string]bool),
+		expiry:   make(map[string]time.Time),
+		conns:    make(map[net.Conn]bool),
+	}
+	go server.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		server.dropConnections()
+	})
+	return server
+}
+
+func (s *fakeRedis) addr() string {
+	return s.listener.Addr().String()
+}
+
+// dropConnections closes every open connection, as a server restart or an idle
+// timeout would.
+func (s *fakeRedis) dropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		conn.Close()
+		delete(s.conns, conn)
+	}
+}
+
+func (s *fakeRedis) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	var queued [][]string
+	inMulti := false
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		var reply string
+		switch name := strings.ToUpper(args[0]); {
+		case name == "MULTI":
+			inMulti, queued = true, nil
+			reply = "+OK\r\n"
+		case name == "EXEC":
+			replies := make([]string, len(queued))
+			s.mu.Lock()
+			for i, command := range queued {
+				replies[i] = s.execute(command)
+			}
+			s.mu.Unlock()
+			inMulti = false
+			reply = fmt.Sprintf("*%d\r\n%s", len(replies), strings.Join(replies, ""))
+		case inMulti:
+			queued = append(queued, args)
+			reply = "+QUEUED\r\n"
+		default:
+			s.mu.Lock()
+			reply = s.execute(args)
+			s.mu.Unlock()
+		}
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || line[0] != '*' {
+		return nil, fmt.Errorf("invalid command %q", line)
+	}
+	args := make([]string, count)
+	for i := range args {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(header[1:]))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func bulk(value string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+}
+
+func bulkArray(values []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(values))
+	for _, value := range values {
+		b.WriteString(bulk(value))
+	}
+	return b.String()
+}
+
+// execute runs a command and returns its encoded reply. The caller must hold s.mu.
+func (s *fakeRedis) execute(args []string) string {
+	s.expire()
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "AUTH", "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		value, ok := s.strings[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(value)
+	case "SET":
+		s.strings[args[1]] = args[2]
+		delete(s.expiry, args[1])
+		if len(args) == 5 && strings.EqualFold(args[3], "PX") {
+			ms, _ := strconv.Atoi(args[4])
+			s.expiry[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		return "+OK\r\n"
+	case "SADD":
+		if s.sets[args[1]] == nil {
+			s.sets[args[1]] = make(map[string]bool)
+		}
+		for _, member := range args[2:] {
+			s.sets[args[1]][member] = true
+		}
+		return ":1\r\n"
+	case "SMEMBERS":
+		var members []string
+		for member := range s.sets[args[1]] {
+			members = append(members, member)
+		}
+		return bulkArray(members)
+	case "PEXPIRE":
+		ms, _ := strconv.Atoi(args[2])
+		s.expiry[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		return ":1\r\n"
+	case "DEL":
+		for _, key := range args[1:] {
+			s.delete(key)
+		}
+		return fmt.Sprintf(":%d\r\n", len(args)-1)
+	case "SCAN":
+		// Every key is returned in one batch
+		pattern := "*"
+		if len(args) >= 4 && strings.EqualFold(args[2], "MATCH") {
+			pattern = args[3]
+		}
+		var keys []string
+		for _, key := range s.keys() {
+			if ok, _ := path.Match(pattern, key); ok {
+				keys = append(keys, key)
+			}
+		}
+		return "*2\r\n" + bulk("0") + bulkArray(keys)
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+}
+
+func (s *fakeRedis) keys() []string {
+	var keys []string
+	for key := range s.strings {
+		keys = append(keys, key)
+	}
+	for key := range s.sets {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (s *fakeRedis) delete(key string) {
+	delete(s.strings, key)
+	delete(s.sets, key)
+	delete(s.expiry, key)
+}
+
+func (s *fakeRedis) expire() {
+	now := time.Now()
+	for key, at := range s.expiry {
+		if now.After(at) {
+			s.delete(key)
+		}
+	}
+}
+
+func TestRedisCacheContract(t *testing.T) {
+	testCacheContract(t, func(t *testing.T, ttl time.Duration) (Cache[*testValue], Cache[*testValue]) {
+		client := NewRedisClient(newFakeRedis(t).addr(), "secret", 1)
+		return NewRedis[*testValue]("first", client, ttl), NewRedis[*testValue]("second", client, ttl)
+	})
+}
+
+func TestRedisRetriesBrokenPooledConnection(t *testing.T) {
+	server := newFakeRedis(t)
+	c := NewRedis[*testValue]("values", NewRedisClient(server.addr(), "", 0), time.Minute)
+	c.Set("a", newTestValue("Ana"))
+
+	// The pooled connection is now closed by the server
+	server.dropConnections()
+	if got, ok := c.Get("a"); !ok || *got.Name != "Ana" {
+		t.Fatalf("Get after the connection broke = %v, %v; want the stored value", got, ok)
+	}
+	if errors := c.Stats().Errors; errors != 0 {
+		t.Errorf("Errors = %d, want 0", errors)
+	}
+}
+
+func TestRedisUnreachableMisses(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	c := NewRedis[*testValue]("values", NewRedisClient(addr, "", 0), time.Minute)
+	c.Set("a", newTestValue("Ana"))
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("Get hit while Redis is unreachable")
+	}
+	stats := c.Stats()
+	if stats.Errors != 2 || stats.Misses != 1 || stats.Entries != -1 {
+		t.Errorf("Stats = %+v, want 2 errors, 1 miss and -1 entries", stats)
+	}
+}
+
+func TestRedisKeepsZeroValuePointers(t *testing.T) {
+	c := NewRedis[*model.Assessment]("assessments", NewRedisClient(newFakeRedis(t).addr(), "", 0), time.Minute)
+	zero, studentID := 0.0, "2023-001"
+	want := &model.Assessment{ID: 7, StudentID: &studentID, InitialPayment: &zero, PerExamFee: &zero}
+	c.Set("7", want)
+
+	got, ok := c.Get("7")
+	if !ok {
+		t.Fatal("Get missed")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Get = %+v, want %+v", got, want)
+	}
+}
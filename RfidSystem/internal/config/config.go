@@ -1 +1,486 @@
 package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// AppConfig holds the application settings that are not related to the database connection.
+type AppConfig struct {
+	Attendance AttendanceConfig
+	Billing    BillingConfig
+	Exams      ExamConfig
+	Grading    GradingConfig
+	Photos     PhotoConfig
+	Cache      CacheConfig
+}
+
+// CacheConfig holds the settings of the caches of kiosk views.
+type CacheConfig struct {
+	// Backend is "memory" (the default), or "redis" to share the caches between
+	// servers through the Redis server at RedisAddr.
+	Backend       string
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	// Default applies to every cache not listed in Caches, which is keyed by cache name.
+	Default CacheSettings
+	Caches  map[string]CacheSettings
+}
+
+// CacheSettings holds the size and time-to-live of a cache. Capacity only
+// applies to in-memory caches; Redis evicts by its own memory limit.
+type CacheSettings struct {
+	Capacity int
+	TTL      time.Duration
+}
+
+// Settings returns the settings of the cache with the given name.
+func (c CacheConfig) Settings(name string) CacheSettings {
+	if settings, ok := c.Caches[name]; ok {
+		return settings
+	}
+	return c.Default
+}
+
+// PhotoConfig holds where uploaded student photos are stored.
+type PhotoConfig struct {
+	// Dir is the directory holding the resized photos, one file per student and size.
+	Dir string
+}
+
+// GradingConfig holds the grading scale and the rules for encoded grades.
+type GradingConfig struct {
+	// Scale is ScalePoint (1.00 is the highest grade) or ScalePercent
+	// (100 is the highest grade).
+	Scale string
+	// MinGrade and MaxGrade bound every period grade, e.g. 1.0 to 5.0.
+	MinGrade float64
+	MaxGrade float64
+	// PassingGrade is the worst final grade that still passes, e.g. 3.0 or 75.
+	PassingGrade float64
+	// Weights maps a grading period ("prelim", "midterm", "prefinal", "final")
+	// to its weight in the final grade. Weights are normalized to their sum.
+	Weights map[string]float64
+	// Honors lists the Latin honors from the highest, each with the worst GWA
+	// that still earns it.
+	Honors []HonorThreshold
+}
+
+// Grading scales.
+const (
+	ScalePoint   = "point"
+	ScalePercent = "percent"
+)
+
+// HonorThreshold is a Latin honor and the worst GWA that still earns it.
+type HonorThreshold struct {
+	Name string
+	GWA  float64
+}
+
+// LowerIsBetter reports whether lower grades are better, as on the 1.00-5.00 scale.
+func (c GradingConfig) LowerIsBetter() bool {
+	return c.Scale != ScalePercent
+}
+
+// AtLeast reports whether grade is as good as or better than threshold on the scale.
+func (c GradingConfig) AtLeast(grade, threshold float64) bool {
+	if c.LowerIsBetter() {
+		return grade <= threshold
+	}
+	return grade >= threshold
+}
+
+// BillingConfig holds the settings printed on billing documents.
+type BillingConfig struct {
+	// InstitutionName and InstitutionAddress are printed at the top of
+	// official receipts and statements of account.
+	InstitutionName    string
+	InstitutionAddress string
+	LatePenalty        LatePenaltyConfig
+	Assessment         AssessmentConfig
+
+	// ReceiptKey is the secret official receipts are signed with, so that a
+	// receipt altered in the database no longer matches its verification hash.
+	ReceiptKey []byte
+}
+
+// AssessmentConfig holds the payment terms of generated assessments.
+type AssessmentConfig struct {
+	// InitialPayment is the down payment due at the start of the term. It is
+	// capped at the net assessment amount.
+	InitialPayment float64
+	// FullPaymentDiscountRate is the percent taken off the net assessment amount
+	// when it is paid in full before the first exam.
+	FullPaymentDiscountRate float64
+}
+
+// LatePenaltyConfig holds the penalty rules for overdue payment schedule installments.
+// An installment that is still unpaid more than GraceDays after its due date is
+// charged Rate percent of its unpaid amount plus a Flat amount.
+type LatePenaltyConfig struct {
+	Rate      float64
+	Flat      float64
+	GraceDays int
+}
+
+// ExamConfig lists the exam periods during which card scans check the student's exam permit.
+type ExamConfig struct {
+	Windows []ExamWindow
+}
+
+// ExamWindow is an exam period. Name matches the payment schedule installment
+// that must be paid before the exam, e.g. "Prelim" or "Midterm".
+// Start and End are the first and last day of the exam period.
+type ExamWindow struct {
+	Name  string
+	Start time.Time
+	End   time.Time
+}
+
+// ActiveWindow returns the exam window that includes t, if any.
+func (c ExamConfig) ActiveWindow(t time.Time) (ExamWindow, bool) {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	for _, window := range c.Windows {
+		if !day.Before(window.Start) && !day.After(window.End) {
+			return window, true
+		}
+	}
+	return ExamWindow{}, false
+}
+
+// Window returns the exam window with the given name, ignoring case.
+func (c ExamConfig) Window(name string) (ExamWindow, bool) {
+	for _, window := range c.Windows {
+		if strings.EqualFold(window.Name, name) {
+			return window, true
+		}
+	}
+	return ExamWindow{}, false
+}
+
+// AttendanceConfig lists the readers installed at entry/exit gates.
+// Scans at any other reader only display student information.
+type AttendanceConfig struct {
+	// Gates maps a reader ID to a fixed direction ("IN" or "OUT").
+	// An empty direction means the reader toggles the student's presence.
+	Gates map[string]string
+	// MinScanInterval is how long after a student's last gate event further
+	// scans of the student are ignored, so that a card read twice does not
+	// toggle the student back out.
+	MinScanInterval time.Duration
+}
+
+// IsGate reports whether the given reader records attendance.
+func (c AttendanceConfig) IsGate(readerID string) bool {
+	_, ok := c.Gates[readerID]
+	return ok
+}
+
+// LoadAppConfig loads the application settings from the environment.
+//
+// ATTENDANCE_GATES is a comma-separated list of gate reader IDs, each optionally
+// suffixed with ":in" or ":out" for one-way gates, e.g. "GATE-1,GATE-2:in,GATE-3:out".
+// ATTENDANCE_MIN_INTERVAL is how long repeated gate scans of a student are
+// ignored after an attendance event, 10s by default.
+// INSTITUTION_NAME and INSTITUTION_ADDRESS are printed on receipts and statements.
+// RECEIPT_SIGNING_KEY is the secret official receipts are signed with; payments
+// cannot be recorded without it, and changing it invalidates every receipt.
+// LATE_PENALTY_RATE (percent), LATE_PENALTY_FLAT and LATE_PENALTY_GRACE_DAYS set
+// the penalty for overdue installments; all default to 0.
+// ASSESSMENT_INITIAL_PAYMENT and FULL_PAYMENT_DISCOUNT_RATE (percent) set the
+// payment terms of generated assessments; both default to 0.
+// GRADE_SCALE is "point" (1.00-5.00, the default) or "percent" (0-100).
+// GRADE_MIN, GRADE_MAX and GRADE_PASSING default to 1.0, 5.0 and 3.0 on the
+// point scale and 50, 100 and 75 on the percent scale. GRADE_WEIGHTS sets the
+// weight of each grading period in the final grade, e.g.
+// "prelim:20,midterm:20,prefinal:20,final:40" (the default is equal weights).
+// LATIN_HONORS lists the honors as name:gwa from the highest, e.g.
+// "Summa Cum Laude:1.20,Magna Cum Laude:1.45,Cum Laude:1.75" (the default on
+// the point scale; 96, 93 and 90 on the percent scale).
+// EXAM_WINDOWS is a comma-separated list of exam periods as name:start:end with
+// dates in YYYY-MM-DD, e.g. "Prelim:2025-08-18:2025-08-23,Midterm:2025-09-22:2025-09-27".
+// PHOTO_DIR is the directory student photos are stored in, "./data/photos" by default.
+// CACHE_BACKEND is "memory" (the default) or "redis"; REDIS_ADDR (default
+// "localhost:6379"), REDIS_PASSWORD and REDIS_DB locate the Redis server.
+// CACHE_SIZE and CACHE_TTL set the capacity and time-to-live of every cache
+// (default 1000 entries and 15m); CACHE_SETTINGS overrides them per cache as
+// name:size:ttl, either of which may be left empty, e.g. "bills:500:5m,grades::1h".
+func LoadAppConfig() AppConfig {
+	_ = godotenv.Load()
+
+	return AppConfig{
+		Attendance: AttendanceConfig{
+			Gates:           parseGates(os.Getenv("ATTENDANCE_GATES")),
+			MinScanInterval: parseDurationEnv("ATTENDANCE_MIN_INTERVAL", 10*time.Second),
+		},
+		Billing: BillingConfig{
+			InstitutionName:    strings.TrimSpace(os.Getenv("INSTITUTION_NAME")),
+			InstitutionAddress: strings.TrimSpace(os.Getenv("INSTITUTION_ADDRESS")),
+			ReceiptKey:         loadReceiptKey(),
+			LatePenalty: LatePenaltyConfig{
+				Rate:      parseFloatEnv("LATE_PENALTY_RATE"),
+				Flat:      parseFloatEnv("LATE_PENALTY_FLAT"),
+				GraceDays: int(parseFloatEnv("LATE_PENALTY_GRACE_DAYS")),
+			},
+			Assessment: AssessmentConfig{
+				InitialPayment:          parseFloatEnv("ASSESSMENT_INITIAL_PAYMENT"),
+				FullPaymentDiscountRate: parseFloatEnv("FULL_PAYMENT_DISCOUNT_RATE"),
+			},
+		},
+		Exams: ExamConfig{
+			Windows: parseExamWindows(os.Getenv("EXAM_WINDOWS")),
+		},
+		Grading: loadGradingConfig(),
+		Photos: PhotoConfig{
+			Dir: envDefault("PHOTO_DIR", "./data/photos"),
+		},
+		Cache: loadCacheConfig(),
+	}
+}
+
+func loadGradingConfig() GradingConfig {
+	grading := GradingConfig{
+		Scale:        ScalePoint,
+		MinGrade:     1.0,
+		MaxGrade:     5.0,
+		PassingGrade: 3.0,
+		Honors: []HonorThreshold{
+			{Name: "Summa Cum Laude", GWA: 1.20},
+			{Name: "Magna Cum Laude", GWA: 1.45},
+			{Name: "Cum Laude", GWA: 1.75},
+		},
+	}
+	switch scale := strings.ToLower(strings.TrimSpace(os.Getenv("GRADE_SCALE"))); scale {
+	case "", ScalePoint:
+	case ScalePercent:
+		grading.Scale = ScalePercent
+		grading.MinGrade, grading.MaxGrade, grading.PassingGrade = 50, 100, 75
+		grading.Honors = []HonorThreshold{
+			{Name: "Summa Cum Laude", GWA: 96},
+			{Name: "Magna Cum Laude", GWA: 93},
+			{Name: "Cum Laude", GWA: 90},
+		}
+	default:
+		log.Printf("Ignoring invalid GRADE_SCALE: %q", scale)
+	}
+	grading.MinGrade = parseFloatEnvDefault("GRADE_MIN", grading.MinGrade)
+	grading.MaxGrade = parseFloatEnvDefault("GRADE_MAX", grading.MaxGrade)
+	grading.PassingGrade = parseFloatEnvDefault("GRADE_PASSING", grading.PassingGrade)
+	grading.Weights = parseGradeWeights(os.Getenv("GRADE_WEIGHTS"))
+	if honors := parseHonors(os.Getenv("LATIN_HONORS")); honors != nil {
+		grading.Honors = honors
+	}
+	return grading
+}
+
+func loadReceiptKey() []byte {
+	key := strings.TrimSpace(os.Getenv("RECEIPT_SIGNING_KEY"))
+	if key == "" {
+		log.Printf("RECEIPT_SIGNING_KEY is not set; payments cannot be recorded until it is")
+		return nil
+	}
+	return []byte(key)
+}
+
+func loadCacheConfig() CacheConfig {
+	cache := CacheConfig{
+		Backend:       strings.ToLower(envDefault("CACHE_BACKEND", "memory")),
+		RedisAddr:     envDefault("REDIS_ADDR", "localhost:6379"),
+		RedisPassword: os.Getenv("REDIS_PASSWORD"),
+		RedisDB:       int(parseFloatEnv("REDIS_DB")),
+		Default: CacheSettings{
+			Capacity: int(parseFloatEnvDefault("CACHE_SIZE", 1000)),
+			TTL:      parseDurationEnv("CACHE_TTL", 15*time.Minute),
+		},
+	}
+	if cache.Backend != "memory" && cache.Backend != "redis" {
+		log.Printf("Ignoring invalid CACHE_BACKEND: %q", cache.Backend)
+		cache.Backend = "memory"
+	}
+	cache.Caches = parseCacheSettings(os.Getenv("CACHE_SETTINGS"), cache.Default)
+	return cache
+}
+
+// parseDurationEnv reads a positive duration such as "15m" from the environment,
+// returning def if the variable is unset or invalid.
+func parseDurationEnv(name string, def time.Duration) time.Duration {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Ignoring invalid %s: %q", name, value)
+		return def
+	}
+	return d
+}
+
+// parseFloatEnv reads a non-negative number from the environment, returning 0
+// if the variable is unset or invalid.
+func parseFloatEnv(name string) float64 {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		log.Printf("Ignoring invalid %s: %q", name, value)
+		return 0
+	}
+	return f
+}
+
+// parseFloatEnvDefault reads a non-negative number from the environment,
+// returning def if the variable is unset or invalid.
+func parseFloatEnvDefault(name string, def float64) float64 {
+	if strings.TrimSpace(os.Getenv(name)) == "" {
+		return def
+	}
+	if f := parseFloatEnv(name); f > 0 {
+		return f
+	}
+	return def
+}
+
+// envDefault reads a setting from the environment, returning def if it is unset.
+func envDefault(name, def string) string {
+	if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+		return value
+	}
+	return def
+}
+
+func parseCacheSettings(value string, def CacheSettings) map[string]CacheSettings {
+	caches := make(map[string]CacheSettings)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 || strings.TrimSpace(parts[0]) == "" {
+			log.Printf("Ignoring invalid cache settings: %q", entry)
+			continue
+		}
+		settings := def
+		if size := strings.TrimSpace(parts[1]); size != "" {
+			n, err := strconv.Atoi(size)
+			if err != nil || n < 1 {
+				log.Printf("Ignoring invalid cache settings: %q", entry)
+				continue
+			}
+			settings.Capacity = n
+		}
+		if ttl := strings.TrimSpace(parts[2]); ttl != "" {
+			d, err := time.ParseDuration(ttl)
+			if err != nil || d <= 0 {
+				log.Printf("Ignoring invalid cache settings: %q", entry)
+				continue
+			}
+			settings.TTL = d
+		}
+		caches[strings.TrimSpace(parts[0])] = settings
+	}
+	return caches
+}
+
+func parseGates(value string) map[string]string {
+	gates := make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		readerID, direction, _ := strings.Cut(entry, ":")
+		direction = strings.ToUpper(strings.TrimSpace(direction))
+		if direction != "IN" && direction != "OUT" {
+			direction = ""
+		}
+		gates[strings.TrimSpace(readerID)] = direction
+	}
+	return gates
+}
+
+func parseExamWindows(value string) []ExamWindow {
+	var windows []ExamWindow
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			log.Printf("Ignoring invalid exam window: %q", entry)
+			continue
+		}
+		start, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(parts[1]), time.Local)
+		if err != nil {
+			log.Printf("Ignoring invalid exam window: %q", entry)
+			continue
+		}
+		end, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(parts[2]), time.Local)
+		if err != nil || end.Before(start) {
+			log.Printf("Ignoring invalid exam window: %q", entry)
+			continue
+		}
+		windows = append(windows, ExamWindow{
+			Name:  strings.TrimSpace(parts[0]),
+			Start: start,
+			End:   end,
+		})
+	}
+	return windows
+}
+
+func parseGradeWeights(value string) map[string]float64 {
+	weights := map[string]float64{"prelim": 1, "midterm": 1, "prefinal": 1, "final": 1}
+	if strings.TrimSpace(value) == "" {
+		return weights
+	}
+	parsed := make(map[string]float64)
+	for _, entry := range strings.Split(value, ",") {
+		period, weight, _ := strings.Cut(strings.TrimSpace(entry), ":")
+		period = strings.ToLower(strings.TrimSpace(period))
+		w, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+		if _, known := weights[period]; !known || err != nil || w < 0 {
+			log.Printf("Ignoring invalid GRADE_WEIGHTS: %q", value)
+			return weights
+		}
+		parsed[period] = w
+	}
+	var total float64
+	for _, w := range parsed {
+		total += w
+	}
+	if total == 0 {
+		log.Printf("Ignoring invalid GRADE_WEIGHTS: %q", value)
+		return weights
+	}
+	return parsed
+}
+
+func parseHonors(value string) []HonorThreshold {
+	var honors []HonorThreshold
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, gwa, _ := strings.Cut(entry, ":")
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(gwa), 64)
+		if strings.TrimSpace(name) == "" || err != nil || threshold <= 0 {
+			log.Printf("Ignoring invalid LATIN_HONORS: %q", value)
+			return nil
+		}
+		honors = append(honors, HonorThreshold{Name: strings.TrimSpace(name), GWA: threshold})
+	}
+	return honors
+}
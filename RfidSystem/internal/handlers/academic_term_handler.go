@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/repositories"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleListAcademicTerms handles HTTP requests to list every academic term and the current one.
+func (h *AppHandler) HandleListAcademicTerms(ctx *fiber.Ctx) error {
+	terms, err := h.RFIDRepository.ListAcademicTerms()
+	if err != nil {
+		log.Printf("Error listing academic terms: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	current, err := h.RFIDRepository.GetCurrentTerm()
+	if err != nil {
+		log.Printf("Error getting current term: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(fiber.Map{
+		"terms":   terms,
+		"current": current,
+	})
+}
+
+// HandleSaveAcademicTerm handles HTTP requests to create an academic term, or to
+// update one when the ":id" path parameter is present. It expects "academic_year",
+// "semester" (any term name, e.g. "Summer" or "First Trimester"), "start_date" and
+// "end_date" in YYYY-MM-DD in the request body. Terms may not overlap.
+func (h *AppHandler) HandleSaveAcademicTerm(ctx *fiber.Ctx) error {
+	var req struct {
+		AcademicYear string `json:"academic_year" form:"academic_year"`
+		Semester     string `json:"semester" form:"semester"`
+		StartDate    string `json:"start_date" form:"start_date"`
+		EndDate      string `json:"end_date" form:"end_date"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	term := &model.AcademicTerm{
+		AcademicYear: strings.TrimSpace(req.AcademicYear),
+		Semester:     strings.TrimSpace(req.Semester),
+		StartDate:    strings.TrimSpace(req.StartDate),
+		EndDate:      strings.TrimSpace(req.EndDate),
+	}
+	if term.AcademicYear == "" || term.Semester == "" {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Academic year and semester are required")
+	}
+	start, err := time.Parse("2006-01-02", term.StartDate)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Start date must be in YYYY-MM-DD format")
+	}
+	end, err := time.Parse("2006-01-02", term.EndDate)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("End date must be in YYYY-MM-DD format")
+	}
+	if end.Before(start) {
+		return ctx.Status(fiber.StatusBadRequest).SendString("End date must not be before start date")
+	}
+	status := fiber.StatusCreated
+	if ctx.Params("id") != "" {
+		id, err := ctx.ParamsInt("id")
+		if err != nil || id <= 0 {
+			return ctx.Status(fiber.StatusBadRequest).SendString("Invalid term ID")
+		}
+		term.ID = int64(id)
+		status = fiber.StatusOK
+	}
+
+	if err := h.RFIDRepository.SaveAcademicTerm(term); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrTermNotFound):
+			return ctx.Status(fiber.StatusNotFound).SendString(err.Error())
+		case errors.Is(err, repositories.ErrTermOverlap):
+			return ctx.Status(fiber.StatusConflict).SendString(err.Error())
+		}
+		log.Printf("Error saving academic term %s %s: %v", term.Semester, term.AcademicYear, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	h.termsChanged(ctx, fmt.Sprintf("Academic term %d (%s %s) saved", term.ID, term.Semester, term.AcademicYear))
+	return ctx.Status(status).JSON(term)
+}
+
+// HandleDeleteAcademicTerm handles HTTP requests to delete the academic term in the
+// ":id" path parameter. Terms with enrollments or assessments cannot be deleted.
+func (h *AppHandler) HandleDeleteAcademicTerm(ctx *fiber.Ctx) error {
+	termID, err := ctx.ParamsInt("id")
+	if err != nil || termID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid term ID")
+	}
+	found, err := h.RFIDRepository.DeleteAcademicTerm(int64(termID))
+	if err != nil {
+		if errors.Is(err, repositories.ErrTermInUse) {
+			return ctx.Status(fiber.StatusConflict).SendString(err.Error())
+		}
+		log.Printf("Error deleting academic term %d: %v", termID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if !found {
+		return ctx.Status(fiber.StatusNotFound).SendString("Academic term not found")
+	}
+	h.termsChanged(ctx, fmt.Sprintf("Academic term %d deleted", termID))
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleActivateTerm handles HTTP requests to make the academic term in the ":id"
+// path parameter the current term, regardless of its dates.
+func (h *AppHandler) HandleActivateTerm(ctx *fiber.Ctx) error {
+	termID, err := ctx.ParamsInt("id")
+	if err != nil || termID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid term ID")
+	}
+	return h.setActiveTerm(ctx, int64(termID))
+}
+
+// HandleClearActiveTerm handles HTTP requests to clear the active term, so the
+// current term follows the term dates again.
+func (h *AppHandler) HandleClearActiveTerm(ctx *fiber.Ctx) error {
+	return h.setActiveTerm(ctx, 0)
+}
+
+func (h *AppHandler) setActiveTerm(ctx *fiber.Ctx, termID int64) error {
+	found, err := h.RFIDRepository.SetActiveTerm(termID)
+	if err != nil {
+		log.Printf("Error setting active term %d: %v", termID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if !found {
+		return ctx.Status(fiber.StatusNotFound).SendString("Academic term not found")
+	}
+	message := fmt.Sprintf("Academic term %d set as active", termID)
+	if termID == 0 {
+		message = "Active academic term cleared"
+	}
+	h.termsChanged(ctx, message)
+
+	current, err := h.RFIDRepository.GetCurrentTerm()
+	if err != nil {
+		log.Printf("Error getting current term: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(fiber.Map{"current": current})
+}
+
+// termsChanged logs a change to the academic terms and drops the cached kiosk
+// views that depend on which term is current.
+func (h *AppHandler) termsChanged(ctx *fiber.Ctx, message string) {
+	caches.Clear()
+	changedBy, _ := ctx.Locals("userEmail").(string)
+	_ = h.db.LogScanEvent("", nil, "academic_term_changed", fmt.Sprintf("%s by %s", message, changedBy), "", "info")
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/repositories"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleGenerateAssessment handles HTTP requests to generate a student's assessment
+// for a term. It expects the student ID as a path parameter and "term_id", and
+// optionally "discount_type_ids", "replace" (regenerate an assessment that has no
+// payments yet) and "dry_run" (compute without saving), in the request body.
+func (h *AppHandler) HandleGenerateAssessment(ctx *fiber.Ctx) error {
+	studentID := ctx.Params("id")
+	var req struct {
+		TermID          int64   `json:"term_id" form:"term_id"`
+		DiscountTypeIDs []int64 `json:"discount_type_ids" form:"discount_type_ids"`
+		Replace         bool    `json:"replace" form:"replace"`
+		DryRun          bool    `json:"dry_run" form:"dry_run"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	if req.TermID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Term ID is required")
+	}
+
+	generatedBy, _ := ctx.Locals("userEmail").(string)
+	generated, err := h.RFIDRepository.GenerateAssessment(model.AssessmentRequest{
+		StudentID:       studentID,
+		TermID:          req.TermID,
+		DiscountTypeIDs: req.DiscountTypeIDs,
+		Replace:         req.Replace,
+		DryRun:          req.DryRun,
+		GeneratedBy:     generatedBy,
+	}, h.config.Billing.Assessment, h.config.Exams.Windows)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrTermNotFound), errors.Is(err, repositories.ErrDiscountTypeNotFound):
+			return ctx.Status(fiber.StatusNotFound).SendString(err.Error())
+		case errors.Is(err, repositories.ErrAssessmentExists),
+			errors.Is(err, repositories.ErrAssessmentHasPayments),
+			errors.Is(err, repositories.ErrDiscountTypeInactive):
+			return ctx.Status(fiber.StatusConflict).SendString(err.Error())
+		case errors.Is(err, repositories.ErrNoEnrolledSubjects), errors.Is(err, repositories.ErrNoFeeSchedule):
+			return ctx.Status(fiber.StatusUnprocessableEntity).SendString(err.Error())
+		}
+		log.Printf("Error generating assessment for %s: %v", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if generated == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Student not found")
+	}
+	if generated.DryRun {
+		return ctx.JSON(generated)
+	}
+
+	invalidateStudent(studentID)
+	_ = h.db.LogScanEvent("", &studentID, "assessment_generated",
+		fmt.Sprintf("Assessment %d generated for term %d by %s", generated.Assessment.ID, req.TermID, generatedBy),
+		fmt.Sprintf(`{"net_assessment_amount": %s}`, formatAmount(generated.Assessment.NetAssessmentAmount)), "success")
+	return ctx.Status(fiber.StatusCreated).JSON(generated)
+}
+
+// HandleListFeeSchedules handles HTTP requests to list the fee schedule assessments are generated from.
+func (h *AppHandler) HandleListFeeSchedules(ctx *fiber.Ctx) error {
+	items, err := h.RFIDRepository.ListFeeSchedules()
+	if err != nil {
+		log.Printf("Error listing fee schedules: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(items)
+}
+
+// HandleSaveFeeSchedule handles HTTP requests to add a fee schedule row, or to
+// update one when the ":id" path parameter is present. It expects "fee_type_id"
+// and "amount", and optionally "per_unit", "term_id" and "program" (both default
+// to every term and program), in the request body.
+func (h *AppHandler) HandleSaveFeeSchedule(ctx *fiber.Ctx) error {
+	var req struct {
+		FeeTypeID int64   `json:"fee_type_id" form:"fee_type_id"`
+		TermID    *int64  `json:"term_id" form:"term_id"`
+		Program   string  `json:"program" form:"program"`
+		Amount    float64 `json:"amount" form:"amount"`
+		PerUnit   bool    `json:"per_unit" form:"per_unit"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	if req.FeeTypeID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Fee type is required")
+	}
+	if req.Amount < 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Amount cannot be negative")
+	}
+
+	item := &model.FeeScheduleItem{
+		FeeTypeID: req.FeeTypeID,
+		TermID:    req.TermID,
+		Amount:    req.Amount,
+		PerUnit:   req.PerUnit,
+	}
+	if program := strings.TrimSpace(req.Program); program != "" {
+		item.Program = &program
+	}
+	status := fiber.StatusCreated
+	if ctx.Params("id") != "" {
+		id, err := ctx.ParamsInt("id")
+		if err != nil || id <= 0 {
+			return ctx.Status(fiber.StatusBadRequest).SendString("Invalid fee schedule ID")
+		}
+		item.ID = int64(id)
+		status = fiber.StatusOK
+	}
+
+	if err := h.RFIDRepository.SaveFeeSchedule(item); err != nil {
+		if errors.Is(err, repositories.ErrFeeTypeNotFound) || errors.Is(err, repositories.ErrFeeScheduleNotFound) {
+			return ctx.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		log.Printf("Error saving fee schedule for fee type %d: %v", req.FeeTypeID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.Status(status).JSON(item)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleDailyAttendance handles HTTP requests for the daily attendance report.
+// It accepts an optional "date" (YYYY-MM-DD, defaults to today) and "group"
+// query parameter of "student" (default), "block_section" or "program".
+func (h *AppHandler) HandleDailyAttendance(c *fiber.Ctx) error {
+	day, err := parseReportDate(c.Query("date"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid date, expected YYYY-MM-DD")
+	}
+
+	group := c.Query("group", "student")
+	if group == "student" {
+		summaries, err := h.RFIDRepository.GetDailyAttendanceByStudent(day)
+		if err != nil {
+			log.Printf("HandleDailyAttendance error: %v", err)
+			return c.Status(fiber.StatusInternalServerError).
+				SendString(fmt.Sprintf("Failed to query attendance: %v", err))
+		}
+		return c.JSON(fiber.Map{"date": day.Format("2006-01-02"), "group": group, "data": summaries})
+	}
+
+	if group != "block_section" && group != "program" {
+		return c.Status(fiber.StatusBadRequest).SendString("Group must be student, block_section or program")
+	}
+	summaries, err := h.RFIDRepository.GetDailyAttendanceByGroup(day, group)
+	if err != nil {
+		log.Printf("HandleDailyAttendance error: %v", err)
+		return c.Status(fiber.StatusInternalServerError).
+			SendString(fmt.Sprintf("Failed to query attendance: %v", err))
+	}
+	return c.JSON(fiber.Map{"date": day.Format("2006-01-02"), "group": group, "data": summaries})
+}
+
+// HandleStudentAttendance handles HTTP requests to list a student's IN/OUT events for a day.
+// It expects the student ID as a path parameter and an optional "date" query parameter.
+func (h *AppHandler) HandleStudentAttendance(c *fiber.Ctx) error {
+	studentID := c.Params("id")
+	day, err := parseReportDate(c.Query("date"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid date, expected YYYY-MM-DD")
+	}
+
+	events, err := h.RFIDRepository.GetStudentAttendance(studentID, day)
+	if err != nil {
+		log.Printf("HandleStudentAttendance error: %v", err)
+		return c.Status(fiber.StatusInternalServerError).
+			SendString(fmt.Sprintf("Failed to query attendance: %v", err))
+	}
+	return c.JSON(events)
+}
+
+// HandleExportAttendance handles HTTP requests to export the daily attendance report as CSV.
+// It accepts the same "date" and "group" query parameters as HandleDailyAttendance.
+func (h *AppHandler) HandleExportAttendance(c *fiber.Ctx) error {
+	day, err := parseReportDate(c.Query("date"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid date, expected YYYY-MM-DD")
+	}
+	group := c.Query("group", "student")
+	if group != "student" && group != "block_section" && group != "program" {
+		return c.Status(fiber.StatusBadRequest).SendString("Group must be student, block_section or program")
+	}
+
+	var records [][]string
+	if group == "student" {
+		summaries, err := h.RFIDRepository.GetDailyAttendanceByStudent(day)
+		if err != nil {
+			log.Printf("HandleExportAttendance error: %v", err)
+			return c.Status(fiber.StatusInternalServerError).
+				SendString(fmt.Sprintf("Failed to query attendance for export: %v", err))
+		}
+		records = append(records, []string{"StudentID", "Name", "Program", "BlockSection", "FirstIn", "LastOut", "Events", "Present"})
+		for _, s := range summaries {
+			records = append(records, []string{
+				s.StudentID,
+				strings.TrimSpace(s.Name),
+				stringValue(s.Program),
+				stringValue(s.BlockSection),
+				timeValue(s.FirstIn),
+				timeValue(s.LastOut),
+				strconv.Itoa(s.EventCount),
+				strconv.FormatBool(s.IsPresent),
+			})
+		}
+	} else {
+		summaries, err := h.RFIDRepository.GetDailyAttendanceByGroup(day, group)
+		if err != nil {
+			log.Printf("HandleExportAttendance error: %v", err)
+			return c.Status(fiber.StatusInternalServerError).
+				SendString(fmt.Sprintf("Failed to query attendance for export: %v", err))
+		}
+		records = append(records, []string{"Group", "TotalStudents", "Attended", "PresentNow"})
+		for _, s := range summaries {
+			records = append(records, []string{
+				s.Group,
+				strconv.Itoa(s.TotalStudents),
+				strconv.Itoa(s.AttendedCount),
+				strconv.Itoa(s.PresentNowCount),
+			})
+		}
+	}
+
+	c.Set("Content-Type", "text/csv")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=attendance-%s-%s.csv", group, day.Format("2006-01-02")))
+	writer := csv.NewWriter(c)
+	defer writer.Flush()
+	for _, record := range records {
+		writer.Write(record)
+	}
+	return nil
+}
+
+// parseReportDate parses a YYYY-MM-DD date in local time, defaulting to today when empty.
+func parseReportDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// stringValue returns the value of a nullable string, or an empty string if it is nil.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// timeValue formats a nullable time as RFC3339, or an empty string if it is nil.
+func timeValue(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
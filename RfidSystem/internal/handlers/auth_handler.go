@@ -2,30 +2,47 @@ package handlers
 
 import (
 	"errors"
+	"log"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
 )
 
-var users = map[string]string{
-	"admin@gmail.com": "admin123",
-	"user@gmail.com":  "user123",
-}
+// dummyPasswordHash is compared against when an email is unknown so that
+// failed logins take the same time whether or not the account exists.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
 
 func (h *AppHandler) LoginPageHandler() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if c.Method() == fiber.MethodGet {
 			return c.Render("pages/login", fiber.Map{
-				"RedirectURL": c.Query("redirect"),
+				"RedirectURL": safeRedirectURL(c.Query("redirect")),
 			})
 		}
 
 		if c.Method() == fiber.MethodPost {
 			email := c.FormValue("email")
 			password := c.FormValue("password")
-			redirectURL := c.FormValue("redirect_url", "/logs")
-			if authenticated, err := authenticateUser(email, password); authenticated {
+			redirectURL := safeRedirectURL(c.FormValue("redirect_url"))
+			if authenticated, err := h.authenticateUser(email, password); authenticated {
 				sessionToken := CreateSession(email)
+				if sessionToken == "" {
+					return c.Render("pages/login", fiber.Map{
+						"Error":       "Unable to start a session, please try again",
+						"RedirectURL": redirectURL,
+					})
+				}
+				c.Cookie(&fiber.Cookie{
+					Name:     "session_token",
+					Value:    sessionToken,
+					Expires:  time.Now().Add(sessionMaxLifetime),
+					HTTPOnly: true,
+					Secure:   c.Protocol() == "https",
+					SameSite: "Lax",
+				})
 
 				if c.Get("HX-Request") == "true" {
 					c.Set("HX-Redirect", redirectURL)
@@ -55,15 +72,13 @@ func (h *AppHandler) LogoutHandler() fiber.Handler {
 			return c.Status(fiber.StatusMethodNotAllowed).SendString("Method Not Allowed")
 		}
 
-		authHeader := c.Get("Authorization")
-		token := ""
-		if strings.HasPrefix(authHeader, "Bearer ") {
-			token = strings.TrimPrefix(authHeader, "Bearer ")
-		}
-
-		if token != "" {
+		if token := bearerToken(c); token != "" {
 			DeleteSession(token)
 		}
+		if cookieToken := c.Cookies("session_token"); cookieToken != "" {
+			DeleteSession(cookieToken)
+		}
+		c.ClearCookie("session_token")
 
 		if c.Get("HX-Request") == "true" {
 			c.Set("HX-Trigger", `{"logoutSuccessClient": {}}`)
@@ -75,43 +90,62 @@ func (h *AppHandler) LogoutHandler() fiber.Handler {
 	}
 }
 
-func authenticateUser(email, password string) (bool, error) {
+// authenticateUser checks the email and password against the bcrypt hash stored in the Users table.
+func (h *AppHandler) authenticateUser(email, password string) (bool, error) {
 	if email == "" || password == "" {
 		return false, errors.New("email and password are required")
 	}
-	storedPassword, exists := users[email]
-	if !exists {
+	user, err := h.RFIDRepository.GetUserByEmail(strings.TrimSpace(email))
+	if err != nil {
+		log.Printf("authenticateUser lookup error: %v", err)
+		return false, errors.New("unable to sign in, please try again")
+	}
+	if user == nil || !user.IsActive {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
 		return false, errors.New("invalid email or password")
 	}
-	if storedPassword != password {
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
 		return false, errors.New("invalid email or password")
 	}
 	return true, nil
 }
 
 // AuthRequired is a middleware that checks if the user is authenticated.
-// If not, it handles the response based on request type (HTMX or regular).
-// func AuthRequired() fiber.Handler { // THIS FUNCTION WILL BE REMOVED
-// 	return func(c *fiber.Ctx) error {
-// 		if !IsAuthenticatedFiber(c) {
-// 			originalURL := c.OriginalURL()
-// 			if originalURL == "/login" || originalURL == "/logout" || originalURL == "/" || originalURL == "/favicon.ico" {
-// 				originalURL = "/logs"
-// 			}
-// 			if c.Get("HX-Request") == "true" {
-// 				c.Set("HX-Redirect", "/login?redirect="+originalURL)
-// 				return c.Status(fiber.StatusUnauthorized).SendString("HTMX redirecting to login")
-// 			} else {
-// 				return c.Redirect("/login?redirect="+originalURL, fiber.StatusSeeOther)
-// 			}
-// 		}
-// 		return c.Next()
-// 	}
-// }
+// It accepts the session cookie set at login or an "Authorization: Bearer <token>"
+// header. If the user is not authenticated, it handles the response based on
+// request type: HTMX requests get an HX-Redirect to the login page, browser page
+// loads are redirected, and API requests get a 401. The user's email and role
+// are stored in the request locals for RequirePermission and the handlers.
+func AuthRequired() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if userSession, ok := lookupRequestSession(c); ok {
+			c.Locals("userEmail", userSession.email)
+			c.Locals("userRole", userSession.role)
+			return c.Next()
+		}
 
-// IsAuthenticatedFiber, GetSessionUserEmailFiber, CreateSession, DeleteSession
-// might need internal adjustments if they relied solely on cookies previously.
-// For now, their direct calls from the modified handlers assume CreateSession returns a token
-// and DeleteSession invalidates a token. IsAuthenticatedFiber is no longer called by AuthRequired.
-// If other handlers need to check authentication, they will need to use a revised IsAuthenticatedFiber
-// that checks the Authorization header.
+		originalURL := c.OriginalURL()
+		if originalURL == "/login" || originalURL == "/logout" || originalURL == "/" || originalURL == "/favicon.ico" {
+			originalURL = "/logs"
+		}
+		loginURL := "/login?redirect=" + url.QueryEscape(originalURL)
+
+		if c.Get("HX-Request") == "true" {
+			c.Set("HX-Redirect", loginURL)
+			return c.Status(fiber.StatusUnauthorized).SendString("HTMX redirecting to login")
+		}
+		if c.Method() == fiber.MethodGet && c.Get("Authorization") == "" && strings.Contains(c.Get("Accept"), "text/html") {
+			return c.Redirect(loginURL, fiber.StatusSeeOther)
+		}
+		return c.Status(fiber.StatusUnauthorized).SendString("Authentication required")
+	}
+}
+
+// safeRedirectURL returns the redirect target if it is a local path, and "/logs" otherwise,
+// so the login form cannot be used to send users to another site.
+func safeRedirectURL(target string) string {
+	if !strings.HasPrefix(target, "/") || strings.HasPrefix(target, "//") || strings.HasPrefix(target, "/\\") {
+		return "/logs"
+	}
+	return target
+}
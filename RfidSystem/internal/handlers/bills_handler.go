@@ -1,17 +1,17 @@
 package handlers
 
 import (
+	"bytes"
 	"fmt"
 	"log"
 	"rfidsystem/internal/model"
-	"time"
+	"rfidsystem/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // billsCache is an LRU cache for storing student bills data.
 // It has a capacity of 5 items and a time-to-live of 1 hour.
-var billsCache = NewLRUCache(5, time.Hour)
 
 // formatAmount formats a float64 amount to a string with two decimal places.
 func formatAmount(amount float64) string {
@@ -70,9 +70,8 @@ func (h *AppHandler) HandleBills(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).SendString("Student Id is required")
 	}
 	// Try cache first
-	if cached, found := billsCache.Get(studentId); found {
-		billsData, ok := cached.(*model.Bills)
-		if ok && billsData != nil {
+	if billsData, found := billsCache.Get(studentId); found {
+		if billsData != nil {
 			log.Printf("[CACHE HIT] Bills for %s", studentId)
 
 			// Check if Assessment is nil before formatting
@@ -125,7 +124,7 @@ func (h *AppHandler) HandleBills(ctx *fiber.Ctx) error {
 	}
 
 	// Store in cache
-	billsCache.Set(studentId, billsData)
+	billsCache.Set(studentId, billsData, studentTag(studentId))
 
 	log.Printf("Successfully retrieved bills data for student ID: %s\n", studentId)
 	fmt.Println("why", billsData)
@@ -145,3 +144,70 @@ func (h *AppHandler) HandleBills(ctx *fiber.Ctx) error {
 	}
 	return nil
 }
+
+// GetBills handles HTTP requests to retrieve the assessment, fee breakdown, discounts
+// and payment history of a specific student as JSON. It expects the student ID as a path parameter.
+func (h *AppHandler) GetBills(ctx *fiber.Ctx) error {
+	studentID := ctx.Params("id")
+
+	billsData, err := h.RFIDRepository.GetStudentBillsByRFID(studentID)
+	if err != nil {
+		log.Printf("Error retrieving bills for student ID %s: %v\n", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if billsData == nil || billsData.Assessment == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("No bills data found for this student")
+	}
+	return ctx.JSON(billsData)
+}
+
+// HandleStatement handles HTTP requests for a print-friendly statement of account.
+// It expects the student ID as a path parameter.
+func (h *AppHandler) HandleStatement(ctx *fiber.Ctx) error {
+	statement, err := h.statementFromParams(ctx)
+	if err != nil || statement == nil {
+		return err
+	}
+	return ctx.Render("pages/statement", fiber.Map{
+		"Title":       "Statement of Account",
+		"Institution": h.config.Billing,
+		"Statement":   statement,
+		"Student":     statement.Student,
+		"Assessment":  statement.Bills.Assessment,
+		"Bills":       statement.Bills,
+	})
+}
+
+// HandleStatementPDF handles HTTP requests to download a student's statement of account as a PDF.
+// It expects the student ID as a path parameter.
+func (h *AppHandler) HandleStatementPDF(ctx *fiber.Ctx) error {
+	statement, err := h.statementFromParams(ctx)
+	if err != nil || statement == nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := services.RenderStatementPDF(&buf, statement, h.config.Billing); err != nil {
+		log.Printf("Error rendering statement for %s: %v", statement.Student.StudentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	ctx.Set("Content-Type", "application/pdf")
+	ctx.Set("Content-Disposition", fmt.Sprintf("inline; filename=statement-%s.pdf", statement.Student.StudentID))
+	return ctx.Send(buf.Bytes())
+}
+
+// statementFromParams loads the statement of account of the student named by the
+// ":id" path parameter. It writes the error response and returns a nil statement
+// if it cannot be loaded.
+func (h *AppHandler) statementFromParams(ctx *fiber.Ctx) (*model.StatementOfAccount, error) {
+	studentID := ctx.Params("id")
+	statement, err := h.RFIDRepository.GetStatementOfAccount(studentID)
+	if err != nil {
+		log.Printf("Error retrieving statement of account for %s: %v", studentID, err)
+		return nil, ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if statement == nil {
+		return nil, ctx.Status(fiber.StatusNotFound).SendString("No bills data found for this student")
+	}
+	return statement, nil
+}
@@ -26,6 +26,12 @@ type Broadcaster struct {
 type Message struct {
 	Event string
 	Data  string
+	// KioskID restricts delivery to clients subscribed to that kiosk.
+	// An empty KioskID delivers the message to every connected client.
+	KioskID string
+	// AdminOnly restricts delivery to clients signed in with the log permission,
+	// for alerts that must not reach public kiosk screens.
+	AdminOnly bool
 }
 
 // Client represents a single connected Server-Sent Events (SSE) client.
@@ -36,6 +42,10 @@ type Client struct {
 	// done signals when this client's connection should be terminated
 	// closed when the client disconnects or encounters an error
 	done chan struct{}
+	// kioskID is the kiosk this client displays; empty for admin and legacy clients
+	kioskID string
+	// admin is set when the client's session may view the scan log
+	admin bool
 }
 
 var (
@@ -99,6 +109,13 @@ func (b *Broadcaster) run() {
 			// Send to all clients concurrently
 			b.mutex.RLock()
 			for client := range b.clients {
+				// Kiosk-specific messages only go to the paired screen
+				if message.KioskID != "" && client.kioskID != message.KioskID {
+					continue
+				}
+				if message.AdminOnly && !client.admin {
+					continue
+				}
 				// Non-blocking send, skip clients with full buffers
 				select {
 				case client.messages <- message:
@@ -109,7 +126,7 @@ func (b *Broadcaster) run() {
 				}
 			}
 			b.mutex.RUnlock()
-			log.Printf("[SSE] broadcast event=%s to %d clients", message.Event, len(b.clients))
+			log.Printf("[SSE] broadcast event=%s kiosk=%q to %d clients", message.Event, message.KioskID, len(b.clients))
 
 		case <-ticker.C:
 			// Periodic check for inactive clients and garbage collection
@@ -122,13 +139,27 @@ func (b *Broadcaster) run() {
 // Broadcast sends a message with the given event type and data to all connected SSE clients.
 // If the event type is empty, it defaults to "message".
 func (b *Broadcaster) Broadcast(event string, data string) {
-	if event == "" {
-		event = "message"
-	}
+	b.BroadcastToKiosk("", event, data)
+}
 
-	message := Message{Event: event, Data: data}
+// BroadcastToKiosk sends a message only to the SSE clients subscribed to the given kiosk.
+// An empty kioskID sends the message to all connected clients, like Broadcast.
+func (b *Broadcaster) BroadcastToKiosk(kioskID, event, data string) {
+	b.send(Message{Event: event, Data: data, KioskID: kioskID})
+}
+
+// BroadcastToAdmins sends a message only to the SSE clients of signed-in accounts
+// allowed to view the scan log, such as the log monitoring page.
+func (b *Broadcaster) BroadcastToAdmins(event, data string) {
+	b.send(Message{Event: event, Data: data, AdminOnly: true})
+}
+
+func (b *Broadcaster) send(message Message) {
+	if message.Event == "" {
+		message.Event = "message"
+	}
 
-	log.Printf("[SSE DEBUG] Raw message being sent:\n%s\n\n", message)
+	log.Printf("[SSE DEBUG] Raw message being sent:\n%+v\n\n", message)
 
 	select {
 	case b.broadcast <- message:
@@ -1,116 +1,74 @@
 package handlers
 
 import (
-	"container/list"
-	"sync"
-	"time"
-)
+	"log"
+	"rfidsystem/internal/cache"
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
 
-// CacheItem represents a single cache entry
-// V is the value type
-// K is the key type
-// For simplicity, we'll use string as the key type
+	"github.com/gofiber/fiber/v2"
+)
 
-type cacheItem struct {
-	key       string
-	value     any
-	expiresAt time.Time
-}
+// Names of the kiosk view caches, as used in CACHE_SETTINGS.
+const (
+	cacheCardScans   = "card_scans"
+	cacheStudentInfo = "student_info"
+	cacheGrades      = "grades"
+	cacheTermGrades  = "term_grades"
+	cacheBills       = "bills"
+)
 
-// LRUCache represents an LRU cache with a time-to-live (TTL) for cache items.
-// It is safe for concurrent use since I embedded a Mutex in it.
-type LRUCache struct {
-	capacity int
-	ttl      time.Duration
-	mu       sync.Mutex
-	items    map[string]*list.Element
-	order    *list.List // Front is most recent
-}
+// The kiosk view caches, keyed by student ID (term grades by termGradesCacheKey)
+// and tagged with studentTag. They are created by configureCaches.
+var (
+	caches              = &cache.Registry{}
+	cardScanCache       cache.Cache[*model.StudentInfoViewModel]
+	studentInfoCache    cache.Cache[*model.StudentInfoViewModel]
+	gradesCache         cache.Cache[*model.Grades]
+	semesterGradesCache cache.Cache[*model.Grades]
+	billsCache          cache.Cache[*model.Bills]
+)
 
-// NewLRUCache creates a new LRUCache with the specified capacity and time-to-live (TTL).
-func NewLRUCache(capacity int, ttl time.Duration) *LRUCache {
-	return &LRUCache{
-		capacity: capacity,
-		ttl:      ttl,
-		items:    make(map[string]*list.Element),
-		order:    list.New(),
+// configureCaches creates the kiosk view caches with the configured backend,
+// sizes and time-to-live.
+func configureCaches(cfg config.CacheConfig) {
+	caches = &cache.Registry{}
+	var client *cache.RedisClient
+	if cfg.Backend == cache.BackendRedis {
+		client = cache.NewRedisClient(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
+		if err := client.Ping(); err != nil {
+			log.Printf("Redis cache at %s is unreachable, kiosk views will be read from the database until it is: %v", cfg.RedisAddr, err)
+		}
 	}
+	cardScanCache = newCache[*model.StudentInfoViewModel](cfg, client, cacheCardScans)
+	studentInfoCache = newCache[*model.StudentInfoViewModel](cfg, client, cacheStudentInfo)
+	gradesCache = newCache[*model.Grades](cfg, client, cacheGrades)
+	semesterGradesCache = newCache[*model.Grades](cfg, client, cacheTermGrades)
+	billsCache = newCache[*model.Bills](cfg, client, cacheBills)
 }
 
-// Get retrieves a value from the cache for a given key.
-// It returns the value and a boolean indicating if the key was found and the item was not expired.
-func (c *LRUCache) Get(key string) (interface{}, bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	elem, ok := c.items[key]
-	if !ok {
-		return nil, false
-	}
-	item := elem.Value.(*cacheItem)
-	if time.Now().After(item.expiresAt) {
-		// Expired, remove
-		c.order.Remove(elem)
-		delete(c.items, key)
-		return nil, false
+// newCache creates and registers a cache in Redis when client is set, or in memory.
+func newCache[T any](cfg config.CacheConfig, client *cache.RedisClient, name string) cache.Cache[T] {
+	settings := cfg.Settings(name)
+	if client != nil {
+		return cache.Register(caches, cache.NewRedis[T](name, client, settings.TTL))
 	}
-	// Move to front
-	c.order.MoveToFront(elem)
-	return item.value, true
+	return cache.Register(caches, cache.NewMemory[T](name, settings.Capacity, settings.TTL))
 }
 
-// Set adds or updates a key-value pair in the cache.
-// If the cache exceeds its capacity, the least recently used item is removed.
-func (c *LRUCache) Set(key string, value interface{}) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if elem, ok := c.items[key]; ok {
-		// Update existing
-		item := elem.Value.(*cacheItem)
-		item.value = value
-		item.expiresAt = time.Now().Add(c.ttl)
-		c.order.MoveToFront(elem)
-		return
-	}
-	// Add new
-	item := &cacheItem{
-		key:       key,
-		value:     value,
-		expiresAt: time.Now().Add(c.ttl),
-	}
-	elem := c.order.PushFront(item)
-	c.items[key] = elem
-	if c.order.Len() > c.capacity {
-		// Remove least recently used
-		oldest := c.order.Back()
-		if oldest != nil {
-			c.order.Remove(oldest)
-			oldestItem := oldest.Value.(*cacheItem)
-			delete(c.items, oldestItem.key)
-		}
-	}
+// studentTag returns the tag of cached views that show a student's data.
+func studentTag(studentID string) string {
+	return "student:" + studentID
 }
 
-// Optional: Delete key
-// Delete removes a key-value pair from the cache.
-func (c *LRUCache) Delete(key string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if elem, ok := c.items[key]; ok {
-		c.order.Remove(elem)
-		delete(c.items, key)
-	}
+// HandleCacheStats handles HTTP requests for the hit, miss and eviction counters
+// of every kiosk view cache, as JSON.
+func (h *AppHandler) HandleCacheStats(ctx *fiber.Ctx) error {
+	return ctx.JSON(caches.Stats())
 }
 
-// PurgeExpired removes all expired items from the cache.
-func (c *LRUCache) PurgeExpired() {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	now := time.Now()
-	for key, elem := range c.items {
-		item := elem.Value.(*cacheItem)
-		if now.After(item.expiresAt) {
-			c.order.Remove(elem)
-			delete(c.items, key)
-		}
-	}
+// invalidateStudent drops every cached view that shows the student's data,
+// e.g. after a payment or a grade changes their balance or grades.
+func invalidateStudent(studentID string) {
+	caches.InvalidateTag(studentTag(studentID))
 }
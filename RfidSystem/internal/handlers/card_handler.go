@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/repositories"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleIssueCard handles HTTP requests to register a new RFID card for a student.
+// It expects "card_uid" and "student_id" in the request body. Any card the student
+// already holds is marked as replaced, so lost cards can be reissued without
+// changing the student ID.
+func (h *AppHandler) HandleIssueCard(ctx *fiber.Ctx) error {
+	var req struct {
+		CardUID   string `json:"card_uid" form:"card_uid"`
+		StudentID string `json:"student_id" form:"student_id"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	req.CardUID = strings.TrimSpace(req.CardUID)
+	req.StudentID = strings.TrimSpace(req.StudentID)
+	if req.CardUID == "" || req.StudentID == "" {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Card UID and student ID are required")
+	}
+
+	existing, err := h.RFIDRepository.GetCardByUID(req.CardUID)
+	if err != nil {
+		log.Printf("Error checking card %s: %v", req.CardUID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if existing != nil {
+		return ctx.Status(fiber.StatusConflict).SendString(fmt.Sprintf("Card %s is already registered to %s", req.CardUID, existing.StudentID))
+	}
+
+	card, err := h.RFIDRepository.IssueCard(req.CardUID, req.StudentID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrStudentNotFound) {
+			return ctx.Status(fiber.StatusNotFound).SendString("Student not found")
+		}
+		log.Printf("Error issuing card %s to %s: %v", req.CardUID, req.StudentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	_ = h.db.LogScanEvent(card.CardUID, &card.StudentID, "card_issued", fmt.Sprintf("Card %s issued to student %s", card.CardUID, card.StudentID), "", "success")
+	return ctx.Status(fiber.StatusCreated).JSON(card)
+}
+
+// HandleGetStudentCards handles HTTP requests to list every card issued to a student.
+// It expects the student ID as a path parameter.
+func (h *AppHandler) HandleGetStudentCards(ctx *fiber.Ctx) error {
+	studentID := ctx.Params("id")
+	if studentID == "" {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Student ID is required")
+	}
+
+	cards, err := h.RFIDRepository.GetCardsByStudentID(studentID)
+	if err != nil {
+		log.Printf("Error retrieving cards for student %s: %v", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	return ctx.JSON(cards)
+}
+
+// HandleRevokeCard handles HTTP requests to report a card as lost or stolen.
+// It expects the card UID as a path parameter and an optional "reason" of
+// "lost" (default) or "stolen" in the request body. Scans of the card are then
+// refused and reported to admins instead of showing the student's records.
+func (h *AppHandler) HandleRevokeCard(ctx *fiber.Ctx) error {
+	cardUID := ctx.Params("uid")
+	var req struct {
+		Reason string `json:"reason" form:"reason"`
+	}
+	if err := ctx.BodyParser(&req); err != nil && len(ctx.Body()) > 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	state := strings.ToLower(strings.TrimSpace(req.Reason))
+	if state == "" {
+		state = model.CardStateLost
+	}
+	if state != model.CardStateLost && state != model.CardStateStolen {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Reason must be either lost or stolen")
+	}
+
+	existing, err := h.RFIDRepository.GetCardByUID(cardUID)
+	if err != nil {
+		log.Printf("Error checking card %s: %v", cardUID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if existing == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Card not found")
+	}
+	if !existing.IsActive() && !existing.IsBlocked() {
+		return ctx.Status(fiber.StatusConflict).SendString(fmt.Sprintf("Card is already %s", existing.State))
+	}
+
+	card, err := h.RFIDRepository.BlockCard(cardUID, state)
+	if err != nil {
+		log.Printf("Error blocking card %s: %v", cardUID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	_ = h.db.LogScanEvent(card.CardUID, &card.StudentID, "card_revoked", fmt.Sprintf("Card %s reported %s", card.CardUID, card.State), "", "warning")
+	return ctx.JSON(card)
+}
+
+// HandleReinstateCard handles HTTP requests to reactivate a card previously
+// reported lost or stolen. It expects the card UID as a path parameter.
+func (h *AppHandler) HandleReinstateCard(ctx *fiber.Ctx) error {
+	cardUID := ctx.Params("uid")
+
+	card, err := h.RFIDRepository.ReinstateCard(cardUID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrCardNotBlocked) || errors.Is(err, repositories.ErrActiveCardExists) {
+			return ctx.Status(fiber.StatusConflict).SendString(err.Error())
+		}
+		log.Printf("Error reinstating card %s: %v", cardUID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if card == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Card not found")
+	}
+
+	_ = h.db.LogScanEvent(card.CardUID, &card.StudentID, "card_reinstated", fmt.Sprintf("Card %s reinstated", card.CardUID), "", "success")
+	return ctx.JSON(card)
+}
@@ -2,25 +2,28 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"rfidsystem/internal/model"
+	"rfidsystem/internal/repositories"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
 )
 
-// LRU Cache for card scans
-var cardScanCache = NewLRUCache(5, time.Hour)
-
 // HandleCardScan handles HTTP POST requests for RFID card scans.
-// It processes the RFID from the request body or form, logs the event,
-// checks the cache, fetches student data from the repository if necessary,
-// stores the data in the cache, and broadcasts an HTMX instruction via SSE.
+// It processes the RFID and reader ID from the request body or form (the reader
+// authenticated by ReaderAuthRequired takes precedence), logs the event,
+// resolves the card UID to a student through the card registry, refuses blocked cards,
+// checks the cache, fetches student data from the repository if necessary, stores the
+// data in the cache, logs the exam permit decision during exam windows, and broadcasts an HTMX instruction via SSE to the kiosk paired
+// with the reader.
 func (h *AppHandler) HandleCardScan(ctx *fiber.Ctx) error {
 	var req struct {
-		RFID string `json:"rfid" form:"rfid"`
+		RFID     string `json:"rfid" form:"rfid"`
+		ReaderID string `json:"reader_id" form:"reader_id"`
 	}
 	if err := ctx.BodyParser(&req); err != nil {
 		log.Printf("Error parsing request body: %v", err)
@@ -41,55 +44,96 @@ func (h *AppHandler) HandleCardScan(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).SendString("RFID is required")
 	}
 
+	authenticatedReader, _ := ctx.Locals("readerID").(string)
+	readerID, ok := scanReaderID(authenticatedReader, req.ReaderID)
+	if !ok {
+		_ = h.db.LogScanEvent(rfid, nil, "reader_mismatch", fmt.Sprintf("Reader %s submitted a scan as %s", authenticatedReader, req.ReaderID), "", "failure")
+		return ctx.Status(fiber.StatusForbidden).SendString("Reader ID does not match the API key")
+	}
+	req.ReaderID = readerID
+
+	// Route the scan to the kiosk paired with the reader
+	kioskID, known, err := h.resolveReaderKiosk(req.ReaderID)
+	if err != nil {
+		_ = h.db.LogScanEvent(rfid, nil, "db_error", fmt.Sprintf("Database error: %v", err), "", "failure")
+		return ctx.Status(fiber.StatusInternalServerError).SendString(fmt.Sprintf("Database error: %v", err))
+	}
+	if !known {
+		_ = h.db.LogScanEvent(rfid, nil, "reader_not_registered", fmt.Sprintf("Scan from unregistered or inactive reader: %s", req.ReaderID), "", "failure")
+		return ctx.Status(fiber.StatusForbidden).SendString(fmt.Sprintf("Reader not registered: %s", req.ReaderID))
+	}
+
+	// Resolve the physical card to the student who holds it
+	card, err := h.RFIDRepository.GetCardByUID(rfid)
+	if err != nil {
+		_ = h.db.LogScanEvent(rfid, nil, "db_error", fmt.Sprintf("Database error: %v", err), "", "failure")
+		GetBroadcaster().BroadcastToKiosk(kioskID, "error", fmt.Sprintf(`{"message": "Database error: %v"}`, err))
+		return ctx.Status(fiber.StatusInternalServerError).SendString(fmt.Sprintf("Database error: %v", err))
+	}
+	if card.IsBlocked() {
+		GetBroadcaster().BroadcastToKiosk(kioskID, "studentcallback", h.handleBlockedCard(card))
+		return ctx.Status(fiber.StatusForbidden).SendString(fmt.Sprintf("Card blocked: %s", rfid))
+	}
+	if !card.IsActive() || card.HolderInactive() {
+		h.logInactiveCard(rfid, card)
+		htmxInstruction := `<div hx-get="/error" hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`
+		GetBroadcaster().BroadcastToKiosk(kioskID, "studentcallback", htmxInstruction)
+		return ctx.Status(fiber.StatusNotFound).SendString(fmt.Sprintf("Card not registered: %s", rfid))
+	}
+	studentId := card.StudentID
+	h.recordAttendance(req.ReaderID, card)
+
 	// Try cache first
-	cached, found := cardScanCache.Get(rfid)
-	if found {
+	if student, found := cardScanCache.Get(studentId); found {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("Cache type assertion failed: %v", r)
+				log.Printf("Error reading cached student %s: %v", studentId, r)
 			}
 		}()
-		student, ok := cached.(*model.StudentInfoViewModel)
-		if ok && student != nil {
+		if student != nil && student.Student != nil {
 			// Log cache hit event
 			_ = h.db.LogScanEvent(rfid, &student.Student.StudentID, "scan_cache_hit", fmt.Sprintf("Cache hit for student %s", *student.Student.FirstName+" "+*student.Student.LastName), "", "info")
-			htmxInstruction := fmt.Sprintf(`<div hx-post="/student-partial" hx-vals='{"rfid":"%s"}' hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`, rfid)
-			GetBroadcaster().Broadcast("studentcallback", htmxInstruction)
+			h.logExamPermit(rfid, student)
+			htmxInstruction := fmt.Sprintf(`<div hx-post="/student-partial" hx-vals='{"rfid":"%s"}' hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`, studentId)
+			GetBroadcaster().BroadcastToKiosk(kioskID, "studentcallback", htmxInstruction)
 			return ctx.SendString("Processing (cache)")
 		}
 	}
 
-	student, err := h.RFIDRepository.GetStudentSummaryData(rfid)
+	student, err := h.RFIDRepository.GetStudentSummaryData(studentId, h.config.Grading)
 
 	if err != nil {
 		_ = h.db.LogScanEvent(rfid, nil, "db_error", fmt.Sprintf("Database error: %v", err), "", "failure")
-		GetBroadcaster().Broadcast("error", fmt.Sprintf(`{"message": "Database error: %v"}`, err))
+		GetBroadcaster().BroadcastToKiosk(kioskID, "error", fmt.Sprintf(`{"message": "Database error: %v"}`, err))
 		return ctx.Status(fiber.StatusInternalServerError).SendString(fmt.Sprintf("Database error: %v", err))
 	}
 
 	if student == nil {
 		_ = h.db.LogScanEvent(rfid, nil, "student_not_found", fmt.Sprintf("Student not found: %s", rfid), "", "failure")
 		htmxInstruction := `<div hx-get="/error" hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`
-		GetBroadcaster().Broadcast("studentcallback", htmxInstruction)
+		GetBroadcaster().BroadcastToKiosk(kioskID, "studentcallback", htmxInstruction)
 		return ctx.Status(fiber.StatusNotFound).SendString(fmt.Sprintf("Student not found: %s", rfid))
 	}
 
 	// Store in cache
-	cardScanCache.Set(rfid, student)
+	cardScanCache.Set(studentId, student, studentTag(studentId))
+	h.logExamPermit(rfid, student)
 
-	htmxInstruction := fmt.Sprintf(`<div hx-post="/student-partial" hx-vals='{"rfid":"%s"}' hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`, rfid)
+	htmxInstruction := fmt.Sprintf(`<div hx-post="/student-partial" hx-vals='{"rfid":"%s"}' hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`, studentId)
 
-	GetBroadcaster().Broadcast("studentcallback", htmxInstruction)
+	GetBroadcaster().BroadcastToKiosk(kioskID, "studentcallback", htmxInstruction)
 	_ = h.db.LogScanEvent(rfid, &student.Student.StudentID, "info_displayed", fmt.Sprintf("Displayed info for student : %s", *student.Student.FirstName+" "+*student.Student.LastName), "", "success")
 	return ctx.SendString("Processing")
 }
 
 // HandleCardScanWS handles websocket connections for real-time RFID card scans.
-// It reads messages from the WebSocket, processes the card ID,
-// checks the cache, fetches student data if needed, stores in cache,
-// broadcasts an HTMX instruction via SSE, and sends the instruction back over the WebSocket.
+// It reads messages from the WebSocket, resolves the card ID to a student
+// through the card registry, checks the cache, fetches student data if needed, stores in cache,
+// broadcasts an HTMX instruction via SSE to the kiosk paired with the message's reader,
+// and sends the instruction back over the WebSocket.
 func (h *AppHandler) HandleCardScanWS(c *websocket.Conn) {
 	defer c.Close()
+	authenticatedReader, _ := c.Locals("readerID").(string)
 	for {
 		_, msg, err := c.ReadMessage()
 		if err != nil {
@@ -98,18 +142,38 @@ func (h *AppHandler) HandleCardScanWS(c *websocket.Conn) {
 		}
 		// Parse JSON message
 		var payload struct {
-			Status string `json:"status"`
-			CardId string `json:"cardId"`
+			Status   string `json:"status"`
+			CardId   string `json:"cardId"`
+			ReaderId string `json:"readerId"`
 		}
 		if err := json.Unmarshal(msg, &payload); err != nil {
 			log.Printf("WS JSON parse error: %v", err)
 			c.WriteMessage(websocket.TextMessage, []byte("Invalid message format"))
 			continue
 		}
+		readerID, ok := scanReaderID(authenticatedReader, payload.ReaderId)
+		if !ok {
+			_ = h.db.LogScanEvent(payload.CardId, nil, "reader_mismatch", fmt.Sprintf("Reader %s submitted a scan as %s", authenticatedReader, payload.ReaderId), "", "failure")
+			c.WriteMessage(websocket.TextMessage, []byte("Reader ID does not match the API key"))
+			continue
+		}
+		payload.ReaderId = readerID
+
+		// Route the scan to the kiosk paired with the reader
+		kioskID, known, err := h.resolveReaderKiosk(payload.ReaderId)
+		if err != nil {
+			c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("Database error: %v", err)))
+			continue
+		}
+		if !known {
+			_ = h.db.LogScanEvent(payload.CardId, nil, "reader_not_registered", fmt.Sprintf("Scan from unregistered or inactive reader: %s", payload.ReaderId), "", "failure")
+			c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("Reader not registered: %s", payload.ReaderId)))
+			continue
+		}
 		// If absent, render home page
 		if payload.Status == "absent" {
 			htmxInstruction := `<div hx-get="/" hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`
-			GetBroadcaster().Broadcast("studentcallback", htmxInstruction)
+			GetBroadcaster().BroadcastToKiosk(kioskID, "studentcallback", htmxInstruction)
 			c.WriteMessage(websocket.TextMessage, []byte(htmxInstruction))
 			continue
 		}
@@ -119,19 +183,40 @@ func (h *AppHandler) HandleCardScanWS(c *websocket.Conn) {
 			c.WriteMessage(websocket.TextMessage, []byte("RFID is required"))
 			continue
 		}
+		// Resolve the physical card to the student who holds it
+		card, err := h.RFIDRepository.GetCardByUID(rfid)
+		if err != nil {
+			c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("Database error: %v", err)))
+			continue
+		}
+		if card.IsBlocked() {
+			htmxInstruction := h.handleBlockedCard(card)
+			c.WriteMessage(websocket.TextMessage, []byte(htmxInstruction))
+			GetBroadcaster().BroadcastToKiosk(kioskID, "studentcallback", htmxInstruction)
+			continue
+		}
+		if !card.IsActive() || card.HolderInactive() {
+			h.logInactiveCard(rfid, card)
+			htmxInstruction := `<div hx-get="/error" hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`
+			c.WriteMessage(websocket.TextMessage, []byte(htmxInstruction))
+			GetBroadcaster().BroadcastToKiosk(kioskID, "studentcallback", htmxInstruction)
+			continue
+		}
+		studentId := card.StudentID
+		h.recordAttendance(payload.ReaderId, card)
 		// Try cache first
-		cached, found := cardScanCache.Get(rfid)
-		if found {
-			if s, ok := cached.(*model.StudentInfoViewModel); ok && s != nil {
-				htmxInstruction := fmt.Sprintf(`<div hx-post="/student-partial" hx-vals='{"rfid":"%s"}' hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`, rfid)
+		if s, found := cardScanCache.Get(studentId); found {
+			if s != nil {
+				h.logExamPermit(rfid, s)
+				htmxInstruction := fmt.Sprintf(`<div hx-post="/student-partial" hx-vals='{"rfid":"%s"}' hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`, studentId)
 				c.WriteMessage(websocket.TextMessage, []byte(htmxInstruction))
 				c.WriteMessage(websocket.TextMessage, []byte("Processing (cache)"))
-				GetBroadcaster().Broadcast("studentcallback", htmxInstruction)
+				GetBroadcaster().BroadcastToKiosk(kioskID, "studentcallback", htmxInstruction)
 				continue
 			}
 		}
 		// Fetch from DB
-		student, err := h.RFIDRepository.GetStudentSummaryData(rfid)
+		student, err := h.RFIDRepository.GetStudentSummaryData(studentId, h.config.Grading)
 		if err != nil {
 			c.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("Database error: %v", err)))
 			continue
@@ -139,14 +224,98 @@ func (h *AppHandler) HandleCardScanWS(c *websocket.Conn) {
 		if student == nil {
 			htmxInstruction := `<div hx-get="/error" hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`
 			c.WriteMessage(websocket.TextMessage, []byte(htmxInstruction))
-			GetBroadcaster().Broadcast("studentcallback", htmxInstruction)
+			GetBroadcaster().BroadcastToKiosk(kioskID, "studentcallback", htmxInstruction)
 			continue
 		}
 		// Store in cache
-		cardScanCache.Set(rfid, student)
-		htmxInstruction := fmt.Sprintf(`<div hx-post="/student-partial" hx-vals='{"rfid":"%s"}' hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`, rfid)
-		GetBroadcaster().Broadcast("studentcallback", htmxInstruction)
+		cardScanCache.Set(studentId, student, studentTag(studentId))
+		h.logExamPermit(rfid, student)
+		htmxInstruction := fmt.Sprintf(`<div hx-post="/student-partial" hx-vals='{"rfid":"%s"}' hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`, studentId)
+		GetBroadcaster().BroadcastToKiosk(kioskID, "studentcallback", htmxInstruction)
 		c.WriteMessage(websocket.TextMessage, []byte(htmxInstruction))
 		c.WriteMessage(websocket.TextMessage, []byte("Processing"))
 	}
 }
+
+// logInactiveCard records a scan of a card that is unknown or no longer active,
+// or whose holder was deactivated.
+func (h *AppHandler) logInactiveCard(rfid string, card *model.StudentCard) {
+	if card == nil {
+		_ = h.db.LogScanEvent(rfid, nil, "card_not_registered", fmt.Sprintf("Card not registered: %s", rfid), "", "failure")
+		return
+	}
+	if card.IsActive() {
+		_ = h.db.LogScanEvent(rfid, &card.StudentID, "student_inactive", fmt.Sprintf("Card %s belongs to deactivated student %s", rfid, card.StudentID), "", "failure")
+		return
+	}
+	_ = h.db.LogScanEvent(rfid, &card.StudentID, "card_inactive", fmt.Sprintf("Card %s is %s", rfid, card.State), "", "failure")
+}
+
+// handleBlockedCard logs a scan of a lost or stolen card, alerts signed-in admins over SSE
+// and returns the HTMX instruction that shows the card blocked screen on the kiosk.
+// The student's records are never loaded for a blocked card.
+func (h *AppHandler) handleBlockedCard(card *model.StudentCard) string {
+	message := fmt.Sprintf("Blocked card %s (%s) scanned", card.CardUID, card.State)
+	_ = h.db.LogScanEvent(card.CardUID, &card.StudentID, "card_blocked", message, fmt.Sprintf(`{"card_state": "%s"}`, card.State), "warning")
+
+	alert, err := json.Marshal(map[string]string{
+		"card_uid":   card.CardUID,
+		"student_id": card.StudentID,
+		"card_state": card.State,
+		"time":       time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		log.Printf("Failed to marshal blocked card alert: %v", err)
+	} else {
+		GetBroadcaster().BroadcastToAdmins("cardblocked", string(alert))
+	}
+
+	return `<div hx-get="/card-blocked" hx-trigger="load" hx-swap="innerHTML" hx-target="#main"></div>`
+}
+
+// recordAttendance records an IN/OUT event when the card was scanned at a
+// configured gate reader. Scans at any other reader are ignored, and repeated
+// scans within the minimum interval are only logged.
+func (h *AppHandler) recordAttendance(readerID string, card *model.StudentCard) {
+	if readerID == "" || !h.config.Attendance.IsGate(readerID) {
+		return
+	}
+	attendance := h.config.Attendance
+	event, err := h.RFIDRepository.RecordAttendance(card.StudentID, card.CardUID, readerID, attendance.Gates[readerID], attendance.MinScanInterval)
+	if errors.Is(err, repositories.ErrAttendanceTooSoon) {
+		_ = h.db.LogScanEvent(card.CardUID, &card.StudentID, "attendance_ignored", fmt.Sprintf("Repeated scan at %s within %s ignored", readerID, attendance.MinScanInterval), "", "info")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to record attendance for %s: %v", card.StudentID, err)
+		_ = h.db.LogScanEvent(card.CardUID, &card.StudentID, "attendance_error", fmt.Sprintf("Failed to record attendance: %v", err), "", "failure")
+		return
+	}
+	_ = h.db.LogScanEvent(card.CardUID, &card.StudentID, "attendance", fmt.Sprintf("Attendance %s at %s", event.Direction, readerID), "", "success")
+}
+
+// resolveReaderKiosk returns the kiosk paired with the reader a scan came from.
+// Scans without a reader ID are broadcast to every kiosk (empty kiosk ID), but only
+// while no reader is registered, as in single-kiosk deployments. known is false if
+// the reader is not registered or inactive, or the reader ID is missing although
+// readers are registered.
+func (h *AppHandler) resolveReaderKiosk(readerID string) (kioskID string, known bool, err error) {
+	if readerID == "" {
+		hasReaders, err := h.RFIDRepository.HasReaders()
+		if err != nil {
+			return "", false, err
+		}
+		return "", !hasReaders, nil
+	}
+	reader, err := h.RFIDRepository.GetReader(readerID)
+	if err != nil {
+		return "", false, err
+	}
+	if reader == nil || !reader.IsActive {
+		return "", false, nil
+	}
+	if reader.KioskID == nil {
+		return "", true, nil
+	}
+	return *reader.KioskID, true, nil
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/services"
+	"sort"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleDelinquentAccounts handles HTTP requests for the delinquent accounts report:
+// every assessment with at least one overdue installment, with the overdue amount
+// and the late penalty, most overdue first. It accepts the query parameters
+// "as_of" (YYYY-MM-DD, defaults to today), "term_id" to limit the report to one
+// term and "format=csv" to download it as CSV.
+func (h *AppHandler) HandleDelinquentAccounts(c *fiber.Ctx) error {
+	asOf, err := parseReportDate(c.Query("as_of"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString("Invalid as_of date, expected YYYY-MM-DD")
+	}
+	var termID *int64
+	if value := c.Query("term_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid term_id")
+		}
+		termID = &id
+	}
+
+	assessments, err := h.RFIDRepository.GetOutstandingAssessments(termID)
+	if err != nil {
+		log.Printf("HandleDelinquentAccounts error: %v", err)
+		return c.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	accounts := []model.DelinquentAccount{}
+	for _, assessment := range assessments {
+		if account, ok := services.SummarizeDelinquency(assessment, asOf, h.config.Billing.LatePenalty); ok {
+			accounts = append(accounts, account)
+		}
+	}
+	sort.SliceStable(accounts, func(i, j int) bool {
+		if accounts[i].DaysOverdue != accounts[j].DaysOverdue {
+			return accounts[i].DaysOverdue > accounts[j].DaysOverdue
+		}
+		return accounts[i].OverdueAmount > accounts[j].OverdueAmount
+	})
+
+	if c.Query("format") != "csv" {
+		return c.JSON(fiber.Map{
+			"as_of":    asOf.Format("2006-01-02"),
+			"accounts": accounts,
+		})
+	}
+
+	c.Set("Content-Type", "text/csv")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=delinquent-accounts-%s.csv", asOf.Format("2006-01-02")))
+	writer := csv.NewWriter(c)
+	defer writer.Flush()
+	writer.Write([]string{"StudentID", "Name", "Program", "BlockSection", "AssessmentNumber", "RemainingBalance", "OverdueAmount", "Penalty", "OverdueInstallments", "OldestDueDate", "DaysOverdue"})
+	for _, a := range accounts {
+		writer.Write([]string{
+			a.StudentID,
+			a.StudentName,
+			stringValue(a.Program),
+			stringValue(a.BlockSection),
+			strconv.FormatInt(a.AssessmentNumber, 10),
+			formatAmount(a.RemainingBalance),
+			formatAmount(a.OverdueAmount),
+			formatAmount(a.Penalty),
+			strconv.Itoa(a.OverdueInstallments),
+			a.OldestDueDate,
+			strconv.Itoa(a.DaysOverdue),
+		})
+	}
+	return nil
+}
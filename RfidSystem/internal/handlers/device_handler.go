@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+)
+
+// HandleListKiosks handles HTTP requests to list all registered kiosks.
+func (h *AppHandler) HandleListKiosks(ctx *fiber.Ctx) error {
+	kiosks, err := h.RFIDRepository.ListKiosks()
+	if err != nil {
+		log.Printf("Error listing kiosks: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(kiosks)
+}
+
+// HandleSaveKiosk handles HTTP requests to register or update a kiosk.
+// It expects "kiosk_id" and "name", and optionally "location", in the request body.
+func (h *AppHandler) HandleSaveKiosk(ctx *fiber.Ctx) error {
+	var req struct {
+		KioskID  string `json:"kiosk_id" form:"kiosk_id"`
+		Name     string `json:"name" form:"name"`
+		Location string `json:"location" form:"location"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	req.KioskID = strings.TrimSpace(req.KioskID)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.KioskID == "" || req.Name == "" {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Kiosk ID and name are required")
+	}
+
+	kiosk := &model.Kiosk{ID: req.KioskID, Name: req.Name}
+	if location := strings.TrimSpace(req.Location); location != "" {
+		kiosk.Location = &location
+	}
+	if err := h.RFIDRepository.SaveKiosk(kiosk); err != nil {
+		log.Printf("Error saving kiosk %s: %v", req.KioskID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(kiosk)
+}
+
+// HandleListReaders handles HTTP requests to list all registered readers and their kiosk pairing.
+func (h *AppHandler) HandleListReaders(ctx *fiber.Ctx) error {
+	readers, err := h.RFIDRepository.ListReaders()
+	if err != nil {
+		log.Printf("Error listing readers: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(readers)
+}
+
+// HandleSaveReader handles HTTP requests to register or update a reader.
+// It expects "reader_id" and "name", and optionally "kiosk_id", "location" and
+// "is_active" (defaults to true), in the request body. Scans on the reader are
+// broadcast only to the kiosk it is paired with.
+func (h *AppHandler) HandleSaveReader(ctx *fiber.Ctx) error {
+	var req struct {
+		ReaderID string `json:"reader_id" form:"reader_id"`
+		KioskID  string `json:"kiosk_id" form:"kiosk_id"`
+		Name     string `json:"name" form:"name"`
+		Location string `json:"location" form:"location"`
+		IsActive *bool  `json:"is_active" form:"is_active"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	req.ReaderID = strings.TrimSpace(req.ReaderID)
+	req.Name = strings.TrimSpace(req.Name)
+	if req.ReaderID == "" || req.Name == "" {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Reader ID and name are required")
+	}
+
+	reader := &model.Reader{ID: req.ReaderID, Name: req.Name, IsActive: true}
+	if req.IsActive != nil {
+		reader.IsActive = *req.IsActive
+	}
+	if location := strings.TrimSpace(req.Location); location != "" {
+		reader.Location = &location
+	}
+	if kioskID := strings.TrimSpace(req.KioskID); kioskID != "" {
+		kiosk, err := h.RFIDRepository.GetKiosk(kioskID)
+		if err != nil {
+			log.Printf("Error checking kiosk %s: %v", kioskID, err)
+			return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+		}
+		if kiosk == nil {
+			return ctx.Status(fiber.StatusNotFound).SendString("Kiosk not registered")
+		}
+		reader.KioskID = &kioskID
+	}
+
+	if err := h.RFIDRepository.SaveReader(reader); err != nil {
+		log.Printf("Error saving reader %s: %v", req.ReaderID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(reader)
+}
+
+// HandleIssueReaderKey handles HTTP requests to issue a new API key for a reader.
+// Any previous key of the reader stops working. The key is only returned in this
+// response; the server keeps its hash.
+func (h *AppHandler) HandleIssueReaderKey(ctx *fiber.Ctx) error {
+	readerID := ctx.Params("id")
+	reader, err := h.RFIDRepository.GetReader(readerID)
+	if err != nil {
+		log.Printf("Error checking reader %s: %v", readerID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if reader == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Reader not registered")
+	}
+
+	key, err := generateToken()
+	if err != nil {
+		log.Printf("Error generating key for reader %s: %v", readerID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if err := h.RFIDRepository.SetReaderAPIKey(readerID, hashToken(key)); err != nil {
+		log.Printf("Error saving key for reader %s: %v", readerID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	email, _ := ctx.Locals("userEmail").(string)
+	_ = h.db.LogScanEvent("", nil, "reader_key_issued", fmt.Sprintf("API key issued for reader %s by %s", readerID, email), "", "info")
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"reader_id": readerID,
+		"api_key":   key,
+	})
+}
+
+// HandleRevokeReaderKey handles HTTP requests to revoke the API key of a reader.
+func (h *AppHandler) HandleRevokeReaderKey(ctx *fiber.Ctx) error {
+	readerID := ctx.Params("id")
+	reader, err := h.RFIDRepository.GetReader(readerID)
+	if err != nil {
+		log.Printf("Error checking reader %s: %v", readerID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if reader == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Reader not registered")
+	}
+
+	if err := h.RFIDRepository.RevokeReaderAPIKey(readerID); err != nil {
+		log.Printf("Error revoking key for reader %s: %v", readerID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	email, _ := ctx.Locals("userEmail").(string)
+	_ = h.db.LogScanEvent("", nil, "reader_key_revoked", fmt.Sprintf("API key revoked for reader %s by %s", readerID, email), "", "warning")
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// ReaderAuthRequired is a middleware for the card scan endpoints. A request must
+// carry the "X-Reader-ID" and "X-Reader-Key" headers of an active reader, or
+// come from a signed-in account that is allowed to scan cards. The WebSocket
+// handshake may pass the reader credentials as "reader_id" and "key" query
+// parameters instead, for reader firmware that cannot set headers.
+// The authenticated reader ID is stored in the request locals as "readerID".
+func (h *AppHandler) ReaderAuthRequired() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		readerID, key := c.Get("X-Reader-ID"), c.Get("X-Reader-Key")
+		if key == "" && websocket.IsWebSocketUpgrade(c) {
+			readerID, key = c.Query("reader_id"), c.Query("key")
+		}
+
+		if key != "" {
+			reader, err := h.RFIDRepository.GetReader(readerID)
+			if err != nil {
+				log.Printf("Error checking reader %s: %v", readerID, err)
+				return c.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+			}
+			if reader == nil || !reader.IsActive || !reader.HasAPIKey() ||
+				subtle.ConstantTimeCompare([]byte(hashToken(key)), []byte(reader.APIKeyHash)) != 1 {
+				_ = h.db.LogScanEvent("", nil, "reader_auth_failed", fmt.Sprintf("Invalid API key for reader: %s", readerID), "", "failure")
+				return c.Status(fiber.StatusUnauthorized).SendString("Invalid reader credentials")
+			}
+			c.Locals("readerID", reader.ID)
+			return c.Next()
+		}
+
+		if userSession, ok := lookupRequestSession(c); ok && HasPermission(userSession.role, PermScanCards) {
+			c.Locals("userEmail", userSession.email)
+			c.Locals("userRole", userSession.role)
+			return c.Next()
+		}
+
+		_ = h.db.LogScanEvent("", nil, "reader_auth_failed", fmt.Sprintf("Scan without reader credentials from %s", c.IP()), "", "failure")
+		return c.Status(fiber.StatusUnauthorized).SendString("Reader credentials required")
+	}
+}
+
+// scanReaderID returns the reader a scan is attributed to. A reader that
+// authenticated with its API key may only submit scans as itself; ok is false
+// if the scan claims to come from another reader.
+func scanReaderID(authenticated, claimed string) (readerID string, ok bool) {
+	if authenticated == "" {
+		return claimed, true
+	}
+	if claimed != "" && claimed != authenticated {
+		return "", false
+	}
+	return authenticated, true
+}
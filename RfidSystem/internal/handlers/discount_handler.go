@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/repositories"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleListDiscountTypes handles HTTP requests to list every discount type,
+// including retired ones.
+func (h *AppHandler) HandleListDiscountTypes(ctx *fiber.Ctx) error {
+	discountTypes, err := h.RFIDRepository.ListDiscountTypes()
+	if err != nil {
+		log.Printf("Error listing discount types: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(discountTypes)
+}
+
+// HandleSaveDiscountType handles HTTP requests to define a discount type, or to
+// update one when the ":id" path parameter is present. It expects "name",
+// "is_percentage" and "value" (a percent of the total fees, or a fixed amount),
+// and optionally "description" and "is_active" (defaults to true), in the request body.
+func (h *AppHandler) HandleSaveDiscountType(ctx *fiber.Ctx) error {
+	var req struct {
+		Name         string  `json:"name" form:"name"`
+		Description  string  `json:"description" form:"description"`
+		IsPercentage bool    `json:"is_percentage" form:"is_percentage"`
+		Value        float64 `json:"value" form:"value"`
+		IsActive     *bool   `json:"is_active" form:"is_active"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Name is required")
+	}
+	if req.Value <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Value must be greater than zero")
+	}
+	if req.IsPercentage && req.Value > 100 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("A percentage discount cannot exceed 100")
+	}
+
+	discountType := &model.DiscountType{
+		Name:         req.Name,
+		IsPercentage: req.IsPercentage,
+		Value:        req.Value,
+		IsActive:     true,
+	}
+	if req.IsActive != nil {
+		discountType.IsActive = *req.IsActive
+	}
+	if description := strings.TrimSpace(req.Description); description != "" {
+		discountType.Description = &description
+	}
+	status := fiber.StatusCreated
+	if ctx.Params("id") != "" {
+		id, err := ctx.ParamsInt("id")
+		if err != nil || id <= 0 {
+			return ctx.Status(fiber.StatusBadRequest).SendString("Invalid discount type ID")
+		}
+		discountType.ID = int64(id)
+		status = fiber.StatusOK
+	}
+
+	if err := h.RFIDRepository.SaveDiscountType(discountType); err != nil {
+		if errors.Is(err, repositories.ErrDiscountTypeNotFound) {
+			return ctx.Status(fiber.StatusNotFound).SendString("Discount type not found")
+		}
+		log.Printf("Error saving discount type %q: %v", req.Name, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	savedBy, _ := ctx.Locals("userEmail").(string)
+	_ = h.db.LogScanEvent("", nil, "discount_type_saved", fmt.Sprintf("Discount type %d (%s) saved by %s", discountType.ID, discountType.Name, savedBy), "", "info")
+	return ctx.Status(status).JSON(discountType)
+}
+
+// HandleListAssessmentDiscounts handles HTTP requests for the discounts applied to
+// an assessment and its discount audit trail.
+func (h *AppHandler) HandleListAssessmentDiscounts(ctx *fiber.Ctx) error {
+	assessmentNumber, err := ctx.ParamsInt("id")
+	if err != nil || assessmentNumber <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid assessment number")
+	}
+	discounts, err := h.RFIDRepository.GetAssessmentDiscounts(int64(assessmentNumber))
+	if err != nil {
+		log.Printf("Error listing discounts of assessment %d: %v", assessmentNumber, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	audit, err := h.RFIDRepository.GetDiscountAudit(int64(assessmentNumber))
+	if err != nil {
+		log.Printf("Error listing discount audit of assessment %d: %v", assessmentNumber, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(fiber.Map{
+		"discounts": discounts,
+		"audit":     audit,
+	})
+}
+
+// HandleGrantDiscount handles HTTP requests to apply a discount type to an assessment.
+// It expects the assessment number as a path parameter and "discount_type_id" and
+// optionally "reason" in the request body. The assessment totals are recomputed
+// in the same transaction and the grant is recorded in the audit trail.
+func (h *AppHandler) HandleGrantDiscount(ctx *fiber.Ctx) error {
+	assessmentNumber, err := ctx.ParamsInt("id")
+	if err != nil || assessmentNumber <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid assessment number")
+	}
+	var req struct {
+		DiscountTypeID int64  `json:"discount_type_id" form:"discount_type_id"`
+		Reason         string `json:"reason" form:"reason"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	if req.DiscountTypeID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Discount type is required")
+	}
+
+	grantedBy, _ := ctx.Locals("userEmail").(string)
+	grant := model.DiscountGrant{
+		AssessmentNumber: int64(assessmentNumber),
+		DiscountTypeID:   req.DiscountTypeID,
+		GrantedBy:        grantedBy,
+	}
+	if reason := strings.TrimSpace(req.Reason); reason != "" {
+		grant.Reason = &reason
+	}
+
+	change, err := h.RFIDRepository.GrantDiscount(grant)
+	if err != nil {
+		return h.discountError(ctx, assessmentNumber, err)
+	}
+	if change == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Assessment not found")
+	}
+	h.logDiscountChange(change)
+	return ctx.Status(fiber.StatusCreated).JSON(change)
+}
+
+// HandleRevokeDiscount handles HTTP requests to remove a discount from an assessment.
+// It expects the assessment number and the assessment discount ID as path parameters
+// and optionally "reason" in the request body.
+func (h *AppHandler) HandleRevokeDiscount(ctx *fiber.Ctx) error {
+	assessmentNumber, err := ctx.ParamsInt("id")
+	if err != nil || assessmentNumber <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid assessment number")
+	}
+	discountID, err := ctx.ParamsInt("discountId")
+	if err != nil || discountID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid discount ID")
+	}
+	var req struct {
+		Reason string `json:"reason" form:"reason"`
+	}
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(&req); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+		}
+	}
+	var reason *string
+	if trimmed := strings.TrimSpace(req.Reason); trimmed != "" {
+		reason = &trimmed
+	}
+
+	revokedBy, _ := ctx.Locals("userEmail").(string)
+	change, err := h.RFIDRepository.RevokeDiscount(int64(assessmentNumber), int64(discountID), revokedBy, reason)
+	if err != nil {
+		return h.discountError(ctx, assessmentNumber, err)
+	}
+	if change == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Discount not found")
+	}
+	h.logDiscountChange(change)
+	return ctx.JSON(change)
+}
+
+// discountError writes the response for an error from granting or revoking a discount.
+func (h *AppHandler) discountError(ctx *fiber.Ctx, assessmentNumber int, err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrDiscountTypeNotFound):
+		return ctx.Status(fiber.StatusNotFound).SendString(err.Error())
+	case errors.Is(err, repositories.ErrDiscountTypeInactive),
+		errors.Is(err, repositories.ErrDiscountAlreadyApplied),
+		errors.Is(err, repositories.ErrDiscountExceedsBalance):
+		return ctx.Status(fiber.StatusConflict).SendString(err.Error())
+	}
+	log.Printf("Error changing discounts of assessment %d: %v", assessmentNumber, err)
+	return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+}
+
+// logDiscountChange logs a discount grant or revocation and drops the student's
+// cached bills so the kiosk shows the new balance.
+func (h *AppHandler) logDiscountChange(change *model.DiscountChange) {
+	studentID := change.Assessment.StudentID
+	if studentID != nil {
+		invalidateStudent(*studentID)
+	}
+	_ = h.db.LogScanEvent("", studentID, "discount_"+change.Audit.Action,
+		fmt.Sprintf("Discount %s (%s) %s on assessment %d by %s", change.Audit.DiscountName, formatAmount(change.Discount.AppliedAmount),
+			change.Audit.Action, change.Assessment.ID, change.Audit.PerformedBy),
+		fmt.Sprintf(`{"audit_id": %d, "remaining_balance": %s}`, change.Audit.ID, formatAmount(change.Assessment.RemainingBalance)), "success")
+}
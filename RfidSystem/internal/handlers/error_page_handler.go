@@ -5,3 +5,8 @@ import "github.com/gofiber/fiber/v2"
 func (h *AppHandler) HandleError(c *fiber.Ctx) error {
 	return c.Render("partials/error_page", fiber.Map{})
 }
+
+// HandleCardBlocked renders the screen shown on the kiosk when a lost or stolen card is scanned.
+func (h *AppHandler) HandleCardBlocked(c *fiber.Ctx) error {
+	return c.Render("partials/card_blocked", fiber.Map{})
+}
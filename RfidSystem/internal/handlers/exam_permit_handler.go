@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/services"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// currentExamPermit returns the student's exam permit for the exam window in
+// progress, or nil outside of exam windows.
+func (h *AppHandler) currentExamPermit(student *model.StudentInfoViewModel) *model.ExamPermit {
+	if student == nil || student.Student == nil {
+		return nil
+	}
+	window, ok := h.config.Exams.ActiveWindow(time.Now())
+	if !ok {
+		return nil
+	}
+
+	standing := model.AssessmentStanding{
+		StudentID:    student.Student.StudentID,
+		StudentName:  strings.TrimSpace(stringValue(student.Student.FirstName) + " " + stringValue(student.Student.LastName)),
+		Program:      student.Student.Program,
+		BlockSection: student.Student.BlockSection,
+		Schedules:    student.PaymentSchedules,
+	}
+	if student.Assessment != nil {
+		standing.AssessmentNumber = student.Assessment.ID
+		standing.TotalPaymentAmount = student.Assessment.TotalPaymentAmount
+		standing.RemainingBalance = student.Assessment.RemainingBalance
+		standing.PerExamFee = student.Assessment.PerExamFee
+	}
+	permit := services.EvaluateExamPermit(standing, window, h.config.Exams.Windows)
+	return &permit
+}
+
+// logExamPermit records the exam permit decision for a scanned card in the scan
+// log. Nothing is logged outside of exam windows.
+func (h *AppHandler) logExamPermit(rfid string, student *model.StudentInfoViewModel) {
+	permit := h.currentExamPermit(student)
+	if permit == nil {
+		return
+	}
+
+	details, err := json.Marshal(permit)
+	if err != nil {
+		log.Printf("Failed to marshal exam permit: %v", err)
+	}
+	if permit.Cleared {
+		_ = h.db.LogScanEvent(rfid, &permit.StudentID, "exam_permit", fmt.Sprintf("Exam permit cleared for the %s exam", permit.Exam), string(details), "success")
+		return
+	}
+	_ = h.db.LogScanEvent(rfid, &permit.StudentID, "exam_permit", fmt.Sprintf("Exam permit not cleared for the %s exam: %s", permit.Exam, permit.Reason), string(details), "warning")
+}
+
+// HandleExamPermits handles HTTP requests for the exam permit eligibility list of
+// a block section. It requires the "block_section" query parameter and accepts
+// "exam" to pick an exam window (defaults to the one in progress), "term_id"
+// (defaults to the current term) and "format=csv" to download the list as CSV.
+func (h *AppHandler) HandleExamPermits(c *fiber.Ctx) error {
+	blockSection := strings.TrimSpace(c.Query("block_section"))
+	if blockSection == "" {
+		return c.Status(fiber.StatusBadRequest).SendString("block_section is required")
+	}
+
+	var window config.ExamWindow
+	var ok bool
+	if exam := strings.TrimSpace(c.Query("exam")); exam != "" {
+		window, ok = h.config.Exams.Window(exam)
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).SendString(fmt.Sprintf("Unknown exam: %s", exam))
+		}
+	} else {
+		window, ok = h.config.Exams.ActiveWindow(time.Now())
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).SendString("No exam window is in progress, pass exam to choose one")
+		}
+	}
+
+	var termID *int64
+	if value := c.Query("term_id"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid term_id")
+		}
+		termID = &id
+	} else {
+		term, err := h.RFIDRepository.GetCurrentTerm()
+		if err != nil {
+			log.Printf("HandleExamPermits error: %v", err)
+			return c.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+		}
+		if term != nil {
+			termID = &term.ID
+		}
+	}
+
+	standings, err := h.RFIDRepository.GetBlockSectionStandings(blockSection, termID)
+	if err != nil {
+		log.Printf("HandleExamPermits error: %v", err)
+		return c.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	permits := make([]model.ExamPermit, 0, len(standings))
+	cleared := 0
+	for _, standing := range standings {
+		permit := services.EvaluateExamPermit(standing, window, h.config.Exams.Windows)
+		if permit.Cleared {
+			cleared++
+		}
+		permits = append(permits, permit)
+	}
+
+	if c.Query("format") != "csv" {
+		return c.JSON(fiber.Map{
+			"block_section": blockSection,
+			"exam":          window.Name,
+			"exam_start":    window.Start.Format("2006-01-02"),
+			"exam_end":      window.End.Format("2006-01-02"),
+			"cleared":       cleared,
+			"not_cleared":   len(permits) - cleared,
+			"permits":       permits,
+		})
+	}
+
+	c.Set("Content-Type", "text/csv")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=exam-permits-%s-%s.csv", blockSection, strings.ToLower(window.Name)))
+	writer := csv.NewWriter(c)
+	defer writer.Flush()
+	writer.Write([]string{"StudentID", "Name", "BlockSection", "Exam", "Cleared", "AmountDue", "Reason"})
+	for _, p := range permits {
+		writer.Write([]string{
+			p.StudentID,
+			p.StudentName,
+			stringValue(p.BlockSection),
+			p.Exam,
+			strconv.FormatBool(p.Cleared),
+			formatAmount(p.AmountDue),
+			p.Reason,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/repositories"
+	"rfidsystem/internal/services"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleClassRoster handles HTTP requests for the roster of a class and its grades.
+// It expects "subject_code", "term_id" and "block_section" query parameters.
+// Faculty can only view the classes assigned to them.
+func (h *AppHandler) HandleClassRoster(ctx *fiber.Ctx) error {
+	subjectCode := strings.TrimSpace(ctx.Query("subject_code"))
+	blockSection := strings.TrimSpace(ctx.Query("block_section"))
+	termID := int64(ctx.QueryInt("term_id"))
+	if subjectCode == "" || blockSection == "" || termID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Subject code, term ID and block section are required")
+	}
+	if allowed, err := h.canEncodeClass(ctx, subjectCode, termID, blockSection); !allowed {
+		return err
+	}
+
+	roster, err := h.RFIDRepository.GetClassRoster(subjectCode, termID, blockSection)
+	if err != nil {
+		log.Printf("Error retrieving roster of %s %s term %d: %v", subjectCode, blockSection, termID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if roster == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Academic term not found")
+	}
+	return ctx.JSON(roster)
+}
+
+// HandleSubmitGrades handles HTTP requests to encode the grades of one grading
+// period for a class. It expects "subject_code", "term_id", "block_section",
+// "period" (prelim, midterm, prefinal or final) and "grades", a list of
+// "student_id" and "grade" pairs where a null grade clears it, in the JSON body.
+// A grade may carry a "remark" of INC or DRP ("" clears it); a remark with a
+// null grade leaves the period grade unchanged.
+// Final grades are recomputed from the configured period weights. The whole
+// submission is rejected if any grade is out of range or the term is locked.
+func (h *AppHandler) HandleSubmitGrades(ctx *fiber.Ctx) error {
+	var req struct {
+		SubjectCode  string `json:"subject_code"`
+		TermID       int64  `json:"term_id"`
+		BlockSection string `json:"block_section"`
+		Period       string `json:"period"`
+		Grades       []struct {
+			StudentID string   `json:"student_id"`
+			Grade     *float64 `json:"grade"`
+			Remark    *string  `json:"remark"`
+		} `json:"grades"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	req.SubjectCode = strings.TrimSpace(req.SubjectCode)
+	req.BlockSection = strings.TrimSpace(req.BlockSection)
+	req.Period = strings.ToLower(strings.TrimSpace(req.Period))
+	if req.SubjectCode == "" || req.BlockSection == "" || req.TermID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Subject code, term ID and block section are required")
+	}
+	if !isGradingPeriod(req.Period) {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Period must be one of " + strings.Join(model.GradingPeriods, ", "))
+	}
+	if len(req.Grades) == 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("At least one grade is required")
+	}
+	if allowed, err := h.canEncodeClass(ctx, req.SubjectCode, req.TermID, req.BlockSection); !allowed {
+		return err
+	}
+
+	submittedBy, _ := ctx.Locals("userEmail").(string)
+	sub := model.GradeSubmission{
+		SubjectCode:  req.SubjectCode,
+		TermID:       req.TermID,
+		BlockSection: req.BlockSection,
+		Period:       req.Period,
+		Grades:       make(map[string]*float64, len(req.Grades)),
+		Remarks:      make(map[string]*string),
+		SubmittedBy:  submittedBy,
+	}
+	for _, g := range req.Grades {
+		studentID := strings.TrimSpace(g.StudentID)
+		if studentID == "" {
+			return ctx.Status(fiber.StatusBadRequest).SendString("Student ID is required for every grade")
+		}
+		if g.Grade != nil {
+			if err := services.ValidateGrade(*g.Grade, h.config.Grading); err != nil {
+				return ctx.Status(fiber.StatusUnprocessableEntity).SendString(fmt.Sprintf("Student %s: %v", studentID, err))
+			}
+		}
+		if g.Remark == nil {
+			sub.Grades[studentID] = g.Grade
+			continue
+		}
+		switch remark := strings.ToUpper(strings.TrimSpace(*g.Remark)); remark {
+		case "":
+			sub.Remarks[studentID] = nil
+		case model.RemarkIncomplete, model.RemarkDropped:
+			sub.Remarks[studentID] = &remark
+		default:
+			return ctx.Status(fiber.StatusUnprocessableEntity).SendString(fmt.Sprintf("Student %s: remark must be %s or %s", studentID, model.RemarkIncomplete, model.RemarkDropped))
+		}
+		if g.Grade != nil {
+			sub.Grades[studentID] = g.Grade
+		}
+	}
+
+	weights := h.config.Grading.Weights
+	roster, err := h.RFIDRepository.SubmitGrades(sub, func(entry model.RosterEntry) *float64 {
+		return services.ComputeFinalGrade(entry, weights)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrTermNotFound):
+			return ctx.Status(fiber.StatusNotFound).SendString(err.Error())
+		case errors.Is(err, repositories.ErrGradesLocked):
+			return ctx.Status(fiber.StatusLocked).SendString(err.Error())
+		case errors.Is(err, repositories.ErrNotInRoster):
+			return ctx.Status(fiber.StatusUnprocessableEntity).SendString(err.Error())
+		}
+		log.Printf("Error submitting %s grades of %s %s term %d: %v", req.Period, req.SubjectCode, req.BlockSection, req.TermID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	for _, studentID := range sub.StudentIDs() {
+		invalidateStudent(studentID)
+	}
+	_ = h.db.LogScanEvent("", nil, "grades_submitted",
+		fmt.Sprintf("%d %s grades of %s %s (term %d) submitted by %s", len(sub.StudentIDs()), sub.Period, sub.SubjectCode, sub.BlockSection, sub.TermID, submittedBy),
+		"", "success")
+	return ctx.JSON(roster)
+}
+
+// HandleFacultyClasses handles HTTP requests for the classes assigned to the signed-in faculty account.
+func (h *AppHandler) HandleFacultyClasses(ctx *fiber.Ctx) error {
+	email, _ := ctx.Locals("userEmail").(string)
+	assignments, err := h.RFIDRepository.GetFacultyAssignments(email)
+	if err != nil {
+		log.Printf("Error listing classes of %s: %v", email, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(assignments)
+}
+
+// HandleAssignFaculty handles HTTP requests to assign a class to a faculty account.
+// It expects "email", "subject_code", "term_id" and "block_section" in the request body.
+func (h *AppHandler) HandleAssignFaculty(ctx *fiber.Ctx) error {
+	var req struct {
+		Email        string `json:"email" form:"email"`
+		SubjectCode  string `json:"subject_code" form:"subject_code"`
+		TermID       int64  `json:"term_id" form:"term_id"`
+		BlockSection string `json:"block_section" form:"block_section"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	assignment := &model.FacultyAssignment{
+		Email:        strings.TrimSpace(req.Email),
+		SubjectCode:  strings.TrimSpace(req.SubjectCode),
+		TermID:       req.TermID,
+		BlockSection: strings.TrimSpace(req.BlockSection),
+	}
+	if assignment.Email == "" || assignment.SubjectCode == "" || assignment.BlockSection == "" || assignment.TermID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Email, subject code, term ID and block section are required")
+	}
+
+	user, err := h.RFIDRepository.GetUserByEmail(assignment.Email)
+	if err != nil {
+		log.Printf("Error retrieving user %s: %v", assignment.Email, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if user == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("User not found")
+	}
+	if user.Role != model.RoleFaculty {
+		return ctx.Status(fiber.StatusUnprocessableEntity).SendString("Classes can only be assigned to faculty accounts")
+	}
+	assignment.UserID = user.ID
+
+	if err := h.RFIDRepository.AssignFaculty(assignment); err != nil {
+		log.Printf("Error assigning %s %s to %s: %v", assignment.SubjectCode, assignment.BlockSection, assignment.Email, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	assignedBy, _ := ctx.Locals("userEmail").(string)
+	_ = h.db.LogScanEvent("", nil, "faculty_assigned",
+		fmt.Sprintf("%s %s (term %d) assigned to %s by %s", assignment.SubjectCode, assignment.BlockSection, assignment.TermID, assignment.Email, assignedBy), "", "info")
+	return ctx.Status(fiber.StatusCreated).JSON(assignment)
+}
+
+// HandleLockTermGrades handles HTTP requests to lock the grades of the term in the
+// ":id" path parameter, so that no more grades can be submitted for it.
+func (h *AppHandler) HandleLockTermGrades(ctx *fiber.Ctx) error {
+	return h.setTermGradesLocked(ctx, true)
+}
+
+// HandleUnlockTermGrades handles HTTP requests to unlock the grades of the term in
+// the ":id" path parameter. Grades stay locked while the term's deadline is past.
+func (h *AppHandler) HandleUnlockTermGrades(ctx *fiber.Ctx) error {
+	return h.setTermGradesLocked(ctx, false)
+}
+
+// HandleSetGradeDeadline handles HTTP requests to set the last day grades can be
+// submitted for the term in the ":id" path parameter. It expects "deadline" in
+// YYYY-MM-DD in the request body; an empty deadline removes it.
+func (h *AppHandler) HandleSetGradeDeadline(ctx *fiber.Ctx) error {
+	termID, err := ctx.ParamsInt("id")
+	if err != nil || termID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid term ID")
+	}
+	var req struct {
+		Deadline string `json:"deadline" form:"deadline"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	var deadline *time.Time
+	if value := strings.TrimSpace(req.Deadline); value != "" {
+		day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).SendString("Deadline must be in YYYY-MM-DD format")
+		}
+		deadline = &day
+	}
+
+	found, err := h.RFIDRepository.SetGradeDeadline(int64(termID), deadline)
+	if err != nil {
+		log.Printf("Error setting grade deadline of term %d: %v", termID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if !found {
+		return ctx.Status(fiber.StatusNotFound).SendString("Academic term not found")
+	}
+	setBy, _ := ctx.Locals("userEmail").(string)
+	_ = h.db.LogScanEvent("", nil, "grade_deadline_set",
+		fmt.Sprintf("Grade deadline of term %d set to %q by %s", termID, strings.TrimSpace(req.Deadline), setBy), "", "info")
+	return ctx.JSON(fiber.Map{"term_id": termID, "grade_deadline": strings.TrimSpace(req.Deadline)})
+}
+
+func (h *AppHandler) setTermGradesLocked(ctx *fiber.Ctx, locked bool) error {
+	termID, err := ctx.ParamsInt("id")
+	if err != nil || termID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid term ID")
+	}
+	found, err := h.RFIDRepository.SetTermGradesLocked(int64(termID), locked)
+	if err != nil {
+		log.Printf("Error changing grade lock of term %d: %v", termID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if !found {
+		return ctx.Status(fiber.StatusNotFound).SendString("Academic term not found")
+	}
+	action := "unlocked"
+	if locked {
+		action = "locked"
+	}
+	changedBy, _ := ctx.Locals("userEmail").(string)
+	_ = h.db.LogScanEvent("", nil, "grades_"+action, fmt.Sprintf("Grades of term %d %s by %s", termID, action, changedBy), "", "info")
+	return ctx.JSON(fiber.Map{"term_id": termID, "locked": locked})
+}
+
+// canEncodeClass reports whether the signed-in user may encode grades for the class.
+// Accounts that manage grades may encode any class; faculty only the classes
+// assigned to them. When it returns false, the error response has been written.
+func (h *AppHandler) canEncodeClass(ctx *fiber.Ctx, subjectCode string, termID int64, blockSection string) (bool, error) {
+	role, _ := ctx.Locals("userRole").(string)
+	if HasPermission(role, PermManageGrades) {
+		return true, nil
+	}
+	email, _ := ctx.Locals("userEmail").(string)
+	assigned, err := h.RFIDRepository.IsFacultyAssigned(email, subjectCode, termID, blockSection)
+	if err != nil {
+		log.Printf("Error checking class assignment of %s: %v", email, err)
+		return false, ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if !assigned {
+		return false, ctx.Status(fiber.StatusForbidden).SendString("This class is not assigned to you")
+	}
+	return true, nil
+}
+
+func isGradingPeriod(period string) bool {
+	for _, p := range model.GradingPeriods {
+		if p == period {
+			return true
+		}
+	}
+	return false
+}
@@ -4,14 +4,11 @@ import (
 	"fmt"
 	"log"
 	"rfidsystem/internal/model"
-	"time"
+	"rfidsystem/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-var gradesCache = NewLRUCache(5, time.Hour)
-var semesterGradesCache = NewLRUCache(5, time.Hour)
-
 // HandleGrades handles HTTP requests to retrieve and display student grades for the current term.
 // It expects a student ID via form value "rfid" or query parameter "student-id".
 // It checks the cache, fetches data from the repository if not found,
@@ -25,33 +22,13 @@ func (h *AppHandler) HandleGrades(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusBadRequest).SendString("Student Id is required")
 	}
 	// Try cache first
-	if cached, found := gradesCache.Get(studentId); found {
-		gradesData, ok := cached.(*model.Grades)
-		if ok && gradesData != nil {
+	if gradesData, found := gradesCache.Get(studentId); found {
+		if gradesData != nil {
 			log.Printf("[CACHE HIT] Grades for %s", studentId)
-			currentTerm := gradesData.CurrentTerm
-			isSecondSemesterAvailable := currentTerm.Semester != "First Semester"
-			preparedGrades, gwaString := h.prepareGradesAndGWA(gradesData.Grades)
-			return ctx.Render("partials/grades", fiber.Map{
-				"Title":                     "Student Grades",
-				"Student":                   gradesData.Student,
-				"Term":                      gradesData.CurrentTerm,
-				"Grades":                    preparedGrades,
-				"GWA":                       gwaString,
-				"SelectedSemester":          currentTerm.Semester,
-				"IsSecondSemesterAvailable": isSecondSemesterAvailable,
-			})
+			return h.renderGrades(ctx, "partials/grades", gradesData)
 		}
 	}
 
-	// Get current term to determine which semester to show by default
-	currentTerm, err := h.RFIDRepository.GetCurrentTerm()
-	if err != nil {
-		log.Printf("Error getting current term: %v", err)
-		_ = h.db.LogScanEvent(studentId, &studentId, "grade_fetch_error", fmt.Sprintf("Error getting current term: %v", err), "", "failure")
-		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
-	}
-
 	// Get grades data
 	gradesData, err := h.RFIDRepository.GetStudentGradesByRFID(studentId)
 	if err != nil {
@@ -65,138 +42,131 @@ func (h *AppHandler) HandleGrades(ctx *fiber.Ctx) error {
 		return ctx.Status(fiber.StatusNotFound).SendString("Student not found")
 	}
 	// Store in cache
-	gradesCache.Set(studentId, gradesData)
+	gradesCache.Set(studentId, gradesData, studentTag(studentId))
 
-	// Determine if second semester is available
-	isSecondSemesterAvailable := currentTerm.Semester != "First Semester"
-
-	// Process grades and calculate GWA
-	preparedGrades, gwaString := h.prepareGradesAndGWA(gradesData.Grades)
-	_ = h.db.LogScanEvent(studentId, &studentId, "grade_fetch_success", fmt.Sprintf("Fetched %d grades", len(preparedGrades)), "", "success")
-
-	return ctx.Render("partials/grades", fiber.Map{
-		"Title":                     "Student Grades",
-		"Student":                   gradesData.Student,
-		"Term":                      gradesData.CurrentTerm,
-		"Grades":                    preparedGrades,
-		"GWA":                       gwaString,
-		"SelectedSemester":          currentTerm.Semester,
-		"IsSecondSemesterAvailable": isSecondSemesterAvailable,
-	})
+	_ = h.db.LogScanEvent(studentId, &studentId, "grade_fetch_success", fmt.Sprintf("Fetched %d grades", len(gradesData.Grades)), "", "success")
+	return h.renderGrades(ctx, "partials/grades", gradesData)
 }
 
-// HandleSemesterGrades handles HTMX requests to retrieve and display student grades for a specific semester.
-// It expects the student ID as a path parameter and the semester as a query parameter.
+// HandleSemesterGrades handles HTMX requests to retrieve and display student grades for another term.
+// It expects the student ID as a path parameter and the "term_id" query parameter,
+// one of the terms listed in the grades partial.
 // It checks the cache, fetches data from the repository if not found,
 // and renders the grades table partial.
 func (h *AppHandler) HandleSemesterGrades(ctx *fiber.Ctx) error {
 	studentId := ctx.Params("studentId")
-	semester := ctx.Query("semester")
-	if studentId == "" || semester == "" {
-		return ctx.Status(fiber.StatusBadRequest).SendString("Student ID and semester are required")
-	}
-	cacheKey := studentId + ":" + semester
-	if cached, found := semesterGradesCache.Get(cacheKey); found {
-		gradesData, ok := cached.(*model.Grades)
-		if ok && gradesData != nil {
-			log.Printf("[CACHE HIT] Semester grades for %s %s", studentId, semester)
-			currentTerm := gradesData.CurrentTerm
-			isSecondSemesterAvailable := currentTerm.Semester != "First Semester"
-			preparedGrades, gwaString := h.prepareGradesAndGWA(gradesData.Grades)
-			return ctx.Render("partials/grades-table", fiber.Map{
-				"Student":                   gradesData.Student,
-				"Term":                      gradesData.CurrentTerm,
-				"Grades":                    preparedGrades,
-				"GWA":                       gwaString,
-				"SelectedSemester":          semester,
-				"IsSecondSemesterAvailable": isSecondSemesterAvailable,
-			})
+	termID := int64(ctx.QueryInt("term_id"))
+	if studentId == "" || termID <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Student ID and term ID are required")
+	}
+	cacheKey := termGradesCacheKey(studentId, termID)
+	if gradesData, found := semesterGradesCache.Get(cacheKey); found {
+		if gradesData != nil {
+			log.Printf("[CACHE HIT] Term grades for %s term %d", studentId, termID)
+			return h.renderGrades(ctx, "partials/grades-table", gradesData)
 		}
 	}
 
-	// Get current term to get the academic year and check semester availability
-	currentTerm, err := h.RFIDRepository.GetCurrentTerm()
+	// Get grades for the requested term
+	gradesData, err := h.RFIDRepository.GetStudentGradesByTerm(studentId, termID)
 	if err != nil {
-		log.Printf("Error getting current term: %v", err)
-		_ = h.db.LogScanEvent(studentId, &studentId, "grade_fetch_error", fmt.Sprintf("Error getting current term: %v", err), "", "failure")
+		log.Printf("Error fetching grades for student %s term %d: %v", studentId, termID, err)
+		_ = h.db.LogScanEvent(studentId, &studentId, "grade_fetch_error", fmt.Sprintf("Error fetching grades for student %s term %d: %v", studentId, termID, err), "", "failure")
 		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
 	}
+	if gradesData == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Student or term not found")
+	}
+	// Store in cache
+	semesterGradesCache.Set(cacheKey, gradesData, studentTag(studentId))
+
+	_ = h.db.LogScanEvent(studentId, &studentId, "grade_fetch_success", fmt.Sprintf("Fetched %d grades", len(gradesData.Grades)), "", "success")
+	// Render only the grades table container
+	return h.renderGrades(ctx, "partials/grades-table", gradesData)
+}
 
-	// Get grades for the requested semester
-	gradesData, err := h.RFIDRepository.GetStudentGradesByRFIDAndSemester(studentId, currentTerm.AcademicYear, semester)
+// GetStudentTerms handles HTTP requests for the academic terms a student has
+// records in, as JSON. It expects the student ID as a path parameter.
+func (h *AppHandler) GetStudentTerms(ctx *fiber.Ctx) error {
+	studentID := ctx.Params("id")
+	terms, err := h.RFIDRepository.GetStudentTerms(studentID)
 	if err != nil {
-		log.Printf("Error fetching grades for student %s semester %s: %v", studentId, semester, err)
-		_ = h.db.LogScanEvent(studentId, &studentId, "grade_fetch_error", fmt.Sprintf("Error fetching grades for student %s semester %s: %v", studentId, semester, err), "", "failure")
+		log.Printf("Error listing terms of student %s: %v", studentID, err)
 		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
 	}
-	// Store in cache
-	semesterGradesCache.Set(cacheKey, gradesData)
-
-	// Determine if second semester is available
-	isSecondSemesterAvailable := currentTerm.Semester != "First Semester"
+	return ctx.JSON(terms)
+}
 
-	// Process grades and calculate GWA
-	preparedGrades, gwaString := h.prepareGradesAndGWA(gradesData.Grades)
-	_ = h.db.LogScanEvent(studentId, &studentId, "grade_fetch_success", fmt.Sprintf("Fetched %d grades", len(preparedGrades)), "", "success")
+// GetGrades handles HTTP requests for a student's grades as JSON. It expects the
+// student ID as a path parameter and an optional "term_id" query parameter; the
+// current term is used without one.
+func (h *AppHandler) GetGrades(ctx *fiber.Ctx) error {
+	studentID := ctx.Params("id")
+	var grades *model.Grades
+	var err error
+	if termID := int64(ctx.QueryInt("term_id")); termID > 0 {
+		grades, err = h.RFIDRepository.GetStudentGradesByTerm(studentID, termID)
+	} else {
+		grades, err = h.RFIDRepository.GetStudentGradesByRFID(studentID)
+	}
+	if err != nil {
+		log.Printf("Error retrieving grades for student %s: %v", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if grades == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Student or term not found")
+	}
+	return ctx.JSON(grades)
+}
 
-	// Render only the grades table container
-	return ctx.Render("partials/grades-table", fiber.Map{
-		"Student":                   gradesData.Student,
-		"Term":                      gradesData.CurrentTerm,
-		"Grades":                    preparedGrades,
-		"GWA":                       gwaString,
-		"SelectedSemester":          semester,
-		"IsSecondSemesterAvailable": isSecondSemesterAvailable,
+// renderGrades renders a grades template for the term of gradesData, with a
+// selector for every term the student has records in. The term is nil when the
+// student has no records and no term is in session.
+func (h *AppHandler) renderGrades(ctx *fiber.Ctx, template string, gradesData *model.Grades) error {
+	preparedGrades, gwaString, honors := h.prepareGradesAndGWA(gradesData.Grades)
+	var selectedTermID int64
+	if gradesData.CurrentTerm != nil {
+		selectedTermID = gradesData.CurrentTerm.ID
+	}
+	return ctx.Render(template, fiber.Map{
+		"Title":          "Student Grades",
+		"Student":        gradesData.Student,
+		"Term":           gradesData.CurrentTerm,
+		"Terms":          gradesData.Terms,
+		"Grades":         preparedGrades,
+		"GWA":            gwaString,
+		"Honors":         honors,
+		"SelectedTermID": selectedTermID,
 	})
 }
 
-// prepareGradesAndGWA processes grades and calculates GWA
-func (h *AppHandler) prepareGradesAndGWA(grades []model.GradesRecord) ([]fiber.Map, string) {
-	var preparedGrades []fiber.Map
-	var totalGrade float64
-	var countGrades int
+// termGradesCacheKey returns the semesterGradesCache key of a student's grades for a term.
+func termGradesCacheKey(studentID string, termID int64) string {
+	return fmt.Sprintf("%s:%d", studentID, termID)
+}
 
-	// Prepare grades with safe handling of nil values
+// prepareGradesAndGWA formats grades for display with their remarks and computes
+// their units-weighted GWA and Latin honor with the configured grading scale.
+func (h *AppHandler) prepareGradesAndGWA(grades []model.GradesRecord) ([]fiber.Map, string, string) {
+	var preparedGrades []fiber.Map
 	for _, grade := range grades {
-		gradeMap := fiber.Map{
+		preparedGrades = append(preparedGrades, fiber.Map{
 			"SubjectCode":    grade.SubjectCode,
 			"SubjectName":    grade.SubjectName,
-			"PrelimGrade":    formatGrade(grade.PrelimGrade),
-			"MidtermGrade":   formatGrade(grade.MidtermGrade),
-			"PrefinalGrade":  formatGrade(grade.PrefinalGrade),
-			"FinalTermGrade": formatGrade(grade.FinalTermGrade),
-			"FinalGrade":     formatGrade(grade.FinalGrade),
-		}
-		preparedGrades = append(preparedGrades, gradeMap)
-
-		// Calculate running total for GWA
-		if grade.FinalGrade != nil {
-			totalGrade += *grade.FinalGrade
-			countGrades++
-		}
+			"Units":          fmt.Sprintf("%g", grade.Units),
+			"PrelimGrade":    services.FormatGrade(grade.PrelimGrade),
+			"MidtermGrade":   services.FormatGrade(grade.MidtermGrade),
+			"PrefinalGrade":  services.FormatGrade(grade.PrefinalGrade),
+			"FinalTermGrade": services.FormatGrade(grade.FinalTermGrade),
+			"FinalGrade":     services.FormatGrade(grade.FinalGrade),
+			"Remark":         services.GradeRemark(grade, h.config.Grading),
+		})
 	}
 
-	// Calculate GWA
-	var gwaString string
-	if countGrades > 0 {
-		gwa := totalGrade / float64(countGrades)
-		gwaString = fmt.Sprintf("%.2f", gwa)
-	} else {
-		gwaString = "N/A"
+	summary := services.SummarizeGrades(grades, h.config.Grading)
+	gwaString := "N/A"
+	if summary.GWA != nil {
+		gwaString = services.FormatGrade(summary.GWA)
 	}
-
-	return preparedGrades, gwaString
-}
-
-// formatGrade safely handles nil grade values and formats non-nil grades
-// Returns:
-//   - "-" when the grade is nil (grade not yet available/recorded)
-//   - formatted string with 2 decimal places for actual grade values
-func formatGrade(grade *float64) string {
-	if grade == nil || *grade == 0 {
-		// Return a dash placeholder for nil grades
-		// This indicates that the grade is not yet available or has not been recorded
-		return "-"
-	}
-	return fmt.Sprintf("%.2f", *grade)
+	return preparedGrades, gwaString, summary.Honors
 }
@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"rfidsystem/internal/config"
 	"rfidsystem/internal/repositories"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,12 +11,32 @@ import (
 type AppHandler struct {
 	db             *repositories.DatabaseClient
 	RFIDRepository *repositories.RFIDRepository
+	config         config.AppConfig
 }
 
-func NewHandler(db *repositories.DatabaseClient, rfidRepo *repositories.RFIDRepository) *AppHandler {
-	return &AppHandler{db: db, RFIDRepository: rfidRepo}
+func NewHandler(db *repositories.DatabaseClient, rfidRepo *repositories.RFIDRepository, cfg config.AppConfig) *AppHandler {
+	sessions = NewSessionStore(rfidRepo)
+	configureCaches(cfg.Cache)
+	return &AppHandler{db: db, RFIDRepository: rfidRepo, config: cfg}
 }
 
+// HandleGetIndex renders the kiosk home page. A kiosk screen is opened once with
+// "?kiosk=<kiosk_id>"; the ID is remembered in a cookie so later navigation back
+// to "/" keeps the screen subscribed to its own SSE channel.
 func (h *AppHandler) HandleGetIndex(ctx *fiber.Ctx) error {
-	return ctx.Render("pages/home", fiber.Map{})
+	kioskID := ctx.Query("kiosk")
+	if kioskID != "" {
+		ctx.Cookie(&fiber.Cookie{
+			Name:     "kiosk_id",
+			Value:    kioskID,
+			Expires:  time.Now().AddDate(1, 0, 0),
+			HTTPOnly: true,
+			SameSite: "Lax",
+		})
+	} else {
+		kioskID = ctx.Cookies("kiosk_id")
+	}
+	return ctx.Render("pages/home", fiber.Map{
+		"KioskID": kioskID,
+	})
 }
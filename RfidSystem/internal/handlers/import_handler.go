@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// importPermissions maps each kind of import to the permission needed to run it.
+var importPermissions = map[string]string{
+	model.ImportStudents:    PermManageStudents,
+	model.ImportEnrollments: PermManageStudents,
+	model.ImportCards:       PermManageCards,
+	model.ImportPayments:    PermRecordPayments,
+}
+
+// HandleImport handles HTTP requests to import a CSV or XLSX file of students,
+// enrollments, card assignments or payments, named by the ":type" path parameter.
+// It expects the file in the "file" form field and, optionally, "dry_run". Every row
+// is checked first; a dry run only returns the report of row errors, and a file with
+// any error is rejected with 422 and nothing imported. Otherwise every row is
+// imported in a single transaction and recorded in the import history.
+func (h *AppHandler) HandleImport(ctx *fiber.Ctx) error {
+	importType := ctx.Params("type")
+	perm, ok := importPermissions[importType]
+	if !ok {
+		return ctx.Status(fiber.StatusNotFound).SendString("Unknown import type")
+	}
+	role, _ := ctx.Locals("userRole").(string)
+	if !HasPermission(role, perm) {
+		email, _ := ctx.Locals("userEmail").(string)
+		log.Printf("Permission %s denied for %s (role %q) on %s %s", perm, email, role, ctx.Method(), ctx.Path())
+		return ctx.Status(fiber.StatusForbidden).SendString("You do not have permission to access this resource")
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("A CSV or XLSX file is required")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Printf("Error opening uploaded import file %s: %v", fileHeader.Filename, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.Printf("Error reading uploaded import file %s: %v", fileHeader.Filename, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	table, err := services.ReadImportFile(fileHeader.Filename, data)
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+	if missing := services.MissingImportColumns(importType, table); len(missing) > 0 {
+		return ctx.Status(fiber.StatusUnprocessableEntity).SendString(fmt.Sprintf("Missing columns: %s", strings.Join(missing, ", ")))
+	}
+	if len(table.Rows) == 0 {
+		return ctx.Status(fiber.StatusUnprocessableEntity).SendString("The file has no rows to import")
+	}
+
+	dryRun := ctx.FormValue("dry_run") == "true" || ctx.FormValue("dry_run") == "1"
+	importedBy, _ := ctx.Locals("userEmail").(string)
+	report, err := h.RFIDRepository.ImportRows(importType, fileHeader.Filename, table.Rows, dryRun, importedBy)
+	if err != nil {
+		log.Printf("Error importing %s from %s: %v", importType, fileHeader.Filename, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if dryRun {
+		return ctx.JSON(report)
+	}
+	if len(report.Errors) > 0 {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(report)
+	}
+
+	// An import can touch any number of students, so drop every cached kiosk view
+	caches.Clear()
+	_ = h.db.LogScanEvent("", nil, "import_committed",
+		fmt.Sprintf("Imported %d %s rows from %s by %s", report.TotalRows, importType, fileHeader.Filename, importedBy),
+		fmt.Sprintf(`{"import_id": %d}`, report.ImportID), "success")
+	return ctx.Status(fiber.StatusCreated).JSON(report)
+}
+
+// HandleListImports handles HTTP requests for the import history, limited to the
+// kinds of import the signed-in account may run.
+func (h *AppHandler) HandleListImports(ctx *fiber.Ctx) error {
+	role, _ := ctx.Locals("userRole").(string)
+	var importTypes []string
+	for importType, perm := range importPermissions {
+		if HasPermission(role, perm) {
+			importTypes = append(importTypes, importType)
+		}
+	}
+	records, err := h.RFIDRepository.ListImports(importTypes)
+	if err != nil {
+		log.Printf("Error listing import history: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(records)
+}
@@ -73,14 +73,18 @@ func (h *AppHandler) HandleLog(c *fiber.Ctx) error {
 			rate = float64(total) / duration
 		}
 	}
-	userEmail, _ := GetSessionUserEmailFiber(c)
+	userEmail, _ := c.Locals("userEmail").(string)
+	userRole, _ := c.Locals("userRole").(string)
 	if err := c.Render("pages/log", fiber.Map{
-		"Logs":      logs,
-		"TotalLogs": total,
-		"ErrorLogs": errorCount,
-		"WarnLogs":  warnCount,
-		"LogRate":   rate,
-		"UserEmail": userEmail,
+		"Logs":          logs,
+		"TotalLogs":     total,
+		"ErrorLogs":     errorCount,
+		"WarnLogs":      warnCount,
+		"LogRate":       rate,
+		"UserEmail":     userEmail,
+		"UserRole":      userRole,
+		"CanClearLogs":  HasPermission(userRole, PermClearLogs),
+		"CanExportLogs": HasPermission(userRole, PermExportLogs),
 	}); err != nil {
 		log.Printf("HandleLog Render error: %v", err)
 		return c.Status(fiber.StatusInternalServerError).
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/repositories"
+	"rfidsystem/internal/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HandleRecordPayment handles HTTP requests to record a payment against an assessment.
+// It expects the assessment number as a path parameter and "amount", "payment_method"
+// and optionally "reference_number" and "description" in the request body. The
+// assessment totals and payment schedule are updated in the same transaction, and
+// the student's cached bills are dropped so the kiosk shows the new balance.
+// The response includes the official receipt issued for the payment.
+func (h *AppHandler) HandleRecordPayment(ctx *fiber.Ctx) error {
+	assessmentNumber, err := ctx.ParamsInt("id")
+	if err != nil || assessmentNumber <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid assessment number")
+	}
+
+	var req struct {
+		Amount          float64 `json:"amount" form:"amount"`
+		PaymentMethod   string  `json:"payment_method" form:"payment_method"`
+		ReferenceNumber string  `json:"reference_number" form:"reference_number"`
+		Description     string  `json:"description" form:"description"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	req.PaymentMethod = strings.TrimSpace(req.PaymentMethod)
+	if req.Amount <= 0 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Amount must be greater than zero")
+	}
+	if req.PaymentMethod == "" {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Payment method is required")
+	}
+
+	recordedBy, _ := ctx.Locals("userEmail").(string)
+	payment := model.NewPayment{
+		AssessmentNumber: int64(assessmentNumber),
+		Amount:           req.Amount,
+		PaymentMethod:    req.PaymentMethod,
+		RecordedBy:       recordedBy,
+	}
+	if reference := strings.TrimSpace(req.ReferenceNumber); reference != "" {
+		payment.ReferenceNumber = &reference
+	}
+	if description := strings.TrimSpace(req.Description); description != "" {
+		payment.Description = &description
+	}
+
+	recorded, err := h.RFIDRepository.RecordPayment(payment)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentExceedsBalance) || errors.Is(err, repositories.ErrDuplicatePaymentReference) {
+			return ctx.Status(fiber.StatusConflict).SendString(err.Error())
+		}
+		if errors.Is(err, repositories.ErrReceiptKeyMissing) {
+			log.Printf("Cannot record payment for assessment %d: RECEIPT_SIGNING_KEY is not set", assessmentNumber)
+			return ctx.Status(fiber.StatusServiceUnavailable).SendString(err.Error())
+		}
+		log.Printf("Error recording payment for assessment %d: %v", assessmentNumber, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if recorded == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Assessment not found")
+	}
+
+	var studentID *string
+	if recorded.Assessment.StudentID != nil {
+		studentID = recorded.Assessment.StudentID
+		invalidateStudent(*studentID)
+	}
+	_ = h.db.LogScanEvent("", studentID, "payment_recorded",
+		fmt.Sprintf("Payment of %s recorded against assessment %d by %s", formatAmount(recorded.Payment.Amount), assessmentNumber, recordedBy),
+		fmt.Sprintf(`{"payment_id": %d, "remaining_balance": %s}`, recorded.Payment.ID, formatAmount(recorded.Assessment.RemainingBalance)), "success")
+
+	return ctx.Status(fiber.StatusCreated).JSON(recorded)
+}
+
+// HandleGetReceipt handles HTTP requests to look up an official receipt by its OR number.
+// The "valid" field reports whether the stored receipt still matches its verification hash.
+func (h *AppHandler) HandleGetReceipt(ctx *fiber.Ctx) error {
+	receipt, err := h.receiptFromParams(ctx)
+	if err != nil || receipt == nil {
+		return err
+	}
+	return ctx.JSON(fiber.Map{
+		"receipt": receipt,
+		"valid":   receipt.ComputeHash(h.config.Billing.ReceiptKey) == receipt.ContentHash,
+	})
+}
+
+// HandleReceiptPDF handles HTTP requests to download or reprint an official receipt as a PDF.
+// It expects the OR number as a path parameter. Receipts whose stored content no longer
+// matches their verification hash are refused.
+func (h *AppHandler) HandleReceiptPDF(ctx *fiber.Ctx) error {
+	receipt, err := h.receiptFromParams(ctx)
+	if err != nil || receipt == nil {
+		return err
+	}
+	if receipt.ComputeHash(h.config.Billing.ReceiptKey) != receipt.ContentHash {
+		log.Printf("Receipt %s failed verification", receipt.FormattedNumber())
+		_ = h.db.LogScanEvent("", &receipt.StudentID, "receipt_tampered", fmt.Sprintf("Receipt %s does not match its verification hash", receipt.FormattedNumber()), "", "failure")
+		return ctx.Status(fiber.StatusConflict).SendString("Receipt failed verification")
+	}
+
+	var buf bytes.Buffer
+	if err := services.RenderReceiptPDF(&buf, receipt, h.config.Billing); err != nil {
+		log.Printf("Error rendering receipt %s: %v", receipt.FormattedNumber(), err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	printedBy, _ := ctx.Locals("userEmail").(string)
+	_ = h.db.LogScanEvent("", &receipt.StudentID, "receipt_printed", fmt.Sprintf("Receipt %s printed by %s", receipt.FormattedNumber(), printedBy), "", "info")
+
+	ctx.Set("Content-Type", "application/pdf")
+	ctx.Set("Content-Disposition", fmt.Sprintf("inline; filename=%s.pdf", receipt.FormattedNumber()))
+	return ctx.Send(buf.Bytes())
+}
+
+// receiptFromParams loads the receipt named by the ":number" path parameter.
+// It writes the error response and returns a nil receipt if it cannot be loaded.
+func (h *AppHandler) receiptFromParams(ctx *fiber.Ctx) (*model.OfficialReceipt, error) {
+	number, err := ctx.ParamsInt("number")
+	if err != nil || number <= 0 {
+		return nil, ctx.Status(fiber.StatusBadRequest).SendString("Invalid receipt number")
+	}
+	receipt, err := h.RFIDRepository.GetReceipt(int64(number))
+	if err != nil {
+		log.Printf("Error retrieving receipt %d: %v", number, err)
+		return nil, ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if receipt == nil {
+		return nil, ctx.Status(fiber.StatusNotFound).SendString("Receipt not found")
+	}
+	return receipt, nil
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"log"
+	"rfidsystem/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Permissions checked by RequirePermission.
+const (
+	PermViewLogs          = "logs:view"
+	PermExportLogs        = "logs:export"
+	PermClearLogs         = "logs:clear"
+	PermViewStudents      = "students:view"
+	PermManageStudents    = "students:manage"
+	PermViewGrades        = "grades:view"
+	PermEncodeGrades      = "grades:encode"
+	PermManageGrades      = "grades:manage"
+	PermManageTerms       = "terms:manage"
+	PermViewBilling       = "billing:view"
+	PermRecordPayments    = "payments:record"
+	PermManageDiscounts   = "discounts:manage"
+	PermManageAssessments = "assessments:manage"
+	PermPrintReceipts     = "receipts:print"
+	PermManageCards       = "cards:manage"
+	PermManageDevices     = "devices:manage"
+	PermViewAttendance    = "attendance:view"
+	PermViewPermits       = "permits:view"
+	PermScanCards         = "cards:scan"
+	PermViewCacheStats    = "cache:view"
+)
+
+// rolePermissions lists what each account role is allowed to do.
+// Super-admins are allowed everything and are not listed here.
+var rolePermissions = map[string]map[string]bool{
+	model.RoleRegistrar: {
+		PermViewLogs:       true,
+		PermExportLogs:     true,
+		PermViewStudents:   true,
+		PermManageStudents: true,
+		PermViewGrades:     true,
+		PermEncodeGrades:   true,
+		PermManageGrades:   true,
+		PermManageTerms:    true,
+		PermManageCards:    true,
+		PermViewAttendance: true,
+		PermViewPermits:    true,
+	},
+	model.RoleCashier: {
+		PermViewLogs:          true,
+		PermViewStudents:      true,
+		PermViewBilling:       true,
+		PermRecordPayments:    true,
+		PermManageDiscounts:   true,
+		PermManageAssessments: true,
+		PermPrintReceipts:     true,
+		PermViewPermits:       true,
+	},
+	model.RoleFaculty: {
+		PermEncodeGrades: true,
+	},
+	model.RoleKiosk: {
+		PermScanCards: true,
+	},
+}
+
+// HasPermission reports whether an account with the given role may perform perm.
+func HasPermission(role, perm string) bool {
+	if role == model.RoleSuperAdmin {
+		return true
+	}
+	return rolePermissions[role][perm]
+}
+
+// RequirePermission is a middleware that only lets through users whose role
+// grants perm. It must run after AuthRequired, which stores the role in the
+// request locals.
+func RequirePermission(perm string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, _ := c.Locals("userRole").(string)
+		if HasPermission(role, perm) {
+			return c.Next()
+		}
+		email, _ := c.Locals("userEmail").(string)
+		log.Printf("Permission %s denied for %s (role %q) on %s %s", perm, email, role, c.Method(), c.Path())
+		return c.Status(fiber.StatusForbidden).SendString("You do not have permission to access this resource")
+	}
+}
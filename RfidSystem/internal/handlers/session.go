@@ -1,105 +1,309 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"log"
 	"net/http"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/repositories"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+const (
+	// sessionIdleTimeout is how long a session stays valid without activity.
+	// Every authenticated request slides the expiry forward by this amount.
+	sessionIdleTimeout = 24 * time.Hour
+	// sessionMaxLifetime caps how long a session can be kept alive by sliding expiry.
+	sessionMaxLifetime = 7 * 24 * time.Hour
+	// sessionTouchInterval limits how often sliding expiry is written to the database.
+	sessionTouchInterval = time.Minute
+)
+
 type session struct {
-	email  string
-	expiry time.Time
+	userID        int64
+	email         string
+	role          string
+	createdAt     time.Time
+	expiry        time.Time
+	lastPersisted time.Time
 }
 
-var sessions = map[string]session{}
+// SessionStore keeps login sessions in MySQL so they survive restarts, with an
+// in-memory cache in front of it. It is safe for concurrent use.
+// Tokens are only ever stored as SHA-256 hashes.
+type SessionStore struct {
+	mu    sync.Mutex
+	cache map[string]*session // keyed by token hash
+	repo  *repositories.RFIDRepository
+}
 
-// CreateSession creates a new session for the given email and returns the session token.
-func CreateSession(email string) string {
-	sessionToken := generateSessionToken()
-	expiry := time.Now().Add(24 * time.Hour)
+var sessions *SessionStore
 
-	sessions[sessionToken] = session{
-		email:  email,
-		expiry: expiry,
+// NewSessionStore creates a SessionStore backed by the given repository and
+// starts a background job that removes expired sessions.
+func NewSessionStore(repo *repositories.RFIDRepository) *SessionStore {
+	store := &SessionStore{
+		cache: make(map[string]*session),
+		repo:  repo,
 	}
-
-	return sessionToken
+	go store.purgeExpired()
+	return store
 }
 
-// DeleteSession removes a session from the sessions map.
-func DeleteSession(sessionToken string) {
-	delete(sessions, sessionToken)
-}
+// Create starts a new session for the user with the given email and returns its token.
+func (s *SessionStore) Create(email string) (string, error) {
+	user, err := s.repo.GetUserByEmail(email)
+	if err != nil {
+		return "", err
+	}
+	if user == nil || !user.IsActive {
+		return "", fmt.Errorf("user not found")
+	}
 
-// IsAuthenticated checks if a user is authenticated based on the session cookie in a standard http.Request.
-// This function is kept for backward compatibility.
-func IsAuthenticated(r *http.Request) bool {
-	cookie, err := r.Cookie("session_token")
+	token, err := generateToken()
 	if err != nil {
-		return false
+		return "", err
+	}
+	now := time.Now()
+	sess := &session{
+		userID:        user.ID,
+		email:         user.Email,
+		role:          user.Role,
+		createdAt:     now,
+		expiry:        now.Add(sessionIdleTimeout),
+		lastPersisted: now,
+	}
+	tokenHash := hashToken(token)
+	if err := s.repo.CreateSession(&model.Session{
+		TokenHash:  tokenHash,
+		UserID:     sess.userID,
+		CreatedAt:  sess.createdAt,
+		LastSeenAt: now,
+		ExpiresAt:  sess.expiry,
+	}); err != nil {
+		return "", err
 	}
 
-	sessionToken := cookie.Value
-	userSession, exists := sessions[sessionToken]
-	if !exists {
-		return false
+	s.mu.Lock()
+	s.cache[tokenHash] = sess
+	s.mu.Unlock()
+	return token, nil
+}
+
+// Lookup returns the session for a token if it exists and has not expired,
+// and slides its expiry forward. The session and its user are read from the
+// database again at least every sessionTouchInterval, so deactivated accounts
+// and role changes take effect on sessions that are already signed in.
+func (s *SessionStore) Lookup(token string) (*session, bool) {
+	if token == "" {
+		return nil, false
 	}
+	tokenHash := hashToken(token)
+	now := time.Now()
 
-	// Check if session has expired
-	if userSession.expiry.Before(time.Now()) {
-		delete(sessions, sessionToken)
-		return false
+	// The database is only queried outside s.mu so that requests do not wait
+	// for each other's round trips
+	s.mu.Lock()
+	cached, wasCached := s.cache[tokenHash]
+	var sess session
+	if wasCached {
+		sess = *cached
+	}
+	s.mu.Unlock()
+
+	if !wasCached || now.Sub(sess.lastPersisted) >= sessionTouchInterval {
+		stored, err := s.repo.GetSession(tokenHash)
+		if err != nil {
+			log.Printf("Session lookup error: %v", err)
+			return nil, false
+		}
+		if stored == nil {
+			s.evict(tokenHash)
+			return nil, false
+		}
+		refreshed := session{
+			userID:        stored.UserID,
+			email:         stored.Email,
+			role:          stored.Role,
+			createdAt:     stored.CreatedAt,
+			expiry:        stored.ExpiresAt,
+			lastPersisted: stored.LastSeenAt,
+		}
+		if wasCached && sess.expiry.After(refreshed.expiry) {
+			refreshed.expiry = sess.expiry
+		}
+		sess = refreshed
+	}
+
+	if sess.expiry.Before(now) {
+		s.evict(tokenHash)
+		if err := s.repo.DeleteSession(tokenHash); err != nil {
+			log.Printf("Failed to delete expired session: %v", err)
+		}
+		return nil, false
 	}
 
-	return true
+	// Sliding expiry, capped by the maximum session lifetime
+	sess.expiry = now.Add(sessionIdleTimeout)
+	if maxExpiry := sess.createdAt.Add(sessionMaxLifetime); sess.expiry.After(maxExpiry) {
+		sess.expiry = maxExpiry
+	}
+	if now.Sub(sess.lastPersisted) >= sessionTouchInterval {
+		if err := s.repo.TouchSession(tokenHash, now, sess.expiry); err != nil {
+			log.Printf("Failed to extend session: %v", err)
+		} else {
+			sess.lastPersisted = now
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.cache[tokenHash]; wasCached && !ok {
+		// Signed out while the session was being checked
+		return nil, false
+	}
+	entry := sess
+	s.cache[tokenHash] = &entry
+	return &sess, true
 }
 
-// IsAuthenticatedFiber checks if a user is authenticated based on the session cookie using a Fiber context.
-func IsAuthenticatedFiber(c *fiber.Ctx) bool {
-	sessionToken := c.Cookies("session_token")
-	if sessionToken == "" {
-		return false
+// evict removes a session from the cache.
+func (s *SessionStore) evict(tokenHash string) {
+	s.mu.Lock()
+	delete(s.cache, tokenHash)
+	s.mu.Unlock()
+}
+
+// Delete removes the session for a token.
+func (s *SessionStore) Delete(token string) {
+	if token == "" {
+		return
 	}
+	tokenHash := hashToken(token)
 
-	userSession, exists := sessions[sessionToken]
-	if !exists {
-		return false
+	s.evict(tokenHash)
+	if err := s.repo.DeleteSession(tokenHash); err != nil {
+		log.Printf("Failed to delete session: %v", err)
 	}
+}
+
+// purgeExpired periodically removes expired sessions from the cache and the database.
+func (s *SessionStore) purgeExpired() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for range ticker.C {
+		now := time.Now()
+		s.mu.Lock()
+		for tokenHash, sess := range s.cache {
+			if sess.expiry.Before(now) {
+				delete(s.cache, tokenHash)
+			}
+		}
+		s.mu.Unlock()
+		if err := s.repo.DeleteExpiredSessions(); err != nil {
+			log.Printf("Failed to purge expired sessions: %v", err)
+		}
+	}
+}
 
-	// Check if session has expired
-	if userSession.expiry.Before(time.Now()) {
-		delete(sessions, sessionToken)
+// CreateSession creates a new session for the given email and returns the session token.
+// It returns an empty token if the session could not be created.
+func CreateSession(email string) string {
+	if sessions == nil {
+		log.Printf("CreateSession called before the session store was initialized")
+		return ""
+	}
+	token, err := sessions.Create(email)
+	if err != nil {
+		log.Printf("Failed to create session for %s: %v", email, err)
+		return ""
+	}
+	return token
+}
+
+// DeleteSession removes a session from the session store.
+func DeleteSession(sessionToken string) {
+	if sessions == nil {
+		return
+	}
+	sessions.Delete(sessionToken)
+}
+
+// IsAuthenticated checks if a user is authenticated based on the session cookie in a standard http.Request.
+// This function is kept for backward compatibility.
+func IsAuthenticated(r *http.Request) bool {
+	cookie, err := r.Cookie("session_token")
+	if err != nil {
 		return false
 	}
+	_, ok := lookupSession(cookie.Value)
+	return ok
+}
 
-	return true
+// IsAuthenticatedFiber checks if a user is authenticated based on the Bearer token
+// or session cookie using a Fiber context.
+func IsAuthenticatedFiber(c *fiber.Ctx) bool {
+	_, ok := lookupRequestSession(c)
+	return ok
 }
 
 // GetSessionUserEmailFiber retrieves the email address of the logged-in user from their session.
 // Returns the email address and true if a valid session exists, empty string and false otherwise.
 func GetSessionUserEmailFiber(c *fiber.Ctx) (string, bool) {
-	sessionToken := c.Cookies("session_token")
-	if sessionToken == "" {
+	userSession, ok := lookupRequestSession(c)
+	if !ok {
 		return "", false
 	}
+	return userSession.email, true
+}
 
-	userSession, exists := sessions[sessionToken]
-	if !exists {
-		return "", false
+// lookupRequestSession returns the session of the token in the "Authorization: Bearer"
+// header, falling back to the session cookie.
+func lookupRequestSession(c *fiber.Ctx) (*session, bool) {
+	if token := bearerToken(c); token != "" {
+		if userSession, ok := lookupSession(token); ok {
+			return userSession, true
+		}
 	}
+	return lookupSession(c.Cookies("session_token"))
+}
 
-	// Check if session has expired
-	if userSession.expiry.Before(time.Now()) {
-		delete(sessions, sessionToken)
-		return "", false
+// bearerToken returns the token of an "Authorization: Bearer <token>" header, if any.
+func bearerToken(c *fiber.Ctx) string {
+	authHeader := c.Get("Authorization")
+	if strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
 	}
+	return ""
+}
 
-	return userSession.email, true
+func lookupSession(token string) (*session, bool) {
+	if sessions == nil {
+		return nil, false
+	}
+	return sessions.Lookup(token)
+}
+
+// generateToken returns a 256-bit cryptographically random token, used for
+// session tokens and reader API keys.
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
-func generateSessionToken() string {
-	// Simple implementation for demonstration
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+// hashToken returns the hex encoded SHA-256 hash under which a token or key is stored.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -12,7 +12,22 @@ import (
 // HandleSSE handles HTTP requests to establish a Server-Sent Events (SSE) connection.
 // It sets the appropriate headers and registers the client with the Broadcaster.
 // It sends an initial "connected" message and periodic "ping" messages.
+// Kiosk screens pass their ID in the "kiosk" query parameter to only receive
+// scans from the readers paired with them. Clients signed in with the log
+// permission also receive admin alerts, such as scans of blocked cards.
 func (h *AppHandler) HandleSSE(c *fiber.Ctx) error {
+	kioskID := c.Query("kiosk")
+	if kioskID != "" {
+		kiosk, err := h.RFIDRepository.GetKiosk(kioskID)
+		if err != nil {
+			log.Printf("HandleSSE kiosk lookup error: %v", err)
+			return c.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+		}
+		if kiosk == nil {
+			return c.Status(fiber.StatusNotFound).SendString("Kiosk not registered")
+		}
+	}
+
 	c.Set("Content-Type", "text/event-stream")
 	c.Set("Cache-Control", "no-cache")
 	c.Set("Connection", "keep-alive")
@@ -23,6 +38,10 @@ func (h *AppHandler) HandleSSE(c *fiber.Ctx) error {
 	client := &Client{
 		messages: make(chan Message, 20),
 		done:     make(chan struct{}),
+		kioskID:  kioskID,
+	}
+	if userSession, ok := lookupRequestSession(c); ok && HasPermission(userSession.role, PermViewLogs) {
+		client.admin = true
 	}
 
 	broadcaster.register <- client
@@ -38,8 +57,8 @@ func (h *AppHandler) HandleSSE(c *fiber.Ctx) error {
 		ticker := time.NewTicker(10 * time.Second)
 		defer ticker.Stop()
 
-		initialMsg := formatSSEMessage("connected", fmt.Sprintf(`{"time": "%s", "status": "connected"}`,
-			time.Now().Format(time.RFC3339)))
+		initialMsg := formatSSEMessage("connected", fmt.Sprintf(`{"time": "%s", "status": "connected", "kiosk": "%s"}`,
+			time.Now().Format(time.RFC3339), kioskID))
 
 		if _, err := w.WriteString(initialMsg); err != nil {
 			return
@@ -4,13 +4,12 @@ import (
 	"fmt"
 	"log"
 	"rfidsystem/internal/model"
+	"rfidsystem/internal/services"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-var studentInfoCache = NewLRUCache(5, time.Hour)
-
 // HandleStudentInfo handles HTTP requests to render the student information partial.
 // It supports receiving the student ID via POST body or GET path parameter.
 // It checks the cache, fetches student summary data from the repository if necessary,
@@ -38,9 +37,8 @@ func (h *AppHandler) HandleStudentInfo(ctx *fiber.Ctx) error {
 	}
 
 	// Try cache first
-	if cached, found := studentInfoCache.Get(studentId); found {
-		studentInfo, ok := cached.(*model.StudentInfoViewModel)
-		if ok && studentInfo != nil && studentInfo.Student != nil {
+	if studentInfo, found := studentInfoCache.Get(studentId); found {
+		if studentInfo != nil && studentInfo.Student != nil {
 			// Update last access timestamp directly
 			now := time.Now()
 			updateQuery := `
@@ -52,21 +50,7 @@ func (h *AppHandler) HandleStudentInfo(ctx *fiber.Ctx) error {
 				log.Printf("Error updating access timestamps for student %s: %v", studentId, err)
 			}
 			// Update last access timestamp even for cached data
-			var formattedSchedules []model.PaymentScheduleViewModel
-			for _, schedule := range studentInfo.PaymentSchedules {
-				if schedule.TermDescription == "Initial Payment" {
-					continue
-				}
-				formattedSchedules = append(formattedSchedules, model.PaymentScheduleViewModel{
-					ID:                      schedule.ID,
-					AssessmentNumber:        schedule.AssessmentNumber,
-					TermDescription:         schedule.TermDescription,
-					DueDate:                 schedule.DueDate,
-					ExpectedAmount:          schedule.ExpectedAmount,
-					ExpectedAmountFormatted: formatAmount(schedule.ExpectedAmount),
-					SortOrder:               schedule.SortOrder,
-				})
-			}
+			formattedSchedules := h.formatPaymentSchedules(studentInfo.Assessment, studentInfo.PaymentSchedules)
 			_ = h.db.LogScanEvent(studentId, &studentInfo.Student.StudentID, "info_displayed", fmt.Sprintf("Displayed cached info for student : %s", *studentInfo.Student.FirstName+" "+*studentInfo.Student.LastName), "", "success")
 			return ctx.Render("partials/student_info", fiber.Map{
 				"Student":          studentInfo.Student,
@@ -74,11 +58,12 @@ func (h *AppHandler) HandleStudentInfo(ctx *fiber.Ctx) error {
 				"GradesSummary":    studentInfo.GradesSummary,
 				"Assessment":       formatAssessmentForView(studentInfo.Assessment),
 				"PaymentSchedules": formattedSchedules,
+				"ExamPermit":       h.currentExamPermit(studentInfo),
 			})
 		}
 	}
 
-	studentInfo, err := h.RFIDRepository.GetStudentSummaryData(studentId)
+	studentInfo, err := h.RFIDRepository.GetStudentSummaryData(studentId, h.config.Grading)
 	if err != nil {
 		log.Printf("Error getting student info: %v", err)
 		_ = h.db.LogScanEvent(studentId, nil, "db_error", fmt.Sprintf("Error getting student info: %v", err), "", "failure")
@@ -91,17 +76,14 @@ func (h *AppHandler) HandleStudentInfo(ctx *fiber.Ctx) error {
 	}
 	log.Printf("Student info retrieved successfully")
 	// Store in cache
-	studentInfoCache.Set(studentId, studentInfo)
+	studentInfoCache.Set(studentId, studentInfo, studentTag(studentId))
 	_ = h.db.LogScanEvent(studentId, &studentInfo.Student.StudentID, "info_displayed", fmt.Sprintf("Displayed info for student : %s", *studentInfo.Student.FirstName+" "+*studentInfo.Student.LastName), "", "success")
 
 	// Log grades summary for debugging
 	if studentInfo.GradesSummary != nil {
 		log.Printf("Found %d year summaries", len(studentInfo.GradesSummary))
 		for _, year := range studentInfo.GradesSummary {
-			log.Printf("Year: %s, First: %v, Second: %v",
-				year.YearName,
-				year.FirstSem,
-				year.SecondSem)
+			log.Printf("Year: %s, Terms: %d", year.YearName, len(year.Terms))
 		}
 	} else {
 		log.Printf("No grades summary found")
@@ -120,13 +102,39 @@ func (h *AppHandler) HandleStudentInfo(ctx *fiber.Ctx) error {
 	log.Printf("Formatted assessment: %+v", formattedAssessment)
 
 	// Format payment schedules
+	formattedSchedules := h.formatPaymentSchedules(studentInfo.Assessment, studentInfo.PaymentSchedules)
+
+	// Render the template with formatted data
+	return ctx.Render("partials/student_info", fiber.Map{
+		"Student":          studentInfo.Student,
+		"YearLevel":        studentInfo.YearLevel,
+		"GradesSummary":    studentInfo.GradesSummary,
+		"Assessment":       formattedAssessment,
+		"PaymentSchedules": formattedSchedules,
+		"ExamPermit":       h.currentExamPermit(studentInfo),
+	})
+}
+
+// formatPaymentSchedules formats the payment schedule installments for display,
+// with whether each is paid, partially paid or overdue and its late penalty.
+// The initial payment is not shown.
+func (h *AppHandler) formatPaymentSchedules(assessment *model.Assessment, schedules []model.PaymentSchedule) []model.PaymentScheduleViewModel {
+	var totalPaid float64
+	if assessment != nil {
+		totalPaid = assessment.TotalPaymentAmount
+	}
+	statuses := make(map[int64]model.InstallmentStatus)
+	for _, status := range services.EvaluateInstallments(schedules, totalPaid, time.Now(), h.config.Billing.LatePenalty) {
+		statuses[status.ScheduleID] = status
+	}
+
 	var formattedSchedules []model.PaymentScheduleViewModel
-	for _, schedule := range studentInfo.PaymentSchedules {
-		if schedule.TermDescription == "Initial Payment" {
+	for _, schedule := range schedules {
+		if schedule.TermDescription == services.InitialPaymentDescription {
 			continue
 		}
-
-		formatted := model.PaymentScheduleViewModel{
+		status := statuses[schedule.ID]
+		formattedSchedules = append(formattedSchedules, model.PaymentScheduleViewModel{
 			ID:                      schedule.ID,
 			AssessmentNumber:        schedule.AssessmentNumber,
 			TermDescription:         schedule.TermDescription,
@@ -134,17 +142,11 @@ func (h *AppHandler) HandleStudentInfo(ctx *fiber.Ctx) error {
 			ExpectedAmount:          schedule.ExpectedAmount,
 			ExpectedAmountFormatted: formatAmount(schedule.ExpectedAmount),
 			SortOrder:               schedule.SortOrder,
-		}
-		log.Printf("Formatted payment schedule: %+v", formatted)
-		formattedSchedules = append(formattedSchedules, formatted)
+			Status:                  status.Status,
+			PaidAmountFormatted:     formatAmount(status.PaidAmount),
+			PenaltyFormatted:        formatAmount(status.Penalty),
+			DaysOverdue:             status.DaysOverdue,
+		})
 	}
-
-	// Render the template with formatted data
-	return ctx.Render("partials/student_info", fiber.Map{
-		"Student":          studentInfo.Student,
-		"YearLevel":        studentInfo.YearLevel,
-		"GradesSummary":    studentInfo.GradesSummary,
-		"Assessment":       formattedAssessment,
-		"PaymentSchedules": formattedSchedules,
-	})
+	return formattedSchedules
 }
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"rfidsystem/internal/repositories"
+	"rfidsystem/internal/services"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// photoPlaceholder is shown for students without a photo.
+const photoPlaceholder = "/ui/static/images/profile-placeholder.png"
+
+// HandleUploadStudentPhoto handles HTTP requests to upload the photo of the student in
+// the ":id" path parameter, in the "photo" form field. JPEG, PNG and GIF images are
+// accepted; the photo is stored as square JPEGs in the thumbnail and kiosk sizes,
+// without its EXIF data, and replaces the student's previous photo.
+func (h *AppHandler) HandleUploadStudentPhoto(ctx *fiber.Ctx) error {
+	studentID := ctx.Params("id")
+	student, err := h.RFIDRepository.GetStudent(studentID)
+	if err != nil {
+		log.Printf("Error retrieving student %s: %v", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if student == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Student not found")
+	}
+	if _, err := services.StudentPhotoPath(h.config.Photos.Dir, studentID, services.PhotoKiosk); err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).SendString(err.Error())
+	}
+
+	fileHeader, err := ctx.FormFile("photo")
+	if err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("A photo is required")
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Printf("Error opening uploaded photo of student %s: %v", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.Printf("Error reading uploaded photo of student %s: %v", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	photos, err := services.ResizeStudentPhoto(data)
+	if err != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).SendString(err.Error())
+	}
+	if err := services.SaveStudentPhotos(h.config.Photos.Dir, studentID, photos); err != nil {
+		log.Printf("Error saving photo of student %s: %v", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	now := time.Now()
+	if err := h.RFIDRepository.SetStudentPhotoUpdated(studentID, &now); err != nil {
+		log.Printf("Error recording photo of student %s: %v", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	h.studentChanged(ctx, studentID, "student_photo_updated", fmt.Sprintf("Photo of student %s uploaded", studentID))
+
+	student.PhotoUpdatedAt = &now
+	return ctx.JSON(student)
+}
+
+// HandleDeleteStudentPhoto handles HTTP requests to remove the photo of the student in
+// the ":id" path parameter, so the kiosk shows the placeholder instead.
+func (h *AppHandler) HandleDeleteStudentPhoto(ctx *fiber.Ctx) error {
+	studentID := ctx.Params("id")
+	if err := h.RFIDRepository.SetStudentPhotoUpdated(studentID, nil); err != nil {
+		if errors.Is(err, repositories.ErrStudentNotFound) {
+			return ctx.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		log.Printf("Error removing photo of student %s: %v", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if err := services.DeleteStudentPhotos(h.config.Photos.Dir, studentID); err != nil {
+		log.Printf("Error deleting photo files of student %s: %v", studentID, err)
+	}
+	h.studentChanged(ctx, studentID, "student_photo_removed", fmt.Sprintf("Photo of student %s removed", studentID))
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// HandleStudentPhoto handles HTTP requests for the photo of the student in the ":id"
+// path parameter, in the size given by the "size" query parameter ("kiosk", the
+// default, or "thumb"). Students without a photo are redirected to the placeholder.
+// Kiosk pages link to photos with a "v" query parameter that changes with every
+// upload, so those responses can be cached for good; other requests are revalidated.
+func (h *AppHandler) HandleStudentPhoto(ctx *fiber.Ctx) error {
+	size := ctx.Query("size", services.PhotoKiosk)
+	if !services.IsPhotoSize(size) {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Size must be kiosk or thumb")
+	}
+	path, err := services.StudentPhotoPath(h.config.Photos.Dir, ctx.Params("id"), size)
+	if err != nil {
+		return ctx.Redirect(photoPlaceholder)
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return ctx.Redirect(photoPlaceholder)
+	}
+	if err != nil {
+		log.Printf("Error reading photo %s: %v", path, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	if ctx.Query("v") != "" {
+		ctx.Set(fiber.HeaderCacheControl, "public, max-age=31536000, immutable")
+	} else {
+		ctx.Set(fiber.HeaderCacheControl, "public, no-cache")
+	}
+	etag := fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+	ctx.Set(fiber.HeaderETag, etag)
+	ctx.Set(fiber.HeaderLastModified, info.ModTime().UTC().Format(http.TimeFormat))
+	if ctx.Get(fiber.HeaderIfNoneMatch) == etag {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ctx.Redirect(photoPlaceholder)
+	}
+	if err != nil {
+		log.Printf("Error reading photo %s: %v", path, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	ctx.Set(fiber.HeaderContentType, "image/jpeg")
+	return ctx.Send(data)
+}
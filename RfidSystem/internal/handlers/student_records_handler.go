@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/repositories"
+	"rfidsystem/internal/services"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// studentRequest is the body of a request to create or update a student record.
+type studentRequest struct {
+	StudentID     string `json:"student_id" form:"student_id"`
+	DepartmentID  *int64 `json:"department_id" form:"department_id"`
+	FirstName     string `json:"first_name" form:"first_name"`
+	LastName      string `json:"last_name" form:"last_name"`
+	MiddleName    string `json:"middle_name" form:"middle_name"`
+	Birthday      string `json:"birthday" form:"birthday"`
+	ContactNumber string `json:"contact_number" form:"contact_number"`
+	Email         string `json:"email" form:"email"`
+	YearLevel     *int   `json:"year_level" form:"year_level"`
+	Program       string `json:"program" form:"program"`
+	BlockSection  string `json:"block_section" form:"block_section"`
+}
+
+// HandleSearchStudents handles HTTP requests to search the student records. It accepts
+// the query parameters "q" (words to find in the student ID, names or email),
+// "program", "year_level", "block_section", "department_id", "status", "sort" (name,
+// student_id, program, year_level or block_section), "order" (asc or desc),
+// "page_size" and "cursor", and returns a page of students with the cursors of the
+// next and previous pages. A cursor keeps the sort order it was returned with.
+func (h *AppHandler) HandleSearchStudents(ctx *fiber.Ctx) error {
+	filter := model.StudentFilter{
+		Query:        strings.TrimSpace(ctx.Query("q")),
+		Program:      strings.ToUpper(strings.TrimSpace(ctx.Query("program"))),
+		YearLevel:    ctx.QueryInt("year_level"),
+		BlockSection: strings.TrimSpace(ctx.Query("block_section")),
+		DepartmentID: int64(ctx.QueryInt("department_id")),
+		Status:       strings.TrimSpace(ctx.Query("status")),
+		Sort:         ctx.Query("sort", model.StudentSortName),
+		PageSize:     ctx.QueryInt("page_size", 25),
+	}
+	if !repositories.IsStudentSort(filter.Sort) {
+		return ctx.Status(fiber.StatusBadRequest).SendString(fmt.Sprintf("Cannot sort students by %q", filter.Sort))
+	}
+	switch ctx.Query("order", "asc") {
+	case "asc":
+	case "desc":
+		filter.Descending = true
+	default:
+		return ctx.Status(fiber.StatusBadRequest).SendString("Order must be asc or desc")
+	}
+	if filter.PageSize < 1 || filter.PageSize > 100 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Page size must be between 1 and 100")
+	}
+	if len(strings.Fields(filter.Query)) > 5 {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Search at most 5 words at a time")
+	}
+	if cursor := ctx.Query("cursor"); cursor != "" {
+		var err error
+		if filter.Cursor, err = model.DecodeStudentCursor(cursor); err != nil {
+			return ctx.Status(fiber.StatusBadRequest).SendString("Invalid cursor")
+		}
+	}
+
+	response, err := h.RFIDRepository.SearchStudents(filter)
+	if errors.Is(err, repositories.ErrInvalidCursor) {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid cursor")
+	}
+	if err != nil {
+		log.Printf("Error searching students: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(response)
+}
+
+// HandleGetStudent handles HTTP requests to retrieve the student record in the ":id" path parameter.
+func (h *AppHandler) HandleGetStudent(ctx *fiber.Ctx) error {
+	studentID := ctx.Params("id")
+	student, err := h.RFIDRepository.GetStudent(studentID)
+	if err != nil {
+		log.Printf("Error retrieving student %s: %v", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if student == nil {
+		return ctx.Status(fiber.StatusNotFound).SendString("Student not found")
+	}
+	return ctx.JSON(student)
+}
+
+// HandleSaveStudent handles HTTP requests to create a student record, or to update
+// the one in the ":id" path parameter. Invalid fields are reported with 422 and a
+// JSON object of messages keyed by field; a student ID or email that is already
+// taken is reported with 409.
+func (h *AppHandler) HandleSaveStudent(ctx *fiber.Ctx) error {
+	var req studentRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).SendString("Invalid request body")
+	}
+	create := ctx.Params("id") == ""
+	if !create {
+		req.StudentID = ctx.Params("id")
+	}
+	student := req.toStudent()
+	if errs := services.ValidateStudent(student); errs != nil {
+		return ctx.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"errors": errs})
+	}
+
+	var err error
+	if create {
+		err = h.RFIDRepository.CreateStudent(student)
+	} else {
+		err = h.RFIDRepository.UpdateStudent(student)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrStudentNotFound):
+			return ctx.Status(fiber.StatusNotFound).SendString(err.Error())
+		case errors.Is(err, repositories.ErrStudentExists), errors.Is(err, repositories.ErrStudentEmailTaken):
+			return ctx.Status(fiber.StatusConflict).SendString(err.Error())
+		case errors.Is(err, repositories.ErrUnknownDepartment):
+			return ctx.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"errors": fiber.Map{"department_id": err.Error()}})
+		}
+		log.Printf("Error saving student %s: %v", student.StudentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+
+	action := "updated"
+	status := fiber.StatusOK
+	if create {
+		action = "created"
+		status = fiber.StatusCreated
+	}
+	h.studentChanged(ctx, student.StudentID, "student_"+action, fmt.Sprintf("Student %s %s", student.StudentID, action))
+
+	saved, err := h.RFIDRepository.GetStudent(student.StudentID)
+	if err != nil || saved == nil {
+		log.Printf("Error retrieving saved student %s: %v", student.StudentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.Status(status).JSON(saved)
+}
+
+// HandleDeactivateStudent handles HTTP requests to mark the student in the ":id" path
+// parameter as inactive, e.g. after they left the school. Their records are kept.
+func (h *AppHandler) HandleDeactivateStudent(ctx *fiber.Ctx) error {
+	return h.setStudentStatus(ctx, model.StudentStatusInactive, "student_deactivated")
+}
+
+// HandleReactivateStudent handles HTTP requests to mark the student in the ":id" path
+// parameter as active again.
+func (h *AppHandler) HandleReactivateStudent(ctx *fiber.Ctx) error {
+	return h.setStudentStatus(ctx, model.StudentStatusActive, "student_reactivated")
+}
+
+func (h *AppHandler) setStudentStatus(ctx *fiber.Ctx, status, eventType string) error {
+	studentID := ctx.Params("id")
+	if err := h.RFIDRepository.SetStudentStatus(studentID, status); err != nil {
+		if errors.Is(err, repositories.ErrStudentNotFound) {
+			return ctx.Status(fiber.StatusNotFound).SendString(err.Error())
+		}
+		log.Printf("Error setting status of student %s to %s: %v", studentID, status, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	h.studentChanged(ctx, studentID, eventType, fmt.Sprintf("Student %s set to %s", studentID, status))
+
+	student, err := h.RFIDRepository.GetStudent(studentID)
+	if err != nil || student == nil {
+		log.Printf("Error retrieving student %s: %v", studentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	return ctx.JSON(student)
+}
+
+// studentChanged logs a change to a student record and drops the kiosk views
+// that show the student's details.
+func (h *AppHandler) studentChanged(ctx *fiber.Ctx, studentID, eventType, message string) {
+	invalidateStudent(studentID)
+	changedBy, _ := ctx.Locals("userEmail").(string)
+	_ = h.db.LogScanEvent("", &studentID, eventType, fmt.Sprintf("%s by %s", message, changedBy), "", "info")
+}
+
+// toStudent builds a normalized student record from the request fields.
+func (req studentRequest) toStudent() *model.Student {
+	student := &model.Student{
+		StudentID:     req.StudentID,
+		DepartmentID:  req.DepartmentID,
+		FirstName:     &req.FirstName,
+		LastName:      &req.LastName,
+		MiddleName:    &req.MiddleName,
+		Birthday:      &req.Birthday,
+		ContactNumber: &req.ContactNumber,
+		Email:         &req.Email,
+		YearLevel:     req.YearLevel,
+		Program:       &req.Program,
+		BlockSection:  &req.BlockSection,
+	}
+	services.NormalizeStudent(student)
+	return student
+}
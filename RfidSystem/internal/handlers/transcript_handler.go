@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetTranscript handles HTTP requests for a student's academic history as JSON:
+// every term they were enrolled in with its subjects, final grades, units and
+// GWA, and the cumulative GWA. It expects the student ID as a path parameter.
+func (h *AppHandler) GetTranscript(ctx *fiber.Ctx) error {
+	transcript, err := h.transcriptFromParams(ctx)
+	if err != nil || transcript == nil {
+		return err
+	}
+	return ctx.JSON(transcript)
+}
+
+// HandleTranscript handles HTTP requests for a print-friendly unofficial transcript.
+// It expects the student ID as a path parameter.
+func (h *AppHandler) HandleTranscript(ctx *fiber.Ctx) error {
+	transcript, err := h.transcriptFromParams(ctx)
+	if err != nil || transcript == nil {
+		return err
+	}
+	return ctx.Render("pages/transcript", fiber.Map{
+		"Title":       "Unofficial Transcript of Records",
+		"Institution": h.config.Billing,
+		"Transcript":  transcript,
+		"Student":     transcript.Student,
+	})
+}
+
+// HandleTranscriptPDF handles HTTP requests to download a student's unofficial transcript as a PDF.
+// It expects the student ID as a path parameter.
+func (h *AppHandler) HandleTranscriptPDF(ctx *fiber.Ctx) error {
+	transcript, err := h.transcriptFromParams(ctx)
+	if err != nil || transcript == nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := services.RenderTranscriptPDF(&buf, transcript, h.config.Billing); err != nil {
+		log.Printf("Error rendering transcript for %s: %v", transcript.Student.StudentID, err)
+		return ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	ctx.Set("Content-Type", "application/pdf")
+	ctx.Set("Content-Disposition", fmt.Sprintf("inline; filename=transcript-%s.pdf", transcript.Student.StudentID))
+	return ctx.Send(buf.Bytes())
+}
+
+// transcriptFromParams loads the transcript of the student named by the ":id"
+// path parameter and computes its GWAs. It writes the error response and returns
+// a nil transcript if it cannot be loaded.
+func (h *AppHandler) transcriptFromParams(ctx *fiber.Ctx) (*model.Transcript, error) {
+	studentID := ctx.Params("id")
+	transcript, err := h.RFIDRepository.GetTranscript(studentID)
+	if err != nil {
+		log.Printf("Error retrieving transcript for %s: %v", studentID, err)
+		return nil, ctx.Status(fiber.StatusInternalServerError).SendString("Internal server error")
+	}
+	if transcript == nil {
+		return nil, ctx.Status(fiber.StatusNotFound).SendString("Student not found")
+	}
+	services.SummarizeTranscript(transcript, h.config.Grading)
+	return transcript, nil
+}
@@ -1,7 +1,14 @@
 package model
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -11,9 +18,16 @@ type StudentInfo struct {
 }
 
 type YearGradeSummary struct {
-	YearName  string  `json:"year_name"`
-	FirstSem  *string `json:"first_sem,omitempty"`
-	SecondSem *string `json:"second_sem,omitempty"`
+	YearName string             `json:"year_name"`
+	Terms    []TermGradeSummary `json:"terms"`
+}
+
+// TermGradeSummary is the formatted GWA of one term of a year summary, or nil
+// if no subject of the term has a final grade yet.
+type TermGradeSummary struct {
+	TermID   int64   `json:"term_id"`
+	Semester string  `json:"semester"`
+	GWA      *string `json:"gwa,omitempty"`
 }
 
 type StudentInfoViewModel struct {
@@ -43,6 +57,8 @@ type AcademicTerm struct {
 	// EndDate      *time.Time `json:"end_date,omitempty" db:"end_date"`
 	StartDate string `json:"start_date,omitempty" db:"start_date"`
 	EndDate   string `json:"end_date,omitempty" db:"end_date"`
+	// IsActive marks the term set as the current term regardless of its dates.
+	IsActive bool `json:"is_active" db:"is_active"`
 }
 
 type FeeType struct {
@@ -57,6 +73,7 @@ type DiscountType struct {
 	Description  *string `json:"description,omitempty" db:"description"`
 	IsPercentage bool    `json:"is_percentage" db:"is_percentage"`
 	Value        float64 `json:"value" db:"value"`
+	IsActive     bool    `json:"is_active" db:"is_active"`
 }
 
 type Student struct {
@@ -76,6 +93,8 @@ type Student struct {
 	// FirstAccessTimestamp string  `json:"first_access_timestamp" db:"first_access_timestamp"`
 	// LastAccessTimestamp  string  `json:"last_access_timestamp" db:"last_access_timestamp"`
 	Status *string `json:"status,omitempty" db:"status"`
+	// PhotoUpdatedAt is when the student's photo was last uploaded, or nil if they have none.
+	PhotoUpdatedAt *time.Time `json:"photo_updated_at,omitempty" db:"photo_updated_at"`
 }
 
 // MarshalJSON customizes Student JSON to format timestamps as "YYYY-MM-DD hh:mm am/pm" without seconds
@@ -189,6 +208,173 @@ type PaymentScheduleViewModel struct {
 	ExpectedAmount          float64
 	ExpectedAmountFormatted string
 	SortOrder               int
+	Status                  string
+	PaidAmountFormatted     string
+	PenaltyFormatted        string
+	DaysOverdue             int
+}
+
+// Installment statuses computed from an assessment's payments.
+const (
+	InstallmentPaid     = "paid"
+	InstallmentPartial  = "partial"
+	InstallmentOverdue  = "overdue"
+	InstallmentUpcoming = "upcoming"
+)
+
+// InstallmentStatus is the payment status of one payment schedule installment.
+// Payments are applied to installments in sort order.
+type InstallmentStatus struct {
+	ScheduleID      int64   `json:"schedule_id"`
+	TermDescription string  `json:"term_description"`
+	DueDate         string  `json:"due_date"`
+	ExpectedAmount  float64 `json:"expected_amount"`
+	PaidAmount      float64 `json:"paid_amount"`
+	Outstanding     float64 `json:"outstanding"`
+	Status          string  `json:"status"`
+	DaysOverdue     int     `json:"days_overdue"`
+	Penalty         float64 `json:"penalty"`
+}
+
+// AssessmentStanding is a student's assessment with its payment schedule.
+// AssessmentNumber is 0 if the student has not been assessed.
+type AssessmentStanding struct {
+	AssessmentNumber   int64
+	StudentID          string
+	StudentName        string
+	Program            *string
+	BlockSection       *string
+	TotalPaymentAmount float64
+	RemainingBalance   float64
+	PerExamFee         *float64
+	Schedules          []PaymentSchedule
+}
+
+// DelinquentAccount is a row of the delinquent accounts report.
+type DelinquentAccount struct {
+	AssessmentNumber    int64   `json:"assessment_number"`
+	StudentID           string  `json:"student_id"`
+	StudentName         string  `json:"student_name"`
+	Program             *string `json:"program,omitempty"`
+	BlockSection        *string `json:"block_section,omitempty"`
+	RemainingBalance    float64 `json:"remaining_balance"`
+	OverdueAmount       float64 `json:"overdue_amount"`
+	Penalty             float64 `json:"penalty"`
+	OverdueInstallments int     `json:"overdue_installments"`
+	OldestDueDate       string  `json:"oldest_due_date"`
+	DaysOverdue         int     `json:"days_overdue"`
+}
+
+// FeeScheduleItem is a fee charged when an assessment is generated. A nil TermID
+// or Program applies to every term or program. Per-unit fees are multiplied by
+// the student's enrolled units.
+type FeeScheduleItem struct {
+	ID        int64   `json:"fee_schedule_id"`
+	FeeTypeID int64   `json:"fee_type_id"`
+	Name      string  `json:"name"`
+	Category  string  `json:"category"`
+	TermID    *int64  `json:"term_id,omitempty"`
+	Program   *string `json:"program,omitempty"`
+	Amount    float64 `json:"amount"`
+	PerUnit   bool    `json:"per_unit"`
+}
+
+// EnrolledSubject is a subject a student is enrolled in for a term.
+type EnrolledSubject struct {
+	SubjectCode string  `json:"subject_code"`
+	SubjectName string  `json:"subject_name"`
+	Units       float64 `json:"units"`
+}
+
+// AssessmentRequest asks for an assessment to be generated for a student and term.
+type AssessmentRequest struct {
+	StudentID       string
+	TermID          int64
+	DiscountTypeIDs []int64
+	// Replace regenerates an existing assessment of the term that has no payments yet.
+	Replace bool
+	// DryRun computes the assessment without saving it.
+	DryRun      bool
+	GeneratedBy string
+}
+
+// AssessmentInput is what an assessment is computed from.
+type AssessmentInput struct {
+	Subjects      []EnrolledSubject
+	Fees          []FeeScheduleItem
+	DiscountTypes []DiscountType
+	TermStart     time.Time
+	TermEnd       time.Time
+}
+
+// AssessmentFeeLine is a fee of a generated assessment.
+type AssessmentFeeLine struct {
+	FeeTypeID int64   `json:"fee_type_id"`
+	Name      string  `json:"name"`
+	Category  string  `json:"category"`
+	Amount    float64 `json:"amount"`
+}
+
+// GeneratedAssessment is an assessment computed from a student's enrolled subjects
+// and the fee schedule, with its fees, discounts and payment schedule.
+type GeneratedAssessment struct {
+	Assessment       Assessment           `json:"assessment"`
+	Subjects         []EnrolledSubject    `json:"subjects"`
+	Units            float64              `json:"units"`
+	Fees             []AssessmentFeeLine  `json:"fees"`
+	Discounts        []AssessmentDiscount `json:"discounts"`
+	PaymentSchedules []PaymentSchedule    `json:"payment_schedules"`
+	DryRun           bool                 `json:"dry_run"`
+}
+
+// Discount audit trail actions.
+const (
+	DiscountGranted = "granted"
+	DiscountRevoked = "revoked"
+)
+
+// DiscountGrant is a request to apply a discount type to an assessment.
+type DiscountGrant struct {
+	AssessmentNumber int64
+	DiscountTypeID   int64
+	Reason           *string
+	GrantedBy        string
+}
+
+// DiscountAuditEntry records a discount granted to or revoked from an assessment.
+type DiscountAuditEntry struct {
+	ID                   int64     `json:"audit_id"`
+	AssessmentNumber     int64     `json:"assessment_number"`
+	AssessmentDiscountID int64     `json:"assessment_discount_id"`
+	DiscountTypeID       int64     `json:"discount_type_id"`
+	DiscountName         string    `json:"discount_name"`
+	Action               string    `json:"action"`
+	AppliedAmount        float64   `json:"applied_amount"`
+	CalculationBasis     *float64  `json:"calculation_basis,omitempty"`
+	PerformedBy          string    `json:"performed_by"`
+	Reason               *string   `json:"reason,omitempty"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+// DiscountChange is the result of granting or revoking a discount: the discount,
+// the assessment with its recomputed totals and the audit trail entry.
+type DiscountChange struct {
+	Discount   AssessmentDiscount `json:"discount"`
+	Assessment Assessment         `json:"assessment"`
+	Audit      DiscountAuditEntry `json:"audit"`
+}
+
+// ExamPermit is the decision whether a student may take an exam: cleared once
+// the payment schedule installments due for the exam have been paid.
+type ExamPermit struct {
+	StudentID    string   `json:"student_id"`
+	StudentName  string   `json:"student_name"`
+	BlockSection *string  `json:"block_section,omitempty"`
+	Exam         string   `json:"exam"`
+	Cleared      bool     `json:"cleared"`
+	AmountDue    float64  `json:"amount_due"`
+	PerExamFee   *float64 `json:"per_exam_fee,omitempty"`
+	Reason       string   `json:"reason,omitempty"`
 }
 
 // -------------------------
@@ -224,20 +410,179 @@ type Bills struct {
 	PaymentHistory []PaymentRecord
 }
 
+// StatementOfAccount is everything printed on a student's statement of account.
+type StatementOfAccount struct {
+	Student          *Student
+	Term             *string
+	Bills            *Bills
+	PaymentSchedules []PaymentSchedule
+	GeneratedAt      time.Time
+}
+
 type GradesRecord struct {
 	SubjectCode    string   `json:"subject_code"`
 	SubjectName    string   `json:"subject_name"`
+	Units          float64  `json:"units"`
 	PrelimGrade    *float64 `json:"prelim_grade"`
 	MidtermGrade   *float64 `json:"midterm_grade"`
 	PrefinalGrade  *float64 `json:"prefinal_grade"`
 	FinalGrade     *float64 `json:"final_grade"`
 	FinalTermGrade *float64 `json:"final_term_grade"`
+	// GradeRemark is RemarkIncomplete or RemarkDropped when the enrollment was
+	// marked so, and empty otherwise.
+	GradeRemark string `json:"grade_remark,omitempty"`
+}
+
+// Remarks of an enrollment's final grade.
+const (
+	RemarkPassed     = "Passed"
+	RemarkFailed     = "Failed"
+	RemarkIncomplete = "INC"
+	RemarkDropped    = "DRP"
+)
+
+// GradeSummary is the GWA of a set of grades and the Latin honor it earns.
+type GradeSummary struct {
+	// GWA is the units-weighted average of the final grades, or nil if no
+	// subject has a final grade yet.
+	GWA    *float64 `json:"gwa"`
+	Units  float64  `json:"units"`
+	Honors string   `json:"honors,omitempty"`
 }
 
 type Grades struct {
-	Student     *Student
+	Student *Student
+	// CurrentTerm is the term the grades are for.
 	CurrentTerm *AcademicTerm
-	Grades      []GradesRecord
+	// Terms lists the terms the student has records in, in chronological order.
+	Terms  []AcademicTerm
+	Grades []GradesRecord
+}
+
+// Transcript is a student's academic history across every term they were enrolled in.
+type Transcript struct {
+	Student *Student         `json:"student"`
+	Terms   []TranscriptTerm `json:"terms"`
+	// Cumulative is the GWA over every term.
+	Cumulative  GradeSummary `json:"cumulative"`
+	GeneratedAt time.Time    `json:"generated_at"`
+}
+
+// TranscriptTerm is the grades of one term of a transcript.
+type TranscriptTerm struct {
+	Term    AcademicTerm      `json:"term"`
+	Grades  []TranscriptGrade `json:"grades"`
+	Summary GradeSummary      `json:"summary"`
+}
+
+// TranscriptGrade is a subject of a transcript term with its remark.
+type TranscriptGrade struct {
+	GradesRecord
+	Remark string `json:"remark"`
+}
+
+// Grading periods of an enrollment, in order.
+const (
+	PeriodPrelim   = "prelim"
+	PeriodMidterm  = "midterm"
+	PeriodPrefinal = "prefinal"
+	PeriodFinal    = "final"
+)
+
+// GradingPeriods lists the grading periods in order.
+var GradingPeriods = []string{PeriodPrelim, PeriodMidterm, PeriodPrefinal, PeriodFinal}
+
+// FacultyAssignment assigns a class (a subject's block section in a term) to a faculty account.
+type FacultyAssignment struct {
+	ID           int64  `json:"assignment_id"`
+	UserID       int64  `json:"user_id"`
+	Email        string `json:"email"`
+	SubjectCode  string `json:"subject_code"`
+	SubjectName  string `json:"subject_name,omitempty"`
+	TermID       int64  `json:"term_id"`
+	BlockSection string `json:"block_section"`
+}
+
+// RosterEntry is a student of a class roster with their grades.
+type RosterEntry struct {
+	EnrollmentID   int64    `json:"enrollment_id"`
+	StudentID      string   `json:"student_id"`
+	StudentName    string   `json:"student_name"`
+	PrelimGrade    *float64 `json:"prelim_grade"`
+	MidtermGrade   *float64 `json:"midterm_grade"`
+	PrefinalGrade  *float64 `json:"prefinal_grade"`
+	FinalTermGrade *float64 `json:"final_term_grade"`
+	FinalGrade     *float64 `json:"final_grade"`
+	GradeRemark    *string  `json:"grade_remark"`
+}
+
+// PeriodGrade returns the grade of the given grading period.
+func (e *RosterEntry) PeriodGrade(period string) *float64 {
+	switch period {
+	case PeriodPrelim:
+		return e.PrelimGrade
+	case PeriodMidterm:
+		return e.MidtermGrade
+	case PeriodPrefinal:
+		return e.PrefinalGrade
+	case PeriodFinal:
+		return e.FinalTermGrade
+	}
+	return nil
+}
+
+// SetPeriodGrade sets the grade of the given grading period.
+func (e *RosterEntry) SetPeriodGrade(period string, grade *float64) {
+	switch period {
+	case PeriodPrelim:
+		e.PrelimGrade = grade
+	case PeriodMidterm:
+		e.MidtermGrade = grade
+	case PeriodPrefinal:
+		e.PrefinalGrade = grade
+	case PeriodFinal:
+		e.FinalTermGrade = grade
+	}
+}
+
+// ClassRoster is the students enrolled in a subject's block section for a term.
+type ClassRoster struct {
+	SubjectCode  string        `json:"subject_code"`
+	SubjectName  string        `json:"subject_name"`
+	TermID       int64         `json:"term_id"`
+	Semester     string        `json:"semester"`
+	BlockSection string        `json:"block_section"`
+	Locked       bool          `json:"locked"`
+	Entries      []RosterEntry `json:"entries"`
+}
+
+// GradeSubmission is a faculty member's grades for one grading period of a class.
+type GradeSubmission struct {
+	SubjectCode  string
+	TermID       int64
+	BlockSection string
+	Period       string
+	// Grades maps a student ID to their grade; a nil grade clears it.
+	Grades map[string]*float64
+	// Remarks maps a student ID to RemarkIncomplete or RemarkDropped, or to
+	// nil to clear the remark. Students left out keep their remark.
+	Remarks     map[string]*string
+	SubmittedBy string
+}
+
+// StudentIDs returns the students the submission changes, in sorted order.
+func (s GradeSubmission) StudentIDs() []string {
+	ids := make([]string, 0, len(s.Grades)+len(s.Remarks))
+	for id := range s.Grades {
+		ids = append(ids, id)
+	}
+	for id := range s.Remarks {
+		if _, ok := s.Grades[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
 }
 
 // StudentAssessmentSummary represents the summary of students for a specific assessment term.
@@ -250,11 +595,14 @@ type StudentAssessmentSummary struct {
 }
 
 // PaginationMetadata holds information about the pagination state.
+// Cursor-paginated lists also return the cursors of the pages around the current one.
 type PaginationMetadata struct {
-	CurrentPage int `json:"currentPage"`
-	PageSize    int `json:"pageSize"`
-	TotalItems  int `json:"totalItems"`
-	TotalPages  int `json:"totalPages"`
+	CurrentPage int    `json:"currentPage"`
+	PageSize    int    `json:"pageSize"`
+	TotalItems  int    `json:"totalItems"`
+	TotalPages  int    `json:"totalPages"`
+	NextCursor  string `json:"nextCursor,omitempty"`
+	PrevCursor  string `json:"prevCursor,omitempty"`
 }
 
 // PaginatedStudentAssessmentResponse structures the paginated response for student assessments.
@@ -262,3 +610,372 @@ type PaginatedStudentAssessmentResponse struct {
 	Data       []*StudentAssessmentSummary `json:"data"`
 	Pagination PaginationMetadata          `json:"pagination"`
 }
+
+// -------------------------
+// Payment recording structs
+
+// NewPayment is a payment a cashier records against an assessment.
+type NewPayment struct {
+	AssessmentNumber int64
+	Amount           float64
+	PaymentMethod    string
+	ReferenceNumber  *string
+	Description      *string
+	RecordedBy       string
+}
+
+// RecordedPayment is the result of recording a payment: the stored payment,
+// its official receipt, the assessment with its updated totals and the
+// installments it settled.
+type RecordedPayment struct {
+	Payment          Payment          `json:"payment"`
+	Receipt          *OfficialReceipt `json:"receipt"`
+	Assessment       *Assessment      `json:"assessment"`
+	SettledSchedules []int64          `json:"settled_schedule_ids"`
+}
+
+// OfficialReceipt is the numbered official receipt (OR) issued for a payment.
+// Its fields are a snapshot taken when the payment was recorded.
+type OfficialReceipt struct {
+	Number           int64     `json:"or_number" db:"or_number"`
+	PaymentID        int64     `json:"payment_id" db:"payment_id"`
+	AssessmentNumber int64     `json:"assessment_number" db:"assessment_number"`
+	StudentID        string    `json:"student_id" db:"student_ID"`
+	StudentName      string    `json:"student_name" db:"student_name"`
+	Term             *string   `json:"term,omitempty" db:"term"`
+	Amount           float64   `json:"amount" db:"amount"`
+	PaymentMethod    string    `json:"payment_method" db:"payment_method"`
+	ReferenceNumber  *string   `json:"reference_number,omitempty" db:"reference_number"`
+	Description      *string   `json:"description,omitempty" db:"description"`
+	BalanceAfter     float64   `json:"balance_after" db:"balance_after"`
+	IssuedAt         time.Time `json:"issued_at" db:"issued_at"`
+	IssuedBy         string    `json:"issued_by" db:"issued_by"`
+	ContentHash      string    `json:"content_hash" db:"content_hash"`
+}
+
+// FormattedNumber returns the OR number as printed on the receipt.
+func (r *OfficialReceipt) FormattedNumber() string {
+	return fmt.Sprintf("OR-%08d", r.Number)
+}
+
+// ComputeHash returns the HMAC-SHA256 of the receipt's printed fields under key,
+// the receipt signing key. A receipt whose stored ContentHash differs from
+// ComputeHash has been altered; without the key, which is never stored in the
+// database, an altered receipt cannot be given a matching hash.
+func (r *OfficialReceipt) ComputeHash(key []byte) string {
+	optional := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	content := strings.Join([]string{
+		r.FormattedNumber(),
+		strconv.FormatInt(r.PaymentID, 10),
+		strconv.FormatInt(r.AssessmentNumber, 10),
+		r.StudentID,
+		r.StudentName,
+		optional(r.Term),
+		fmt.Sprintf("%.2f", r.Amount),
+		r.PaymentMethod,
+		optional(r.ReferenceNumber),
+		optional(r.Description),
+		fmt.Sprintf("%.2f", r.BalanceAfter),
+		r.IssuedAt.UTC().Format(time.RFC3339),
+		r.IssuedBy,
+	}, "\n")
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(content))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// -------------------------
+// Card registry structs
+
+// Card states stored in StudentCards.card_state.
+const (
+	CardStateActive   = "active"
+	CardStateReplaced = "replaced"
+	CardStateRevoked  = "revoked"
+	CardStateLost     = "lost"
+	CardStateStolen   = "stolen"
+)
+
+// StudentCard maps a physical RFID card UID to the student who holds it.
+// A student may have several cards over time but only one active card.
+type StudentCard struct {
+	CardUID     string     `json:"card_uid" db:"card_uid"`
+	StudentID   string     `json:"student_id" db:"student_ID"`
+	IssuedDate  time.Time  `json:"issued_date" db:"issued_date"`
+	RevokedDate *time.Time `json:"revoked_date,omitempty" db:"revoked_date"`
+	State       string     `json:"card_state" db:"card_state"`
+	// StudentStatus is the status of the card holder's student record, when loaded.
+	StudentStatus string `json:"student_status,omitempty"`
+}
+
+// IsActive reports whether the card can be used to look up its student.
+func (c *StudentCard) IsActive() bool {
+	return c != nil && c.State == CardStateActive
+}
+
+// HolderInactive reports whether the card holder's student record was deactivated.
+// Scans of their card are rejected like scans of an inactive card.
+func (c *StudentCard) HolderInactive() bool {
+	return c != nil && c.StudentStatus == StudentStatusInactive
+}
+
+// IsBlocked reports whether the card was reported lost or stolen. Scans of a
+// blocked card must not reveal the holder's records.
+func (c *StudentCard) IsBlocked() bool {
+	return c != nil && (c.State == CardStateLost || c.State == CardStateStolen)
+}
+
+// -------------------------
+// Attendance structs
+
+// Attendance directions recorded in AttendanceEvents.direction.
+const (
+	AttendanceIn  = "IN"
+	AttendanceOut = "OUT"
+)
+
+// AttendanceEvent is a single IN/OUT event recorded when a card is scanned at a gate.
+type AttendanceEvent struct {
+	ID        int64     `json:"attendance_id" db:"attendance_id"`
+	StudentID string    `json:"student_id" db:"student_ID"`
+	CardUID   string    `json:"card_uid" db:"card_uid"`
+	ReaderID  string    `json:"reader_id" db:"reader_id"`
+	Direction string    `json:"direction" db:"direction"`
+	EventTime time.Time `json:"event_time" db:"event_time"`
+}
+
+// StudentAttendanceSummary summarizes one student's attendance for a single day.
+type StudentAttendanceSummary struct {
+	StudentID    string     `json:"student_id"`
+	Name         string     `json:"name"`
+	Program      *string    `json:"program,omitempty"`
+	BlockSection *string    `json:"block_section,omitempty"`
+	FirstIn      *time.Time `json:"first_in,omitempty"`
+	LastOut      *time.Time `json:"last_out,omitempty"`
+	EventCount   int        `json:"event_count"`
+	IsPresent    bool       `json:"is_present"`
+}
+
+// GroupAttendanceSummary summarizes attendance for a block section or program on a single day.
+type GroupAttendanceSummary struct {
+	Group           string `json:"group"`
+	TotalStudents   int    `json:"total_students"`
+	AttendedCount   int    `json:"attended_count"`
+	PresentNowCount int    `json:"present_now_count"`
+}
+
+// -------------------------
+// Reader and kiosk structs
+
+// Kiosk is a display screen that subscribes to scan broadcasts for its paired readers.
+type Kiosk struct {
+	ID        string    `json:"kiosk_id" db:"kiosk_id"`
+	Name      string    `json:"name" db:"name"`
+	Location  *string   `json:"location,omitempty" db:"location"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Reader is a registered RFID reader. Scans on the reader are shown on its paired kiosk.
+type Reader struct {
+	ID        string    `json:"reader_id" db:"reader_id"`
+	KioskID   *string   `json:"kiosk_id,omitempty" db:"kiosk_id"`
+	Name      string    `json:"name" db:"name"`
+	Location  *string   `json:"location,omitempty" db:"location"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	// APIKeyHash is the SHA-256 hash of the reader's API key, empty if no key was issued.
+	APIKeyHash     string     `json:"-" db:"api_key_hash"`
+	APIKeyIssuedAt *time.Time `json:"api_key_issued_at,omitempty" db:"api_key_issued_at"`
+}
+
+// HasAPIKey reports whether an API key is currently issued to the reader.
+func (r *Reader) HasAPIKey() bool {
+	return r != nil && r.APIKeyHash != ""
+}
+
+// -------------------------
+// Authentication structs
+
+// Roles stored in Users.role.
+const (
+	RoleSuperAdmin = "super_admin"
+	RoleRegistrar  = "registrar"
+	RoleCashier    = "cashier"
+	RoleKiosk      = "kiosk"
+	RoleFaculty    = "faculty"
+)
+
+// IsValidRole reports whether role is one of the known account roles.
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleSuperAdmin, RoleRegistrar, RoleCashier, RoleKiosk, RoleFaculty:
+		return true
+	}
+	return false
+}
+
+// User is an admin account that can log in to the monitoring pages.
+type User struct {
+	ID           int64     `json:"user_id" db:"user_id"`
+	Email        string    `json:"email" db:"email"`
+	PasswordHash string    `json:"-" db:"password_hash"`
+	Role         string    `json:"role" db:"role"`
+	IsActive     bool      `json:"is_active" db:"is_active"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// Session is a persisted login session. The token itself is never stored,
+// only its SHA-256 hash.
+type Session struct {
+	TokenHash  string    `db:"token_hash"`
+	UserID     int64     `db:"user_id"`
+	Email      string    `db:"email"`
+	Role       string    `db:"role"`
+	CreatedAt  time.Time `db:"created_at"`
+	LastSeenAt time.Time `db:"last_seen_at"`
+	ExpiresAt  time.Time `db:"expires_at"`
+}
+
+// -------------------------
+// Student records structs
+
+// Student statuses stored in Students.status.
+const (
+	StudentStatusActive   = "active"
+	StudentStatusInactive = "inactive"
+)
+
+// MaxYearLevel is the highest year level a student can be in.
+const MaxYearLevel = 6
+
+// Columns a student search can be sorted by.
+const (
+	StudentSortName         = "name"
+	StudentSortID           = "student_id"
+	StudentSortProgram      = "program"
+	StudentSortYearLevel    = "year_level"
+	StudentSortBlockSection = "block_section"
+)
+
+// StudentFilter selects and orders students in a student search. Empty fields match every student.
+type StudentFilter struct {
+	// Query holds words that must each appear in the student ID, a name or the email.
+	Query        string
+	Program      string
+	YearLevel    int
+	BlockSection string
+	DepartmentID int64
+	Status       string
+	Sort         string
+	Descending   bool
+	PageSize     int
+	// Cursor is the page to return; nil for the first page.
+	Cursor *StudentCursor
+}
+
+// StudentCursor marks a page of a student search by the student just before it
+// (or just after it, for Before), so pages stay stable while records are added.
+type StudentCursor struct {
+	Page       int    `json:"p"`
+	Sort       string `json:"s"`
+	Descending bool   `json:"d,omitempty"`
+	Keys       []any  `json:"k"`
+	StudentID  string `json:"id"`
+	Before     bool   `json:"b,omitempty"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string.
+func (c StudentCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeStudentCursor parses a cursor returned by StudentCursor.Encode.
+func DecodeStudentCursor(s string) (*StudentCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	cursor := &StudentCursor{}
+	if err := json.Unmarshal(data, cursor); err != nil || cursor.Page < 1 || cursor.StudentID == "" {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	for _, key := range cursor.Keys {
+		switch key.(type) {
+		case string, float64:
+		default:
+			return nil, fmt.Errorf("invalid cursor")
+		}
+	}
+	return cursor, nil
+}
+
+// PaginatedStudentResponse structures the paginated response for a student search.
+type PaginatedStudentResponse struct {
+	Data       []*Student         `json:"data"`
+	Pagination PaginationMetadata `json:"pagination"`
+}
+
+// -------------------------
+// Bulk import structs
+
+// Kinds of records that can be imported from a file, stored in ImportHistory.import_type.
+const (
+	ImportStudents    = "students"
+	ImportEnrollments = "enrollments"
+	ImportCards       = "cards"
+	ImportPayments    = "payments"
+)
+
+// ImportTable holds the rows read from an import file.
+type ImportTable struct {
+	// Columns lists the column names of the header row, lowercased.
+	Columns []string
+	Rows    []ImportRow
+}
+
+// ImportRow is a row of an import file, its values keyed by column name.
+type ImportRow struct {
+	// Line is the row's line (CSV) or row number (XLSX) in the file.
+	Line   int
+	Values map[string]string
+}
+
+// ImportRowError explains why a row of an import file cannot be imported.
+type ImportRowError struct {
+	Line    int    `json:"line"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportReport is the result of checking, and unless it is a dry run or a row has
+// errors, importing a file.
+type ImportReport struct {
+	ImportID  int64            `json:"import_id,omitempty"`
+	Type      string           `json:"type"`
+	FileName  string           `json:"file_name"`
+	DryRun    bool             `json:"dry_run"`
+	TotalRows int              `json:"total_rows"`
+	ValidRows int              `json:"valid_rows"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// AddError records an error in a row of the import file.
+func (r *ImportReport) AddError(line int, column, message string) {
+	r.Errors = append(r.Errors, ImportRowError{Line: line, Column: column, Message: message})
+}
+
+// ImportRecord is a committed import in the import history.
+type ImportRecord struct {
+	ID         int64     `json:"import_id" db:"import_id"`
+	Type       string    `json:"type" db:"import_type"`
+	FileName   string    `json:"file_name" db:"file_name"`
+	RowCount   int       `json:"row_count" db:"row_count"`
+	ImportedBy string    `json:"imported_by" db:"imported_by"`
+	ImportedAt time.Time `json:"imported_at" db:"imported_at"`
+}
@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"rfidsystem/internal/model"
+)
+
+// Academic Term Related Functions
+// ------------------------------------------------------------------
+
+// ErrTermOverlap is returned when saving an academic term whose dates overlap another term.
+var ErrTermOverlap = errors.New("academic term overlaps another term")
+
+// ErrTermInUse is returned when deleting an academic term that has enrollments or assessments.
+var ErrTermInUse = errors.New("academic term has enrollments or assessments")
+
+// ListAcademicTerms retrieves every academic term, the latest first.
+func (r *RFIDRepository) ListAcademicTerms() ([]model.AcademicTerm, error) {
+	rows, err := r.dbClient.DB.Query(`
+	SELECT term_id, academic_year, semester,
+		DATE_FORMAT(start_date, '%Y-%m-%d'), DATE_FORMAT(end_date, '%Y-%m-%d'), is_active
+	FROM AcademicTerms
+	ORDER BY start_date DESC, term_id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying academic terms: %v", err)
+	}
+	defer rows.Close()
+
+	terms := []model.AcademicTerm{}
+	for rows.Next() {
+		var term model.AcademicTerm
+		if err := rows.Scan(&term.ID, &term.AcademicYear, &term.Semester, &term.StartDate, &term.EndDate, &term.IsActive); err != nil {
+			return nil, fmt.Errorf("error scanning academic term row: %v", err)
+		}
+		terms = append(terms, term)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading academic term rows: %v", err)
+	}
+	return terms, nil
+}
+
+// SaveAcademicTerm inserts an academic term, or updates it if term.ID is set.
+// Dates are in YYYY-MM-DD. It returns ErrTermOverlap if the term's dates overlap
+// another term and ErrTermNotFound if the term to update does not exist.
+func (r *RFIDRepository) SaveAcademicTerm(term *model.AcademicTerm) error {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin academic term transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if term.ID != 0 {
+		err := tx.QueryRow(`SELECT is_active FROM AcademicTerms WHERE term_id = ? FOR UPDATE`, term.ID).Scan(&term.IsActive)
+		if err == sql.ErrNoRows {
+			return ErrTermNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("error querying academic term: %v", err)
+		}
+	}
+
+	var overlapping model.AcademicTerm
+	err = tx.QueryRow(`
+	SELECT term_id, academic_year, semester
+	FROM AcademicTerms
+	WHERE start_date <= ? AND end_date >= ? AND term_id <> ?
+	LIMIT 1
+	FOR UPDATE
+	`, term.EndDate, term.StartDate, term.ID).Scan(&overlapping.ID, &overlapping.AcademicYear, &overlapping.Semester)
+	if err == nil {
+		return fmt.Errorf("%w: %s %s (term %d)", ErrTermOverlap, overlapping.Semester, overlapping.AcademicYear, overlapping.ID)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("error checking overlapping terms: %v", err)
+	}
+
+	if term.ID == 0 {
+		res, err := tx.Exec(`
+		INSERT INTO AcademicTerms (academic_year, semester, start_date, end_date)
+		VALUES (?, ?, ?, ?)
+		`, term.AcademicYear, term.Semester, term.StartDate, term.EndDate)
+		if err != nil {
+			return fmt.Errorf("error inserting academic term: %v", err)
+		}
+		if term.ID, err = res.LastInsertId(); err != nil {
+			return fmt.Errorf("error reading academic term id: %v", err)
+		}
+	} else {
+		_, err := tx.Exec(`
+		UPDATE AcademicTerms
+		SET academic_year = ?, semester = ?, start_date = ?, end_date = ?
+		WHERE term_id = ?
+		`, term.AcademicYear, term.Semester, term.StartDate, term.EndDate, term.ID)
+		if err != nil {
+			return fmt.Errorf("error updating academic term: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit academic term transaction: %v", err)
+	}
+	return nil
+}
+
+// DeleteAcademicTerm deletes an academic term. It returns ErrTermInUse if the term
+// has enrollments or assessments, and false if the term does not exist.
+func (r *RFIDRepository) DeleteAcademicTerm(termID int64) (bool, error) {
+	var inUse bool
+	err := r.dbClient.DB.QueryRow(`
+	SELECT EXISTS(SELECT 1 FROM Enrollments WHERE term_id = ?)
+		OR EXISTS(SELECT 1 FROM Assessment WHERE term_id = ?)
+	`, termID, termID).Scan(&inUse)
+	if err != nil {
+		return false, fmt.Errorf("error checking academic term usage: %v", err)
+	}
+	if inUse {
+		return false, ErrTermInUse
+	}
+
+	res, err := r.dbClient.DB.Exec(`DELETE FROM AcademicTerms WHERE term_id = ?`, termID)
+	if err != nil {
+		return false, fmt.Errorf("error deleting academic term: %v", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error reading deleted academic terms: %v", err)
+	}
+	return n > 0, nil
+}
+
+// SetActiveTerm makes the given term the current term regardless of its dates,
+// replacing the previous active term. A termID of 0 clears the active term, so
+// the current term follows the term dates again. It returns false if the term
+// does not exist.
+func (r *RFIDRepository) SetActiveTerm(termID int64) (bool, error) {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("begin active term transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if termID != 0 {
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM AcademicTerms WHERE term_id = ?)`, termID).Scan(&exists); err != nil {
+			return false, fmt.Errorf("error checking academic term: %v", err)
+		}
+		if !exists {
+			return false, nil
+		}
+	}
+	if _, err := tx.Exec(`UPDATE AcademicTerms SET is_active = (term_id = ?)`, termID); err != nil {
+		return false, fmt.Errorf("error setting active term: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit active term transaction: %v", err)
+	}
+	return true, nil
+}
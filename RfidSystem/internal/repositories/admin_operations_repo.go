@@ -1,72 +1,11 @@
 package repositories
 
 import (
-	"database/sql"
 	"fmt"
 	"log"
 	"rfidsystem/internal/model"
 )
 
-// DEPRECATED
-
-// GetAllStudents retrieves a paginated list of all students from the database.
-// It returns a slice of Student pointers and an error if the query fails.
-func (r *RFIDRepository) GetAllStudents(page int) ([]*model.Student, error) {
-	var students []*model.Student
-	limit := 5
-	offset := (page - 1) * limit
-
-	query := `
-		SELECT student_ID, department_ID, first_Name, last_Name, middle_Name, birthday, contact_number, email, year_Level, program, block_section, first_access_timestamp, last_access_timestamp
-		FROM Students ORDER BY last_Name ASC LIMIT ? OFFSET ?;
-	`
-
-	log.Print(query)
-
-	rows, err := r.dbClient.DB.Query(query, limit, offset)
-	if err != nil {
-		log.Printf("Error querying students: %v\n", err)
-		return nil, fmt.Errorf("error querying students: %v", err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		student := &model.Student{}
-		err := rows.Scan(
-			&student.StudentID,
-			&student.DepartmentID,
-			&student.FirstName,
-			&student.LastName,
-			&student.MiddleName,
-			&student.Birthday,
-			&student.ContactNumber,
-			&student.Email,
-			&student.YearLevel,
-			&student.Program,
-			&student.BlockSection,
-			&student.FirstAccessTimestamp,
-			&student.LastAccessTimestamp,
-		)
-		if err != nil {
-			log.Printf("Error scanning student row: %v\n", err)
-			return nil, fmt.Errorf("error scanning student row: %v", err)
-		}
-		students = append(students, student)
-	}
-
-	if err = rows.Err(); err != nil {
-		log.Printf("Error after scanning rows: %v\n", err)
-		return nil, fmt.Errorf("error after scanning rows: %v", err)
-	}
-
-	if len(students) == 0 {
-		log.Println("No students found in the database")
-		return nil, nil
-	}
-
-	return students, nil
-}
-
 // GetStudentsForAssessmentTerm retrieves a paginated list of students for a specific assessment term.
 // It returns a slice of StudentAssessmentSummary pointers, the total count of students for the term, and an error.
 //
@@ -151,71 +90,3 @@ func (r *RFIDRepository) GetStudentsForAssessmentTerm(termID int64, page, limit
 
 	return students, totalStudents, nil
 }
-
-// Deprecated
-func (r *RFIDRepository) GetStudentGradesByID(studentId string) (*model.GradesRecord, error) {
-	// grades := &model.GradesRecord{}
-
-	query := "CALL GetStudent(?)"
-
-	rows, err := r.dbClient.DB.Query(query, studentId)
-	if err != nil {
-		log.Printf("Error querying student grades: %v\n", err)
-		return nil, fmt.Errorf("error querying student grades: %v", err)
-	}
-
-	defer rows.Close()
-
-	for rows.Next() {
-
-	}
-
-	return nil, nil
-}
-
-// GetStudentInfo retrieves detailed information for a specific student by their ID.
-// It queries the Students table and returns a Student pointer or an error.
-// It returns sql.ErrNoRows if no student is found.
-func (r *RFIDRepository) GetStudentInfo(studentID string) (*model.Student, error) {
-	student := &model.Student{}
-
-	query := `
-		SELECT
-			student_ID, department_ID, first_Name, last_Name, middle_Name,
-			birthday, contact_number, email, year_Level, program, block_section,
-			first_access_timestamp, last_access_timestamp, status
-		FROM Students
-		WHERE student_ID = ?;
-	`
-
-	log.Printf("Executing query: %s with student_id: %s\n", query, studentID) // Log string ID
-
-	row := r.dbClient.DB.QueryRow(query, studentID) // Pass string ID
-	err := row.Scan(
-		&student.StudentID,
-		&student.DepartmentID,
-		&student.FirstName,
-		&student.LastName,
-		&student.MiddleName,
-		&student.Birthday,
-		&student.ContactNumber,
-		&student.Email,
-		&student.YearLevel,
-		&student.Program,
-		&student.BlockSection,
-		&student.FirstAccessTimestamp,
-		&student.LastAccessTimestamp,
-		&student.Status,
-	)
-
-	if err != nil {
-		if err == sql.ErrNoRows {
-			log.Printf("No student found with ID: %s\n", studentID) // Log string ID
-			return nil, fmt.Errorf("student not found")             // Return a specific error for not found
-		}
-		log.Printf("Error scanning student row for ID %s: %v\n", studentID, err) // Log string ID
-		return nil, fmt.Errorf("error scanning student row: %v", err)
-	}
-
-	return student, nil
-}
@@ -0,0 +1,419 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/services"
+	"time"
+)
+
+// Assessment Generation Related Functions
+// ------------------------------------------------------------------
+
+// ErrTermNotFound is returned when the academic term of a request does not exist.
+var ErrTermNotFound = errors.New("academic term not found")
+
+// ErrAssessmentExists is returned when the student already has an assessment
+// for the term and it was not asked to be replaced.
+var ErrAssessmentExists = errors.New("the student already has an assessment for this term")
+
+// ErrAssessmentHasPayments is returned when replacing an assessment that already has payments.
+var ErrAssessmentHasPayments = errors.New("the assessment already has payments and cannot be regenerated")
+
+// ErrNoEnrolledSubjects is returned when the student is not enrolled in any subject for the term.
+var ErrNoEnrolledSubjects = errors.New("the student is not enrolled in any subject for this term")
+
+// ErrFeeTypeNotFound is returned when saving a fee schedule for a fee type that does not exist.
+var ErrFeeTypeNotFound = errors.New("fee type not found")
+
+// ErrFeeScheduleNotFound is returned when updating a fee schedule row that does not exist.
+var ErrFeeScheduleNotFound = errors.New("fee schedule not found")
+
+// ErrNoFeeSchedule is returned when no fee schedule applies to the student's term and program.
+var ErrNoFeeSchedule = errors.New("no fee schedule applies to this term and program")
+
+// ListFeeSchedules retrieves every fee schedule row with its fee type.
+func (r *RFIDRepository) ListFeeSchedules() ([]model.FeeScheduleItem, error) {
+	rows, err := r.dbClient.DB.Query(`
+	SELECT fs.fee_schedule_id, fs.fee_type_id, ft.name, ft.category, fs.term_id, fs.program, fs.amount, fs.per_unit
+	FROM FeeSchedules fs
+	JOIN FeeTypes ft ON ft.fee_type_id = fs.fee_type_id
+	ORDER BY ft.category, ft.name, fs.term_id, fs.program
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying fee schedules: %v", err)
+	}
+	return scanFeeSchedules(rows)
+}
+
+// SaveFeeSchedule creates a fee schedule row, or updates it if its ID is set.
+// Assessments that were already generated are not changed. It returns
+// ErrFeeScheduleNotFound if the row to update does not exist.
+func (r *RFIDRepository) SaveFeeSchedule(item *model.FeeScheduleItem) error {
+	err := r.dbClient.DB.QueryRow(`SELECT name, category FROM FeeTypes WHERE fee_type_id = ?`, item.FeeTypeID).Scan(&item.Name, &item.Category)
+	if err == sql.ErrNoRows {
+		return ErrFeeTypeNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("error querying fee type: %v", err)
+	}
+
+	if item.ID == 0 {
+		res, err := r.dbClient.DB.Exec(`
+		INSERT INTO FeeSchedules (fee_type_id, term_id, program, amount, per_unit)
+		VALUES (?, ?, ?, ?, ?)
+		`, item.FeeTypeID, item.TermID, item.Program, item.Amount, item.PerUnit)
+		if err != nil {
+			return fmt.Errorf("error inserting fee schedule: %v", err)
+		}
+		item.ID, err = res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("error reading fee schedule id: %v", err)
+		}
+		return nil
+	}
+
+	res, err := r.dbClient.DB.Exec(`
+	UPDATE FeeSchedules
+	SET fee_type_id = ?, term_id = ?, program = ?, amount = ?, per_unit = ?
+	WHERE fee_schedule_id = ?
+	`, item.FeeTypeID, item.TermID, item.Program, item.Amount, item.PerUnit, item.ID)
+	if err != nil {
+		return fmt.Errorf("error updating fee schedule: %v", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		var exists bool
+		if err := r.dbClient.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM FeeSchedules WHERE fee_schedule_id = ?)`, item.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("error checking fee schedule: %v", err)
+		}
+		if !exists {
+			return ErrFeeScheduleNotFound
+		}
+	}
+	return nil
+}
+
+// GenerateAssessment computes a student's assessment for a term from the subjects
+// they are enrolled in, the fee schedule and the requested discounts, and stores
+// it with its fees, discounts and payment schedule in a single transaction.
+// Discounts are recorded in the discount audit trail. A dry run computes the
+// assessment without storing it. It returns nil if the student does not exist.
+func (r *RFIDRepository) GenerateAssessment(req model.AssessmentRequest, policy config.AssessmentConfig, windows []config.ExamWindow) (*model.GeneratedAssessment, error) {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin assessment transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the student row so the same assessment is not generated twice concurrently
+	var program *string
+	err = tx.QueryRow(`SELECT program FROM Students WHERE student_ID = ? FOR UPDATE`, req.StudentID).Scan(&program)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying student: %v", err)
+	}
+
+	input := model.AssessmentInput{}
+	err = tx.QueryRow(`SELECT start_date, end_date FROM AcademicTerms WHERE term_id = ?`, req.TermID).Scan(&input.TermStart, &input.TermEnd)
+	if err == sql.ErrNoRows {
+		return nil, ErrTermNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying academic term: %v", err)
+	}
+
+	// Lock the student's current assessment of the term, if any, so it is not paid meanwhile
+	var existing *model.Assessment
+	var existingNumber int64
+	err = tx.QueryRow(`
+	SELECT assessment_Number FROM Assessment WHERE student_ID = ? AND term_id = ? ORDER BY assessment_Number DESC LIMIT 1
+	`, req.StudentID, req.TermID).Scan(&existingNumber)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying existing assessment: %v", err)
+	}
+	if err == nil {
+		if !req.Replace {
+			return nil, ErrAssessmentExists
+		}
+		if existing, err = lockAssessment(tx, existingNumber); err != nil {
+			return nil, err
+		}
+		var payments int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM Payments WHERE assessment_number = ?`, existingNumber).Scan(&payments); err != nil {
+			return nil, fmt.Errorf("error checking assessment payments: %v", err)
+		}
+		if payments > 0 || (existing != nil && existing.TotalPaymentAmount > 0) {
+			return nil, ErrAssessmentHasPayments
+		}
+	}
+
+	if input.Subjects, err = enrolledSubjects(tx, req.StudentID, req.TermID); err != nil {
+		return nil, err
+	}
+	if len(input.Subjects) == 0 {
+		return nil, ErrNoEnrolledSubjects
+	}
+	if input.Fees, err = applicableFeeSchedules(tx, req.TermID, program); err != nil {
+		return nil, err
+	}
+	if len(input.Fees) == 0 {
+		return nil, ErrNoFeeSchedule
+	}
+	discountNames := make(map[int64]string)
+	for _, id := range req.DiscountTypeIDs {
+		if _, ok := discountNames[id]; ok {
+			continue
+		}
+		dt, err := activeDiscountType(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		discountNames[id] = dt.Name
+		input.DiscountTypes = append(input.DiscountTypes, *dt)
+	}
+
+	generated := services.ComputeAssessment(input, policy, windows)
+	studentID, termID := req.StudentID, req.TermID
+	generated.Assessment.StudentID = &studentID
+	generated.Assessment.TermID = &termID
+	if req.DryRun {
+		generated.DryRun = true
+		if existing != nil {
+			generated.Assessment.ID = existing.ID
+		}
+		return &generated, nil
+	}
+
+	if existing != nil {
+		generated.Assessment.ID = existing.ID
+		if err := clearAssessment(tx, existing.ID, req.GeneratedBy); err != nil {
+			return nil, err
+		}
+	}
+	if err := saveGeneratedAssessment(tx, &generated, discountNames, req.GeneratedBy); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit assessment transaction: %v", err)
+	}
+	return &generated, nil
+}
+
+// enrolledSubjects retrieves the subjects a student is enrolled in for a term, with their units.
+func enrolledSubjects(tx *sql.Tx, studentID string, termID int64) ([]model.EnrolledSubject, error) {
+	rows, err := tx.Query(`
+	SELECT e.subject_Code, s.subject_name, s.units
+	FROM Enrollments e
+	JOIN Subjects s ON e.subject_Code = s.subject_Code
+	WHERE e.student_ID = ? AND e.term_id = ?
+	ORDER BY e.subject_Code
+	`, studentID, termID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying enrolled subjects: %v", err)
+	}
+	defer rows.Close()
+
+	var subjects []model.EnrolledSubject
+	for rows.Next() {
+		var subject model.EnrolledSubject
+		if err := rows.Scan(&subject.SubjectCode, &subject.SubjectName, &subject.Units); err != nil {
+			return nil, fmt.Errorf("error scanning enrolled subject row: %v", err)
+		}
+		subjects = append(subjects, subject)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading enrolled subject rows: %v", err)
+	}
+	return subjects, nil
+}
+
+// applicableFeeSchedules retrieves the fee schedule of a term and program. When
+// several rows of a fee type apply, the one specific to the term wins over the
+// one specific to the program, which wins over the general one.
+func applicableFeeSchedules(tx *sql.Tx, termID int64, program *string) ([]model.FeeScheduleItem, error) {
+	rows, err := tx.Query(`
+	SELECT fs.fee_schedule_id, fs.fee_type_id, ft.name, ft.category, fs.term_id, fs.program, fs.amount, fs.per_unit
+	FROM FeeSchedules fs
+	JOIN FeeTypes ft ON ft.fee_type_id = fs.fee_type_id
+	WHERE (fs.term_id IS NULL OR fs.term_id = ?)
+		AND (fs.program IS NULL OR fs.program = ?)
+	ORDER BY ft.category, ft.name
+	`, termID, program)
+	if err != nil {
+		return nil, fmt.Errorf("error querying fee schedules: %v", err)
+	}
+	items, err := scanFeeSchedules(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	specificity := func(item model.FeeScheduleItem) int {
+		score := 0
+		if item.TermID != nil {
+			score += 2
+		}
+		if item.Program != nil {
+			score++
+		}
+		return score
+	}
+	index := make(map[int64]int)
+	var fees []model.FeeScheduleItem
+	for _, item := range items {
+		i, ok := index[item.FeeTypeID]
+		if !ok {
+			index[item.FeeTypeID] = len(fees)
+			fees = append(fees, item)
+			continue
+		}
+		if specificity(item) > specificity(fees[i]) {
+			fees[i] = item
+		}
+	}
+	return fees, nil
+}
+
+func scanFeeSchedules(rows *sql.Rows) ([]model.FeeScheduleItem, error) {
+	defer rows.Close()
+
+	items := []model.FeeScheduleItem{}
+	for rows.Next() {
+		var item model.FeeScheduleItem
+		if err := rows.Scan(&item.ID, &item.FeeTypeID, &item.Name, &item.Category, &item.TermID, &item.Program,
+			&item.Amount, &item.PerUnit); err != nil {
+			return nil, fmt.Errorf("error scanning fee schedule row: %v", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading fee schedule rows: %v", err)
+	}
+	return items, nil
+}
+
+// clearAssessment removes the fees, discounts and payment schedule of an assessment
+// that is being regenerated. Its discounts are recorded as revoked in the audit trail.
+func clearAssessment(tx *sql.Tx, assessmentNumber int64, performedBy string) error {
+	rows, err := tx.Query(`
+	SELECT ad.assessment_discount_id, ad.assessment_number, ad.discount_type_id, ad.applied_amount, ad.calculation_basis,
+		COALESCE(dt.name, '')
+	FROM AssessmentDiscounts ad
+	LEFT JOIN DiscountTypes dt ON dt.discount_type_id = ad.discount_type_id
+	WHERE ad.assessment_number = ?
+	`, assessmentNumber)
+	if err != nil {
+		return fmt.Errorf("error querying assessment discounts: %v", err)
+	}
+	var discounts []model.AssessmentDiscount
+	var names []string
+	for rows.Next() {
+		var discount model.AssessmentDiscount
+		var name string
+		if err := rows.Scan(&discount.ID, &discount.AssessmentNumber, &discount.DiscountTypeID, &discount.AppliedAmount,
+			&discount.CalculationBasis, &name); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning assessment discount row: %v", err)
+		}
+		discounts = append(discounts, discount)
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading assessment discount rows: %v", err)
+	}
+
+	reason := "Assessment regenerated"
+	for i, discount := range discounts {
+		if _, err := auditDiscount(tx, discount, names[i], model.DiscountRevoked, performedBy, &reason); err != nil {
+			return err
+		}
+	}
+	for _, table := range []string{"AssessmentFees", "AssessmentDiscounts", "PaymentSchedule"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE assessment_number = ?`, assessmentNumber); err != nil {
+			return fmt.Errorf("error clearing %s: %v", table, err)
+		}
+	}
+	return nil
+}
+
+// saveGeneratedAssessment stores a generated assessment with its fees, discounts
+// and payment schedule, updating the assessment row if its ID is set.
+func saveGeneratedAssessment(tx *sql.Tx, generated *model.GeneratedAssessment, discountNames map[int64]string, generatedBy string) error {
+	a := &generated.Assessment
+	now := time.Now()
+	if a.ID == 0 {
+		res, err := tx.Exec(`
+		INSERT INTO Assessment (student_ID, term_id, total_fee_amount, total_discount_amount, net_assessment_amount,
+			initial_Payment, total_payment_amount, full_pmt_if_b4_prelim, remaining_Balance, per_Exam_Fee, generated_by, generated_at)
+		VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?, ?, ?)
+		`, a.StudentID, a.TermID, a.TotalFeeAmount, a.TotalDiscountAmount, a.NetAssessmentAmount,
+			a.InitialPayment, a.FullPmtIfB4Prelim, a.RemainingBalance, a.PerExamFee, generatedBy, now)
+		if err != nil {
+			return fmt.Errorf("error inserting assessment: %v", err)
+		}
+		if a.ID, err = res.LastInsertId(); err != nil {
+			return fmt.Errorf("error reading assessment number: %v", err)
+		}
+	} else {
+		_, err := tx.Exec(`
+		UPDATE Assessment
+		SET total_fee_amount = ?, total_discount_amount = ?, net_assessment_amount = ?, initial_Payment = ?,
+			total_payment_amount = 0, full_pmt_if_b4_prelim = ?, remaining_Balance = ?, per_Exam_Fee = ?,
+			generated_by = ?, generated_at = ?
+		WHERE assessment_Number = ?
+		`, a.TotalFeeAmount, a.TotalDiscountAmount, a.NetAssessmentAmount, a.InitialPayment,
+			a.FullPmtIfB4Prelim, a.RemainingBalance, a.PerExamFee, generatedBy, now, a.ID)
+		if err != nil {
+			return fmt.Errorf("error updating assessment: %v", err)
+		}
+	}
+
+	for _, fee := range generated.Fees {
+		if _, err := tx.Exec(`
+		INSERT INTO AssessmentFees (assessment_number, fee_type_id, amount) VALUES (?, ?, ?)
+		`, a.ID, fee.FeeTypeID, fee.Amount); err != nil {
+			return fmt.Errorf("error inserting assessment fee: %v", err)
+		}
+	}
+
+	reason := "Granted when the assessment was generated"
+	for i := range generated.Discounts {
+		discount := &generated.Discounts[i]
+		discount.AssessmentNumber = a.ID
+		res, err := tx.Exec(`
+		INSERT INTO AssessmentDiscounts (assessment_number, discount_type_id, applied_amount, calculation_basis)
+		VALUES (?, ?, ?, ?)
+		`, discount.AssessmentNumber, discount.DiscountTypeID, discount.AppliedAmount, discount.CalculationBasis)
+		if err != nil {
+			return fmt.Errorf("error inserting assessment discount: %v", err)
+		}
+		if discount.ID, err = res.LastInsertId(); err != nil {
+			return fmt.Errorf("error reading assessment discount id: %v", err)
+		}
+		if _, err := auditDiscount(tx, *discount, discountNames[discount.DiscountTypeID], model.DiscountGranted, generatedBy, &reason); err != nil {
+			return err
+		}
+	}
+
+	for i := range generated.PaymentSchedules {
+		schedule := &generated.PaymentSchedules[i]
+		schedule.AssessmentNumber = a.ID
+		res, err := tx.Exec(`
+		INSERT INTO PaymentSchedule (assessment_number, term_description, due_date, expected_amount, sort_order)
+		VALUES (?, ?, ?, ?, ?)
+		`, schedule.AssessmentNumber, schedule.TermDescription, schedule.DueDate, schedule.ExpectedAmount, schedule.SortOrder)
+		if err != nil {
+			return fmt.Errorf("error inserting payment schedule: %v", err)
+		}
+		if schedule.ID, err = res.LastInsertId(); err != nil {
+			return fmt.Errorf("error reading payment schedule id: %v", err)
+		}
+	}
+	return nil
+}
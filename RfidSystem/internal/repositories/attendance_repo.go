@@ -0,0 +1,252 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"time"
+)
+
+// Attendance Related Functions
+// ------------------------------------------------------------------
+
+// ErrAttendanceTooSoon is returned when a student is scanned at a gate again
+// within the minimum interval, e.g. because the reader read the card twice.
+var ErrAttendanceTooSoon = errors.New("student was scanned at a gate moments ago")
+
+// RecordAttendance records an IN/OUT event for a student scanned at a gate reader
+// and updates the student's presence state in the same transaction.
+// If direction is empty the student's presence is toggled; a student whose last
+// event was on an earlier day always starts the day with an IN event.
+// A scan less than minInterval after the student's last event is not recorded
+// and returns ErrAttendanceTooSoon.
+func (r *RFIDRepository) RecordAttendance(studentID, cardUID, readerID, direction string, minInterval time.Duration) (*model.AttendanceEvent, error) {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin attendance transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the student row so that concurrent scans of the same student are
+	// serialized, including the first one, before any presence row exists.
+	var lockedID string
+	err = tx.QueryRow("SELECT student_ID FROM Students WHERE student_ID = ? FOR UPDATE", studentID).Scan(&lockedID)
+	if err == sql.ErrNoRows {
+		return nil, ErrStudentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error locking student: %v", err)
+	}
+
+	var isPresent bool
+	var lastEvent time.Time
+	err = tx.QueryRow(`
+	SELECT is_present, last_event_time
+	FROM AttendancePresence
+	WHERE student_ID = ?
+	FOR UPDATE
+	`, studentID).Scan(&isPresent, &lastEvent)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying presence: %v", err)
+	}
+	hasPresence := err == nil
+
+	now := time.Now()
+	if hasPresence && now.Sub(lastEvent) < minInterval {
+		return nil, ErrAttendanceTooSoon
+	}
+	if direction == "" {
+		direction = model.AttendanceIn
+		if hasPresence && isPresent && sameDay(lastEvent, now) {
+			direction = model.AttendanceOut
+		}
+	}
+
+	res, err := tx.Exec(`
+	INSERT INTO AttendanceEvents (student_ID, card_uid, reader_id, direction, event_time)
+	VALUES (?, ?, ?, ?, ?)
+	`, studentID, cardUID, readerID, direction, now)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting attendance event: %v", err)
+	}
+	eventID, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error reading attendance event id: %v", err)
+	}
+
+	if _, err := tx.Exec(`
+	INSERT INTO AttendancePresence (student_ID, is_present, last_event_time, last_reader_id)
+	VALUES (?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE is_present = VALUES(is_present), last_event_time = VALUES(last_event_time), last_reader_id = VALUES(last_reader_id)
+	`, studentID, direction == model.AttendanceIn, now, readerID); err != nil {
+		return nil, fmt.Errorf("error updating presence: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit attendance transaction: %v", err)
+	}
+
+	log.Printf("Recorded attendance %s for student %s at reader %s", direction, studentID, readerID)
+	return &model.AttendanceEvent{
+		ID:        eventID,
+		StudentID: studentID,
+		CardUID:   cardUID,
+		ReaderID:  readerID,
+		Direction: direction,
+		EventTime: now,
+	}, nil
+}
+
+// GetStudentAttendance retrieves all attendance events of a student on the given day.
+func (r *RFIDRepository) GetStudentAttendance(studentID string, day time.Time) ([]model.AttendanceEvent, error) {
+	start, end := dayBounds(day)
+	rows, err := r.dbClient.DB.Query(`
+	SELECT attendance_id, student_ID, card_uid, reader_id, direction, event_time
+	FROM AttendanceEvents
+	WHERE student_ID = ? AND event_time >= ? AND event_time < ?
+	ORDER BY event_time
+	`, studentID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error querying attendance events: %v", err)
+	}
+	defer rows.Close()
+
+	events := []model.AttendanceEvent{}
+	for rows.Next() {
+		var event model.AttendanceEvent
+		if err := rows.Scan(&event.ID, &event.StudentID, &event.CardUID, &event.ReaderID, &event.Direction, &event.EventTime); err != nil {
+			return nil, fmt.Errorf("error scanning attendance event: %v", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading attendance events: %v", err)
+	}
+
+	return events, nil
+}
+
+// GetDailyAttendanceByStudent summarizes the attendance of every student who
+// scanned at a gate on the given day.
+func (r *RFIDRepository) GetDailyAttendanceByStudent(day time.Time) ([]model.StudentAttendanceSummary, error) {
+	start, end := dayBounds(day)
+	rows, err := r.dbClient.DB.Query(`
+	SELECT
+		s.student_ID,
+		CONCAT(COALESCE(s.first_Name, ''), ' ', COALESCE(s.last_Name, '')) AS name,
+		s.program,
+		s.block_section,
+		MIN(CASE WHEN ae.direction = 'IN' THEN ae.event_time END) AS first_in,
+		MAX(CASE WHEN ae.direction = 'OUT' THEN ae.event_time END) AS last_out,
+		COUNT(*) AS event_count,
+		COALESCE(MAX(ap.is_present AND ap.last_event_time >= ?), FALSE) AS is_present
+	FROM AttendanceEvents ae
+	JOIN Students s ON s.student_ID = ae.student_ID
+	LEFT JOIN AttendancePresence ap ON ap.student_ID = ae.student_ID
+	WHERE ae.event_time >= ? AND ae.event_time < ?
+	GROUP BY s.student_ID, s.first_Name, s.last_Name, s.program, s.block_section
+	ORDER BY s.last_Name, s.first_Name
+	`, start, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error querying daily attendance: %v", err)
+	}
+	defer rows.Close()
+
+	summaries := []model.StudentAttendanceSummary{}
+	for rows.Next() {
+		var summary model.StudentAttendanceSummary
+		var firstIn, lastOut sql.NullTime
+		if err := rows.Scan(
+			&summary.StudentID,
+			&summary.Name,
+			&summary.Program,
+			&summary.BlockSection,
+			&firstIn,
+			&lastOut,
+			&summary.EventCount,
+			&summary.IsPresent,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning daily attendance row: %v", err)
+		}
+		if firstIn.Valid {
+			summary.FirstIn = &firstIn.Time
+		}
+		if lastOut.Valid {
+			summary.LastOut = &lastOut.Time
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading daily attendance rows: %v", err)
+	}
+
+	return summaries, nil
+}
+
+// attendanceGroupColumns whitelists the Students columns a daily report can be grouped by.
+var attendanceGroupColumns = map[string]string{
+	"block_section": "s.block_section",
+	"program":       "s.program",
+}
+
+// GetDailyAttendanceByGroup summarizes attendance on the given day per block section
+// or program. groupBy must be "block_section" or "program".
+func (r *RFIDRepository) GetDailyAttendanceByGroup(day time.Time, groupBy string) ([]model.GroupAttendanceSummary, error) {
+	column, ok := attendanceGroupColumns[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("invalid attendance grouping: %s", groupBy)
+	}
+
+	start, end := dayBounds(day)
+	query := fmt.Sprintf(`
+	SELECT
+		COALESCE(%[1]s, 'Unassigned') AS grp,
+		COUNT(*) AS total_students,
+		COUNT(att.student_ID) AS attended_count,
+		COALESCE(SUM(ap.is_present AND ap.last_event_time >= ?), 0) AS present_now_count
+	FROM Students s
+	LEFT JOIN (
+		SELECT DISTINCT student_ID
+		FROM AttendanceEvents
+		WHERE event_time >= ? AND event_time < ?
+	) att ON att.student_ID = s.student_ID
+	LEFT JOIN AttendancePresence ap ON ap.student_ID = s.student_ID
+	GROUP BY grp
+	ORDER BY grp
+	`, column)
+
+	rows, err := r.dbClient.DB.Query(query, start, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error querying attendance by %s: %v", groupBy, err)
+	}
+	defer rows.Close()
+
+	summaries := []model.GroupAttendanceSummary{}
+	for rows.Next() {
+		var summary model.GroupAttendanceSummary
+		if err := rows.Scan(&summary.Group, &summary.TotalStudents, &summary.AttendedCount, &summary.PresentNowCount); err != nil {
+			return nil, fmt.Errorf("error scanning attendance group row: %v", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading attendance group rows: %v", err)
+	}
+
+	return summaries, nil
+}
+
+// dayBounds returns the start of the given day and the start of the following day.
+func dayBounds(day time.Time) (time.Time, time.Time) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	return start, start.AddDate(0, 0, 1)
+}
+
+// sameDay reports whether a and b fall on the same calendar day.
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.In(a.Location()).Date()
+	return ay == by && am == bm && ad == bd
+}
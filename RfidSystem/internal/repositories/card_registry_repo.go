@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"time"
+)
+
+// Card Registry Related Functions
+// ------------------------------------------------------------------
+
+// ErrActiveCardExists is returned when reinstating a card for a student who
+// has since been issued a replacement.
+var ErrActiveCardExists = errors.New("student already has an active card")
+
+// ErrCardNotBlocked is returned when reinstating a card that was never reported lost or stolen.
+var ErrCardNotBlocked = errors.New("card is not reported lost or stolen")
+
+// GetCardByUID retrieves a registered card by its physical RFID UID, with the
+// status of the student who holds it. It returns nil if the card is not registered.
+func (r *RFIDRepository) GetCardByUID(cardUID string) (*model.StudentCard, error) {
+	query := `
+	SELECT sc.card_uid, sc.student_ID, sc.issued_date, sc.revoked_date, sc.card_state, COALESCE(s.status, '')
+	FROM StudentCards sc
+	LEFT JOIN Students s ON s.student_ID = sc.student_ID
+	WHERE sc.card_uid = ?
+	`
+
+	card := &model.StudentCard{}
+	var revokedDate sql.NullTime
+	err := r.dbClient.DB.QueryRow(query, cardUID).Scan(
+		&card.CardUID,
+		&card.StudentID,
+		&card.IssuedDate,
+		&revokedDate,
+		&card.State,
+		&card.StudentStatus,
+	)
+	if err == sql.ErrNoRows {
+		log.Printf("No card registered with UID: %s\n", cardUID)
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Error querying card %s: %v\n", cardUID, err)
+		return nil, fmt.Errorf("error querying card: %v", err)
+	}
+	if revokedDate.Valid {
+		card.RevokedDate = &revokedDate.Time
+	}
+
+	return card, nil
+}
+
+// GetCardsByStudentID retrieves every card ever issued to a student, newest first.
+func (r *RFIDRepository) GetCardsByStudentID(studentID string) ([]model.StudentCard, error) {
+	query := `
+	SELECT card_uid, student_ID, issued_date, revoked_date, card_state
+	FROM StudentCards
+	WHERE student_ID = ?
+	ORDER BY issued_date DESC
+	`
+
+	rows, err := r.dbClient.DB.Query(query, studentID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying cards: %v", err)
+	}
+	defer rows.Close()
+
+	cards := []model.StudentCard{}
+	for rows.Next() {
+		var card model.StudentCard
+		var revokedDate sql.NullTime
+		if err := rows.Scan(&card.CardUID, &card.StudentID, &card.IssuedDate, &revokedDate, &card.State); err != nil {
+			return nil, fmt.Errorf("error scanning card row: %v", err)
+		}
+		if revokedDate.Valid {
+			card.RevokedDate = &revokedDate.Time
+		}
+		cards = append(cards, card)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading card rows: %v", err)
+	}
+
+	return cards, nil
+}
+
+// IssueCard registers a new card for a student. Any card the student currently
+// holds is marked as replaced in the same transaction, so a student only ever
+// has one active card.
+func (r *RFIDRepository) IssueCard(cardUID, studentID string) (*model.StudentCard, error) {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin issue card transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	card, err := issueCard(tx, cardUID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit issue card transaction: %v", err)
+	}
+	return card, nil
+}
+
+// issueCard registers a new card for a student inside tx, as IssueCard.
+func issueCard(tx *sql.Tx, cardUID, studentID string) (*model.StudentCard, error) {
+	var exists int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM Students WHERE student_ID = ?`, studentID).Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("error checking student: %v", err)
+	}
+	if exists == 0 {
+		return nil, ErrStudentNotFound
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`
+	UPDATE StudentCards
+	SET card_state = ?, revoked_date = ?
+	WHERE student_ID = ? AND card_state = ?
+	`, model.CardStateReplaced, now, studentID, model.CardStateActive); err != nil {
+		return nil, fmt.Errorf("error replacing previous cards: %v", err)
+	}
+
+	if _, err := tx.Exec(`
+	INSERT INTO StudentCards (card_uid, student_ID, issued_date, card_state)
+	VALUES (?, ?, ?, ?)
+	`, cardUID, studentID, now, model.CardStateActive); err != nil {
+		return nil, fmt.Errorf("error inserting card: %v", err)
+	}
+
+	return &model.StudentCard{
+		CardUID:    cardUID,
+		StudentID:  studentID,
+		IssuedDate: now,
+		State:      model.CardStateActive,
+	}, nil
+}
+
+// BlockCard marks a card as lost or stolen so scans no longer display the
+// holder's records. It returns the updated card, or nil if the card is not registered.
+func (r *RFIDRepository) BlockCard(cardUID, state string) (*model.StudentCard, error) {
+	if state != model.CardStateLost && state != model.CardStateStolen {
+		return nil, fmt.Errorf("invalid block state: %s", state)
+	}
+
+	res, err := r.dbClient.DB.Exec(`
+	UPDATE StudentCards
+	SET card_state = ?, revoked_date = ?
+	WHERE card_uid = ? AND card_state IN (?, ?, ?)
+	`, state, time.Now(), cardUID, model.CardStateActive, model.CardStateLost, model.CardStateStolen)
+	if err != nil {
+		return nil, fmt.Errorf("error blocking card: %v", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		log.Printf("Card %s was not blocked (unknown or not active)", cardUID)
+	}
+
+	return r.GetCardByUID(cardUID)
+}
+
+// ReinstateCard reactivates a card that was reported lost or stolen.
+// It fails if the student has since been issued another active card.
+func (r *RFIDRepository) ReinstateCard(cardUID string) (*model.StudentCard, error) {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin reinstate card transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var studentID, state string
+	err = tx.QueryRow(`SELECT student_ID, card_state FROM StudentCards WHERE card_uid = ? FOR UPDATE`, cardUID).Scan(&studentID, &state)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying card: %v", err)
+	}
+	if state != model.CardStateLost && state != model.CardStateStolen {
+		return nil, ErrCardNotBlocked
+	}
+
+	var activeCards int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM StudentCards WHERE student_ID = ? AND card_state = ?`, studentID, model.CardStateActive).Scan(&activeCards); err != nil {
+		return nil, fmt.Errorf("error checking active cards: %v", err)
+	}
+	if activeCards > 0 {
+		return nil, ErrActiveCardExists
+	}
+
+	if _, err := tx.Exec(`
+	UPDATE StudentCards
+	SET card_state = ?, revoked_date = NULL
+	WHERE card_uid = ?
+	`, model.CardStateActive, cardUID); err != nil {
+		return nil, fmt.Errorf("error reinstating card: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit reinstate card transaction: %v", err)
+	}
+
+	return r.GetCardByUID(cardUID)
+}
@@ -2,12 +2,13 @@ package repositories
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"rfidsystem/internal/config"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
 // DatabaseClient represents a client for interacting with the database.
@@ -41,6 +42,16 @@ func (c *DatabaseClient) Close() error {
 	return c.DB.Close()
 }
 
+// erDupEntry is the MySQL error number of a duplicate value in a unique index.
+const erDupEntry = 1062
+
+// isDuplicateKey reports whether err is MySQL rejecting a duplicate value in a
+// unique index.
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == erDupEntry
+}
+
 // LogScanEvent inserts a new log entry into the scan_logs table.
 // It records details about a scan event, including the card ID, optional student ID,
 // event type, message, optional details, and status.
@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"time"
+)
+
+// Reader and Kiosk Related Functions
+// ------------------------------------------------------------------
+
+// GetReader retrieves a registered reader by its ID.
+// It returns nil if the reader is not registered.
+func (r *RFIDRepository) GetReader(readerID string) (*model.Reader, error) {
+	reader := &model.Reader{}
+	err := r.dbClient.DB.QueryRow(`
+	SELECT reader_id, kiosk_id, name, location, is_active, created_at, COALESCE(api_key_hash, ''), api_key_issued_at
+	FROM Readers
+	WHERE reader_id = ?
+	`, readerID).Scan(&reader.ID, &reader.KioskID, &reader.Name, &reader.Location, &reader.IsActive, &reader.CreatedAt, &reader.APIKeyHash, &reader.APIKeyIssuedAt)
+	if err == sql.ErrNoRows {
+		log.Printf("No reader registered with ID: %s\n", readerID)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying reader: %v", err)
+	}
+	return reader, nil
+}
+
+// HasReaders reports whether any reader is registered.
+func (r *RFIDRepository) HasReaders() (bool, error) {
+	var exists bool
+	if err := r.dbClient.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM Readers)").Scan(&exists); err != nil {
+		return false, fmt.Errorf("error checking for readers: %v", err)
+	}
+	return exists, nil
+}
+
+// ListReaders retrieves all registered readers ordered by ID.
+func (r *RFIDRepository) ListReaders() ([]model.Reader, error) {
+	rows, err := r.dbClient.DB.Query(`
+	SELECT reader_id, kiosk_id, name, location, is_active, created_at, COALESCE(api_key_hash, ''), api_key_issued_at
+	FROM Readers
+	ORDER BY reader_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying readers: %v", err)
+	}
+	defer rows.Close()
+
+	readers := []model.Reader{}
+	for rows.Next() {
+		var reader model.Reader
+		if err := rows.Scan(&reader.ID, &reader.KioskID, &reader.Name, &reader.Location, &reader.IsActive, &reader.CreatedAt, &reader.APIKeyHash, &reader.APIKeyIssuedAt); err != nil {
+			return nil, fmt.Errorf("error scanning reader row: %v", err)
+		}
+		readers = append(readers, reader)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading reader rows: %v", err)
+	}
+	return readers, nil
+}
+
+// SaveReader registers a reader or updates an existing one, including its kiosk pairing.
+func (r *RFIDRepository) SaveReader(reader *model.Reader) error {
+	if reader.CreatedAt.IsZero() {
+		reader.CreatedAt = time.Now()
+	}
+	_, err := r.dbClient.DB.Exec(`
+	INSERT INTO Readers (reader_id, kiosk_id, name, location, is_active, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE kiosk_id = VALUES(kiosk_id), name = VALUES(name), location = VALUES(location), is_active = VALUES(is_active)
+	`, reader.ID, reader.KioskID, reader.Name, reader.Location, reader.IsActive, reader.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving reader: %v", err)
+	}
+	return nil
+}
+
+// SetReaderAPIKey stores the hash of a newly issued API key for a reader,
+// replacing any previous key.
+func (r *RFIDRepository) SetReaderAPIKey(readerID, keyHash string) error {
+	res, err := r.dbClient.DB.Exec(`
+	UPDATE Readers SET api_key_hash = ?, api_key_issued_at = ? WHERE reader_id = ?
+	`, keyHash, time.Now(), readerID)
+	if err != nil {
+		return fmt.Errorf("error setting reader api key: %v", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("reader not found")
+	}
+	return nil
+}
+
+// RevokeReaderAPIKey removes the API key of a reader. Scans signed with the
+// old key are refused afterwards.
+func (r *RFIDRepository) RevokeReaderAPIKey(readerID string) error {
+	res, err := r.dbClient.DB.Exec(`
+	UPDATE Readers SET api_key_hash = NULL, api_key_issued_at = NULL WHERE reader_id = ?
+	`, readerID)
+	if err != nil {
+		return fmt.Errorf("error revoking reader api key: %v", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("reader not found")
+	}
+	return nil
+}
+
+// GetKiosk retrieves a registered kiosk by its ID.
+// It returns nil if the kiosk is not registered.
+func (r *RFIDRepository) GetKiosk(kioskID string) (*model.Kiosk, error) {
+	kiosk := &model.Kiosk{}
+	err := r.dbClient.DB.QueryRow(`
+	SELECT kiosk_id, name, location, created_at
+	FROM Kiosks
+	WHERE kiosk_id = ?
+	`, kioskID).Scan(&kiosk.ID, &kiosk.Name, &kiosk.Location, &kiosk.CreatedAt)
+	if err == sql.ErrNoRows {
+		log.Printf("No kiosk registered with ID: %s\n", kioskID)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying kiosk: %v", err)
+	}
+	return kiosk, nil
+}
+
+// ListKiosks retrieves all registered kiosks ordered by ID.
+func (r *RFIDRepository) ListKiosks() ([]model.Kiosk, error) {
+	rows, err := r.dbClient.DB.Query(`
+	SELECT kiosk_id, name, location, created_at
+	FROM Kiosks
+	ORDER BY kiosk_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying kiosks: %v", err)
+	}
+	defer rows.Close()
+
+	kiosks := []model.Kiosk{}
+	for rows.Next() {
+		var kiosk model.Kiosk
+		if err := rows.Scan(&kiosk.ID, &kiosk.Name, &kiosk.Location, &kiosk.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning kiosk row: %v", err)
+		}
+		kiosks = append(kiosks, kiosk)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading kiosk rows: %v", err)
+	}
+	return kiosks, nil
+}
+
+// SaveKiosk registers a kiosk or updates an existing one.
+func (r *RFIDRepository) SaveKiosk(kiosk *model.Kiosk) error {
+	if kiosk.CreatedAt.IsZero() {
+		kiosk.CreatedAt = time.Now()
+	}
+	_, err := r.dbClient.DB.Exec(`
+	INSERT INTO Kiosks (kiosk_id, name, location, created_at)
+	VALUES (?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE name = VALUES(name), location = VALUES(location)
+	`, kiosk.ID, kiosk.Name, kiosk.Location, kiosk.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving kiosk: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,457 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/services"
+	"time"
+)
+
+// Discount Management Related Functions
+// ------------------------------------------------------------------
+
+// ErrDiscountTypeNotFound is returned when granting a discount type that does not exist.
+var ErrDiscountTypeNotFound = errors.New("discount type not found")
+
+// ErrDiscountTypeInactive is returned when granting a discount type that has been retired.
+var ErrDiscountTypeInactive = errors.New("discount type is no longer active")
+
+// ErrDiscountAlreadyApplied is returned when an assessment already has a discount of the same type.
+var ErrDiscountAlreadyApplied = errors.New("the assessment already has this discount")
+
+// ErrDiscountExceedsBalance is returned when a discount is larger than what is
+// left to pay on its assessment.
+var ErrDiscountExceedsBalance = errors.New("discount exceeds the remaining balance")
+
+// ListDiscountTypes retrieves every discount type, including retired ones.
+func (r *RFIDRepository) ListDiscountTypes() ([]model.DiscountType, error) {
+	rows, err := r.dbClient.DB.Query(`
+	SELECT discount_type_id, name, description, is_percentage, value, is_active
+	FROM DiscountTypes
+	ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying discount types: %v", err)
+	}
+	defer rows.Close()
+
+	discountTypes := []model.DiscountType{}
+	for rows.Next() {
+		var dt model.DiscountType
+		if err := rows.Scan(&dt.ID, &dt.Name, &dt.Description, &dt.IsPercentage, &dt.Value, &dt.IsActive); err != nil {
+			return nil, fmt.Errorf("error scanning discount type row: %v", err)
+		}
+		discountTypes = append(discountTypes, dt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading discount type rows: %v", err)
+	}
+	return discountTypes, nil
+}
+
+// GetDiscountType retrieves a discount type by its ID. It returns nil if it does not exist.
+func (r *RFIDRepository) GetDiscountType(id int64) (*model.DiscountType, error) {
+	return queryDiscountType(r.dbClient.DB, id)
+}
+
+// SaveDiscountType creates a discount type, or updates it if its ID is set.
+// Changing a discount type does not change the discounts already granted.
+func (r *RFIDRepository) SaveDiscountType(dt *model.DiscountType) error {
+	if dt.ID == 0 {
+		res, err := r.dbClient.DB.Exec(`
+		INSERT INTO DiscountTypes (name, description, is_percentage, value, is_active)
+		VALUES (?, ?, ?, ?, ?)
+		`, dt.Name, dt.Description, dt.IsPercentage, dt.Value, dt.IsActive)
+		if err != nil {
+			return fmt.Errorf("error inserting discount type: %v", err)
+		}
+		dt.ID, err = res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("error reading discount type id: %v", err)
+		}
+		return nil
+	}
+
+	res, err := r.dbClient.DB.Exec(`
+	UPDATE DiscountTypes
+	SET name = ?, description = ?, is_percentage = ?, value = ?, is_active = ?
+	WHERE discount_type_id = ?
+	`, dt.Name, dt.Description, dt.IsPercentage, dt.Value, dt.IsActive, dt.ID)
+	if err != nil {
+		return fmt.Errorf("error updating discount type: %v", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		var exists bool
+		if err := r.dbClient.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM DiscountTypes WHERE discount_type_id = ?)`, dt.ID).Scan(&exists); err != nil {
+			return fmt.Errorf("error checking discount type: %v", err)
+		}
+		if !exists {
+			return ErrDiscountTypeNotFound
+		}
+	}
+	return nil
+}
+
+// GetAssessmentDiscounts retrieves the discounts currently applied to an assessment.
+func (r *RFIDRepository) GetAssessmentDiscounts(assessmentNumber int64) ([]model.AssessmentDiscount, error) {
+	rows, err := r.dbClient.DB.Query(`
+	SELECT assessment_discount_id, assessment_number, discount_type_id, applied_amount, calculation_basis
+	FROM AssessmentDiscounts
+	WHERE assessment_number = ?
+	ORDER BY assessment_discount_id
+	`, assessmentNumber)
+	if err != nil {
+		return nil, fmt.Errorf("error querying assessment discounts: %v", err)
+	}
+	defer rows.Close()
+
+	discounts := []model.AssessmentDiscount{}
+	for rows.Next() {
+		var d model.AssessmentDiscount
+		if err := rows.Scan(&d.ID, &d.AssessmentNumber, &d.DiscountTypeID, &d.AppliedAmount, &d.CalculationBasis); err != nil {
+			return nil, fmt.Errorf("error scanning assessment discount row: %v", err)
+		}
+		discounts = append(discounts, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading assessment discount rows: %v", err)
+	}
+	return discounts, nil
+}
+
+// GetDiscountAudit retrieves the discount audit trail of an assessment, oldest first.
+func (r *RFIDRepository) GetDiscountAudit(assessmentNumber int64) ([]model.DiscountAuditEntry, error) {
+	rows, err := r.dbClient.DB.Query(`
+	SELECT da.audit_id, da.assessment_number, da.assessment_discount_id, da.discount_type_id, COALESCE(dt.name, ''),
+		da.action, da.applied_amount, da.calculation_basis, da.performed_by, da.reason, da.created_at
+	FROM DiscountAudit da
+	LEFT JOIN DiscountTypes dt ON dt.discount_type_id = da.discount_type_id
+	WHERE da.assessment_number = ?
+	ORDER BY da.created_at, da.audit_id
+	`, assessmentNumber)
+	if err != nil {
+		return nil, fmt.Errorf("error querying discount audit: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []model.DiscountAuditEntry{}
+	for rows.Next() {
+		var e model.DiscountAuditEntry
+		if err := rows.Scan(&e.ID, &e.AssessmentNumber, &e.AssessmentDiscountID, &e.DiscountTypeID, &e.DiscountName,
+			&e.Action, &e.AppliedAmount, &e.CalculationBasis, &e.PerformedBy, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning discount audit row: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading discount audit rows: %v", err)
+	}
+	return entries, nil
+}
+
+// GrantDiscount applies a discount type to an assessment in a single transaction:
+// it computes the discount amount, recomputes the assessment's total discount,
+// net amount and remaining balance, and records the grant in the audit trail.
+// It returns nil if the assessment does not exist.
+func (r *RFIDRepository) GrantDiscount(grant model.DiscountGrant) (*model.DiscountChange, error) {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin discount transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	assessment, err := lockAssessment(tx, grant.AssessmentNumber)
+	if err != nil || assessment == nil {
+		return nil, err
+	}
+
+	dt, err := activeDiscountType(tx, grant.DiscountTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied int
+	err = tx.QueryRow(`
+	SELECT COUNT(*) FROM AssessmentDiscounts WHERE assessment_number = ? AND discount_type_id = ?
+	`, assessment.ID, dt.ID).Scan(&applied)
+	if err != nil {
+		return nil, fmt.Errorf("error checking assessment discounts: %v", err)
+	}
+	if applied > 0 {
+		return nil, ErrDiscountAlreadyApplied
+	}
+
+	discount := model.AssessmentDiscount{
+		AssessmentNumber: assessment.ID,
+		DiscountTypeID:   dt.ID,
+	}
+	discount.AppliedAmount, discount.CalculationBasis = services.DiscountAmount(*dt, assessment.TotalFeeAmount)
+	res, err := tx.Exec(`
+	INSERT INTO AssessmentDiscounts (assessment_number, discount_type_id, applied_amount, calculation_basis)
+	VALUES (?, ?, ?, ?)
+	`, discount.AssessmentNumber, discount.DiscountTypeID, discount.AppliedAmount, discount.CalculationBasis)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting assessment discount: %v", err)
+	}
+	discount.ID, err = res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error reading assessment discount id: %v", err)
+	}
+
+	if err := recomputeDiscountTotals(tx, assessment); err != nil {
+		return nil, err
+	}
+	audit, err := auditDiscount(tx, discount, dt.Name, model.DiscountGranted, grant.GrantedBy, grant.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit discount transaction: %v", err)
+	}
+	return &model.DiscountChange{Discount: discount, Assessment: *assessment, Audit: *audit}, nil
+}
+
+// RevokeDiscount removes a discount from an assessment in a single transaction,
+// recomputing the assessment totals and recording the revocation in the audit
+// trail. It returns nil if the assessment or the discount does not exist.
+func (r *RFIDRepository) RevokeDiscount(assessmentNumber, assessmentDiscountID int64, revokedBy string, reason *string) (*model.DiscountChange, error) {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin discount transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	assessment, err := lockAssessment(tx, assessmentNumber)
+	if err != nil || assessment == nil {
+		return nil, err
+	}
+
+	var discount model.AssessmentDiscount
+	var name string
+	err = tx.QueryRow(`
+	SELECT ad.assessment_discount_id, ad.assessment_number, ad.discount_type_id, ad.applied_amount, ad.calculation_basis,
+		COALESCE(dt.name, '')
+	FROM AssessmentDiscounts ad
+	LEFT JOIN DiscountTypes dt ON dt.discount_type_id = ad.discount_type_id
+	WHERE ad.assessment_discount_id = ? AND ad.assessment_number = ?
+	`, assessmentDiscountID, assessment.ID).Scan(&discount.ID, &discount.AssessmentNumber, &discount.DiscountTypeID,
+		&discount.AppliedAmount, &discount.CalculationBasis, &name)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying assessment discount: %v", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM AssessmentDiscounts WHERE assessment_discount_id = ?`, discount.ID); err != nil {
+		return nil, fmt.Errorf("error deleting assessment discount: %v", err)
+	}
+	if err := recomputeDiscountTotals(tx, assessment); err != nil {
+		return nil, err
+	}
+	audit, err := auditDiscount(tx, discount, name, model.DiscountRevoked, revokedBy, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit discount transaction: %v", err)
+	}
+	return &model.DiscountChange{Discount: discount, Assessment: *assessment, Audit: *audit}, nil
+}
+
+// rowQuerier is implemented by *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// queryDiscountType retrieves a discount type by its ID. It returns nil if it does not exist.
+func queryDiscountType(q rowQuerier, id int64) (*model.DiscountType, error) {
+	dt := &model.DiscountType{}
+	err := q.QueryRow(`
+	SELECT discount_type_id, name, description, is_percentage, value, is_active
+	FROM DiscountTypes
+	WHERE discount_type_id = ?
+	`, id).Scan(&dt.ID, &dt.Name, &dt.Description, &dt.IsPercentage, &dt.Value, &dt.IsActive)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying discount type: %v", err)
+	}
+	return dt, nil
+}
+
+// activeDiscountType retrieves a discount type that can be granted, returning
+// ErrDiscountTypeNotFound or ErrDiscountTypeInactive otherwise.
+func activeDiscountType(tx *sql.Tx, id int64) (*model.DiscountType, error) {
+	dt, err := queryDiscountType(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if dt == nil {
+		return nil, ErrDiscountTypeNotFound
+	}
+	if !dt.IsActive {
+		return nil, ErrDiscountTypeInactive
+	}
+	return dt, nil
+}
+
+// recomputeDiscountTotals sums the discounts of a locked assessment and updates its
+// total discount, net amount and remaining balance. The remaining balance moves by
+// the change in total discount, so payments already recorded are kept, and the
+// payment schedule is split anew to add up to the net amount.
+func recomputeDiscountTotals(tx *sql.Tx, assessment *model.Assessment) error {
+	var totalDiscount float64
+	err := tx.QueryRow(`
+	SELECT COALESCE(SUM(applied_amount), 0) FROM AssessmentDiscounts WHERE assessment_number = ?
+	`, assessment.ID).Scan(&totalDiscount)
+	if err != nil {
+		return fmt.Errorf("error summing assessment discounts: %v", err)
+	}
+	totalDiscount = roundAmount(totalDiscount)
+
+	remaining := roundAmount(assessment.RemainingBalance - (totalDiscount - assessment.TotalDiscountAmount))
+	if totalDiscount > roundAmount(assessment.TotalFeeAmount) || remaining < 0 {
+		return ErrDiscountExceedsBalance
+	}
+
+	assessment.TotalDiscountAmount = totalDiscount
+	assessment.NetAssessmentAmount = roundAmount(assessment.TotalFeeAmount - totalDiscount)
+	assessment.RemainingBalance = remaining
+	if _, err := tx.Exec(`
+	UPDATE Assessment
+	SET total_discount_amount = ?, net_assessment_amount = ?, remaining_Balance = ?
+	WHERE assessment_Number = ?
+	`, assessment.TotalDiscountAmount, assessment.NetAssessmentAmount, assessment.RemainingBalance, assessment.ID); err != nil {
+		return fmt.Errorf("error updating assessment discount totals: %v", err)
+	}
+	return resplitPaymentSchedules(tx, assessment)
+}
+
+// resplitPaymentSchedules spreads the net amount of a locked assessment over its
+// payment schedule after its discounts change, so the installments add up to the
+// net amount again. Settled installments keep their amounts and the rest is split
+// across the unsettled ones in proportion to their amounts, the last absorbing any
+// rounding difference. If every installment is settled, the last one takes the
+// difference. Installments are then settled or reopened by the payments made.
+func resplitPaymentSchedules(tx *sql.Tx, assessment *model.Assessment) error {
+	rows, err := tx.Query(`
+	SELECT schedule_id, expected_amount, settled_at IS NOT NULL
+	FROM PaymentSchedule
+	WHERE assessment_number = ?
+	ORDER BY sort_order
+	FOR UPDATE
+	`, assessment.ID)
+	if err != nil {
+		return fmt.Errorf("error querying payment schedules: %v", err)
+	}
+	// amount is an installment's current expected amount and split its new one
+	type installment struct {
+		id      int64
+		amount  float64
+		split   float64
+		settled bool
+	}
+	var installments []installment
+	for rows.Next() {
+		var i installment
+		if err := rows.Scan(&i.id, &i.amount, &i.settled); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning payment schedule row: %v", err)
+		}
+		i.split = i.amount
+		installments = append(installments, i)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("error reading payment schedule rows: %v", err)
+	}
+	rows.Close()
+	if len(installments) == 0 {
+		return nil
+	}
+
+	var settledTotal, unsettledTotal float64
+	var unsettled []int
+	for i, inst := range installments {
+		if inst.settled {
+			settledTotal += inst.amount
+		} else {
+			unsettledTotal += inst.amount
+			unsettled = append(unsettled, i)
+		}
+	}
+	target := roundAmount(assessment.NetAssessmentAmount - settledTotal)
+	if len(unsettled) == 0 {
+		last := &installments[len(installments)-1]
+		last.split = roundAmount(last.amount + target)
+	} else {
+		var assigned float64
+		for n, i := range unsettled {
+			inst := &installments[i]
+			switch {
+			case n == len(unsettled)-1:
+				inst.split = roundAmount(target - assigned)
+			case unsettledTotal > 0:
+				inst.split = roundAmount(inst.amount * target / unsettledTotal)
+			default:
+				inst.split = roundAmount(target / float64(len(unsettled)))
+			}
+			assigned = roundAmount(assigned + inst.split)
+		}
+	}
+
+	now := time.Now()
+	var cumulative float64
+	covered := true
+	for _, inst := range installments {
+		cumulative = roundAmount(cumulative + inst.split)
+		covered = covered && cumulative <= roundAmount(assessment.TotalPaymentAmount)
+		if inst.split == inst.amount && covered == inst.settled {
+			continue
+		}
+		if _, err := tx.Exec(`
+		UPDATE PaymentSchedule
+		SET expected_amount = ?, settled_at = IF(?, COALESCE(settled_at, ?), NULL)
+		WHERE schedule_id = ?
+		`, inst.split, covered, now, inst.id); err != nil {
+			return fmt.Errorf("error updating payment schedule %d: %v", inst.id, err)
+		}
+	}
+	return nil
+}
+
+// auditDiscount records a discount grant or revocation in the audit trail.
+func auditDiscount(tx *sql.Tx, discount model.AssessmentDiscount, name, action, performedBy string, reason *string) (*model.DiscountAuditEntry, error) {
+	entry := &model.DiscountAuditEntry{
+		AssessmentNumber:     discount.AssessmentNumber,
+		AssessmentDiscountID: discount.ID,
+		DiscountTypeID:       discount.DiscountTypeID,
+		DiscountName:         name,
+		Action:               action,
+		AppliedAmount:        discount.AppliedAmount,
+		CalculationBasis:     discount.CalculationBasis,
+		PerformedBy:          performedBy,
+		Reason:               reason,
+		CreatedAt:            time.Now().Truncate(time.Second),
+	}
+	res, err := tx.Exec(`
+	INSERT INTO DiscountAudit (assessment_number, assessment_discount_id, discount_type_id, action, applied_amount,
+		calculation_basis, performed_by, reason, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.AssessmentNumber, entry.AssessmentDiscountID, entry.DiscountTypeID, entry.Action, entry.AppliedAmount,
+		entry.CalculationBasis, entry.PerformedBy, entry.Reason, entry.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting discount audit entry: %v", err)
+	}
+	entry.ID, err = res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error reading discount audit id: %v", err)
+	}
+	return entry, nil
+}
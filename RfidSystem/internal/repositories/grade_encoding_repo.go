@@ -0,0 +1,291 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"rfidsystem/internal/model"
+	"strings"
+	"time"
+)
+
+// Grade Encoding Related Functions
+// ------------------------------------------------------------------
+
+// ErrGradesLocked is returned when changing the grades of a term that is locked.
+var ErrGradesLocked = errors.New("the grades of this term are locked")
+
+// ErrNotInRoster is returned when a grade is submitted for a student who is not
+// enrolled in the class.
+var ErrNotInRoster = errors.New("student is not enrolled in this class")
+
+// GetClassRoster retrieves the students enrolled in a subject's block section for
+// a term, with their grades and whether the term's grades are locked. It returns
+// nil if the term does not exist.
+func (r *RFIDRepository) GetClassRoster(subjectCode string, termID int64, blockSection string) (*model.ClassRoster, error) {
+	roster := &model.ClassRoster{
+		SubjectCode:  subjectCode,
+		TermID:       termID,
+		BlockSection: blockSection,
+	}
+	locked, semester, err := termGradesLocked(r.dbClient.DB, termID, false)
+	if err != nil {
+		return nil, err
+	}
+	if semester == nil {
+		return nil, nil
+	}
+	roster.Locked = locked
+	roster.Semester = *semester
+
+	err = r.dbClient.DB.QueryRow(`SELECT subject_name FROM Subjects WHERE subject_Code = ?`, subjectCode).Scan(&roster.SubjectName)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying subject: %v", err)
+	}
+
+	rows, err := r.dbClient.DB.Query(rosterQuery, subjectCode, termID, blockSection)
+	if err != nil {
+		return nil, fmt.Errorf("error querying class roster: %v", err)
+	}
+	if roster.Entries, err = scanRoster(rows); err != nil {
+		return nil, err
+	}
+	return roster, nil
+}
+
+// SubmitGrades stores the grades of one grading period and the INC/DRP remarks for
+// students of a class in a single transaction, recomputing each student's final
+// grade with computeFinal.
+// Nothing is stored if the term's grades are locked or a student is not enrolled
+// in the class. It returns the updated roster.
+func (r *RFIDRepository) SubmitGrades(sub model.GradeSubmission, computeFinal func(model.RosterEntry) *float64) (*model.ClassRoster, error) {
+	column, ok := periodColumns[sub.Period]
+	if !ok {
+		return nil, fmt.Errorf("unknown grading period %q", sub.Period)
+	}
+
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin grades transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// A shared lock on the term makes locking the term wait for submissions in progress
+	locked, semester, err := termGradesLocked(tx, sub.TermID, true)
+	if err != nil {
+		return nil, err
+	}
+	if semester == nil {
+		return nil, ErrTermNotFound
+	}
+	if locked {
+		return nil, ErrGradesLocked
+	}
+
+	rows, err := tx.Query(rosterQuery+" FOR UPDATE", sub.SubjectCode, sub.TermID, sub.BlockSection)
+	if err != nil {
+		return nil, fmt.Errorf("error querying class roster: %v", err)
+	}
+	entries, err := scanRoster(rows)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int)
+	for i, entry := range entries {
+		index[entry.StudentID] = i
+	}
+	for _, studentID := range sub.StudentIDs() {
+		if _, ok := index[studentID]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNotInRoster, studentID)
+		}
+	}
+
+	now := time.Now()
+	for _, studentID := range sub.StudentIDs() {
+		entry := &entries[index[studentID]]
+		if grade, ok := sub.Grades[studentID]; ok {
+			entry.SetPeriodGrade(sub.Period, grade)
+			entry.FinalGrade = computeFinal(*entry)
+		}
+		if remark, ok := sub.Remarks[studentID]; ok {
+			entry.GradeRemark = remark
+		}
+		if _, err := tx.Exec(`
+		UPDATE Enrollments
+		SET `+column+` = ?, final_grade = ?, grade_remark = ?, grades_updated_by = ?, grades_updated_at = ?
+		WHERE enrollment_ID = ?
+		`, entry.PeriodGrade(sub.Period), entry.FinalGrade, entry.GradeRemark, sub.SubmittedBy, now, entry.EnrollmentID); err != nil {
+			return nil, fmt.Errorf("error updating grades: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit grades transaction: %v", err)
+	}
+
+	roster := &model.ClassRoster{
+		SubjectCode:  sub.SubjectCode,
+		TermID:       sub.TermID,
+		Semester:     *semester,
+		BlockSection: sub.BlockSection,
+		Entries:      entries,
+	}
+	err = r.dbClient.DB.QueryRow(`SELECT subject_name FROM Subjects WHERE subject_Code = ?`, sub.SubjectCode).Scan(&roster.SubjectName)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying subject: %v", err)
+	}
+	return roster, nil
+}
+
+// SetTermGradesLocked locks or unlocks the grades of a term. It returns false if
+// the term does not exist.
+func (r *RFIDRepository) SetTermGradesLocked(termID int64, locked bool) (bool, error) {
+	var lockedAt *time.Time
+	if locked {
+		now := time.Now()
+		lockedAt = &now
+	}
+	return r.updateTermGrading(termID, `UPDATE AcademicTerms SET grades_locked_at = ? WHERE term_id = ?`, lockedAt, termID)
+}
+
+// SetGradeDeadline sets the last day grades can be submitted for a term, or
+// removes the deadline if it is nil. It returns false if the term does not exist.
+func (r *RFIDRepository) SetGradeDeadline(termID int64, deadline *time.Time) (bool, error) {
+	var day *string
+	if deadline != nil {
+		formatted := deadline.Format("2006-01-02")
+		day = &formatted
+	}
+	return r.updateTermGrading(termID, `UPDATE AcademicTerms SET grade_deadline = ? WHERE term_id = ?`, day, termID)
+}
+
+func (r *RFIDRepository) updateTermGrading(termID int64, query string, args ...any) (bool, error) {
+	if _, err := r.dbClient.DB.Exec(query, args...); err != nil {
+		return false, fmt.Errorf("error updating term grading: %v", err)
+	}
+	var exists bool
+	if err := r.dbClient.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM AcademicTerms WHERE term_id = ?)`, termID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("error checking academic term: %v", err)
+	}
+	return exists, nil
+}
+
+// AssignFaculty assigns a class to the faculty account assignment.UserID. Assigning
+// a class twice keeps the existing assignment.
+func (r *RFIDRepository) AssignFaculty(assignment *model.FacultyAssignment) error {
+	res, err := r.dbClient.DB.Exec(`
+	INSERT INTO FacultyAssignments (user_id, subject_Code, term_id, block_section)
+	VALUES (?, ?, ?, ?)
+	ON DUPLICATE KEY UPDATE assignment_id = LAST_INSERT_ID(assignment_id)
+	`, assignment.UserID, assignment.SubjectCode, assignment.TermID, assignment.BlockSection)
+	if err != nil {
+		return fmt.Errorf("error saving faculty assignment: %v", err)
+	}
+	assignment.ID, err = res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("error reading faculty assignment id: %v", err)
+	}
+	return nil
+}
+
+// GetFacultyAssignments retrieves the classes assigned to the faculty account with the given email.
+func (r *RFIDRepository) GetFacultyAssignments(email string) ([]model.FacultyAssignment, error) {
+	rows, err := r.dbClient.DB.Query(`
+	SELECT fa.assignment_id, fa.user_id, u.email, fa.subject_Code, COALESCE(s.subject_name, ''), fa.term_id, fa.block_section
+	FROM FacultyAssignments fa
+	JOIN Users u ON u.user_id = fa.user_id
+	LEFT JOIN Subjects s ON s.subject_Code = fa.subject_Code
+	WHERE u.email = ?
+	ORDER BY fa.term_id DESC, fa.subject_Code, fa.block_section
+	`, email)
+	if err != nil {
+		return nil, fmt.Errorf("error querying faculty assignments: %v", err)
+	}
+	defer rows.Close()
+
+	assignments := []model.FacultyAssignment{}
+	for rows.Next() {
+		var a model.FacultyAssignment
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Email, &a.SubjectCode, &a.SubjectName, &a.TermID, &a.BlockSection); err != nil {
+			return nil, fmt.Errorf("error scanning faculty assignment row: %v", err)
+		}
+		assignments = append(assignments, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading faculty assignment rows: %v", err)
+	}
+	return assignments, nil
+}
+
+// IsFacultyAssigned reports whether the class is assigned to the faculty account with the given email.
+func (r *RFIDRepository) IsFacultyAssigned(email, subjectCode string, termID int64, blockSection string) (bool, error) {
+	var assigned bool
+	err := r.dbClient.DB.QueryRow(`
+	SELECT EXISTS(
+		SELECT 1 FROM FacultyAssignments fa
+		JOIN Users u ON u.user_id = fa.user_id
+		WHERE u.email = ? AND fa.subject_Code = ? AND fa.term_id = ? AND fa.block_section = ?
+	)`, email, subjectCode, termID, blockSection).Scan(&assigned)
+	if err != nil {
+		return false, fmt.Errorf("error checking faculty assignment: %v", err)
+	}
+	return assigned, nil
+}
+
+// periodColumns maps a grading period to its Enrollments column.
+var periodColumns = map[string]string{
+	model.PeriodPrelim:   "prelim_grade",
+	model.PeriodMidterm:  "midterm_grade",
+	model.PeriodPrefinal: "prefinal_grade",
+	model.PeriodFinal:    "final_term_grade",
+}
+
+const rosterQuery = `
+	SELECT e.enrollment_ID, s.student_ID, CONCAT_WS(' ', s.first_Name, s.last_Name),
+		e.prelim_grade, e.midterm_grade, e.prefinal_grade, e.final_term_grade, e.final_grade, e.grade_remark
+	FROM Enrollments e
+	JOIN Students s ON s.student_ID = e.student_ID
+	WHERE e.subject_Code = ? AND e.term_id = ? AND s.block_section = ?
+	ORDER BY s.last_Name, s.first_Name`
+
+func scanRoster(rows *sql.Rows) ([]model.RosterEntry, error) {
+	defer rows.Close()
+
+	entries := []model.RosterEntry{}
+	for rows.Next() {
+		var e model.RosterEntry
+		if err := rows.Scan(&e.EnrollmentID, &e.StudentID, &e.StudentName,
+			&e.PrelimGrade, &e.MidtermGrade, &e.PrefinalGrade, &e.FinalTermGrade, &e.FinalGrade, &e.GradeRemark); err != nil {
+			return nil, fmt.Errorf("error scanning class roster row: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading class roster rows: %v", err)
+	}
+	return entries, nil
+}
+
+// termGradesLocked reports whether a term's grades are locked, either explicitly
+// or because its grade deadline has passed, and returns its semester name. The
+// semester is nil if the term does not exist. shared takes a shared lock on the
+// term row within a transaction.
+func termGradesLocked(q rowQuerier, termID int64, shared bool) (bool, *string, error) {
+	query := `
+	SELECT semester, grades_locked_at IS NOT NULL OR (grade_deadline IS NOT NULL AND CURRENT_DATE > grade_deadline)
+	FROM AcademicTerms
+	WHERE term_id = ?`
+	if shared {
+		query += " LOCK IN SHARE MODE"
+	}
+	var semester string
+	var locked bool
+	err := q.QueryRow(strings.TrimSpace(query), termID).Scan(&semester, &locked)
+	if err == sql.ErrNoRows {
+		return false, nil, nil
+	}
+	if err != nil {
+		return false, nil, fmt.Errorf("error querying term grade lock: %v", err)
+	}
+	return locked, &semester, nil
+}
@@ -0,0 +1,441 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"rfidsystem/internal/model"
+	"rfidsystem/internal/services"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Bulk Import Related Functions
+// ------------------------------------------------------------------
+
+// rowImporter checks a row of an import file and applies it inside the import
+// transaction. Problems with the row are added to the report; the returned error
+// is for failures that abort the whole import.
+type rowImporter func(row model.ImportRow, report *model.ImportReport) error
+
+// ImportRows checks the rows of an import file of kind importType and imports them
+// in a single transaction, recording the import in the import history. Each row is
+// applied as soon as it is checked, so later rows are checked against the earlier
+// ones, and duplicate rows are reported with the line they repeat. If dryRun is set
+// or any row has errors, the transaction is rolled back and only the report is
+// returned; report.ImportID is set once the import is committed.
+func (r *RFIDRepository) ImportRows(importType, fileName string, rows []model.ImportRow, dryRun bool, importedBy string) (*model.ImportReport, error) {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin import transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var importRow rowImporter
+	switch importType {
+	case model.ImportStudents:
+		importRow = studentImporter(tx)
+	case model.ImportEnrollments:
+		importRow = enrollmentImporter(tx)
+	case model.ImportCards:
+		importRow = cardImporter(tx)
+	case model.ImportPayments:
+		importRow = paymentImporter(tx, importedBy, r.receiptKey)
+	default:
+		return nil, fmt.Errorf("unknown import type %q", importType)
+	}
+
+	report := &model.ImportReport{
+		Type:      importType,
+		FileName:  fileName,
+		DryRun:    dryRun,
+		TotalRows: len(rows),
+		Errors:    []model.ImportRowError{},
+	}
+	for _, row := range rows {
+		errorCount := len(report.Errors)
+		if err := importRow(row, report); err != nil {
+			return nil, fmt.Errorf("line %d: %v", row.Line, err)
+		}
+		if len(report.Errors) == errorCount {
+			report.ValidRows++
+		}
+	}
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	res, err := tx.Exec(`
+	INSERT INTO ImportHistory (import_type, file_name, row_count, imported_by)
+	VALUES (?, ?, ?, ?)
+	`, importType, fileName, len(rows), importedBy)
+	if err != nil {
+		return nil, fmt.Errorf("error inserting import history: %v", err)
+	}
+	if report.ImportID, err = res.LastInsertId(); err != nil {
+		return nil, fmt.Errorf("error reading import id: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit import transaction: %v", err)
+	}
+	return report, nil
+}
+
+// ListImports retrieves the import history of the given kinds of import, the latest first.
+func (r *RFIDRepository) ListImports(importTypes []string) ([]model.ImportRecord, error) {
+	records := []model.ImportRecord{}
+	if len(importTypes) == 0 {
+		return records, nil
+	}
+	args := make([]any, len(importTypes))
+	for i, importType := range importTypes {
+		args[i] = importType
+	}
+	rows, err := r.dbClient.DB.Query(`
+	SELECT import_id, import_type, file_name, row_count, imported_by, imported_at
+	FROM ImportHistory
+	WHERE import_type IN (?`+strings.Repeat(", ?", len(args)-1)+`)
+	ORDER BY imported_at DESC, import_id DESC
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying import history: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var record model.ImportRecord
+		if err := rows.Scan(&record.ID, &record.Type, &record.FileName, &record.RowCount, &record.ImportedBy, &record.ImportedAt); err != nil {
+			return nil, fmt.Errorf("error scanning import history row: %v", err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading import history rows: %v", err)
+	}
+	return records, nil
+}
+
+// studentImporter imports rows of student records, checked like records created through the API.
+func studentImporter(tx *sql.Tx) rowImporter {
+	seenIDs := map[string]int{}
+	seenEmails := map[string]int{}
+	return func(row model.ImportRow, report *model.ImportReport) error {
+		values := row.Values
+		student := &model.Student{StudentID: values["student_id"]}
+		for column, field := range map[string]**string{
+			"first_name":     &student.FirstName,
+			"last_name":      &student.LastName,
+			"middle_name":    &student.MiddleName,
+			"contact_number": &student.ContactNumber,
+			"email":          &student.Email,
+			"program":        &student.Program,
+			"block_section":  &student.BlockSection,
+		} {
+			if value, ok := values[column]; ok {
+				*field = &value
+			}
+		}
+		parseErrs := map[string]string{}
+		if yearLevel, err := strconv.Atoi(values["year_level"]); err == nil {
+			student.YearLevel = &yearLevel
+		}
+		if v := values["department_id"]; v != "" {
+			if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+				student.DepartmentID = &id
+			} else {
+				parseErrs["department_id"] = "must be a department ID"
+			}
+		}
+		if v := values["birthday"]; v != "" {
+			if birthday, err := services.ParseImportDate(v); err == nil {
+				student.Birthday = &birthday
+			} else {
+				parseErrs["birthday"] = err.Error()
+			}
+		}
+		services.NormalizeStudent(student)
+
+		errs := services.ValidateStudent(student)
+		if errs == nil {
+			errs = map[string]string{}
+		}
+		for column, msg := range parseErrs {
+			errs[column] = msg
+		}
+		if line, ok := seenIDs[student.StudentID]; ok && student.StudentID != "" {
+			errs["student_id"] = fmt.Sprintf("duplicate of line %d", line)
+		}
+		if student.Email != nil {
+			if line, ok := seenEmails[*student.Email]; ok {
+				errs["email"] = fmt.Sprintf("email is also used on line %d", line)
+			}
+		}
+		if len(errs) > 0 {
+			addFieldErrors(report, row.Line, errs)
+			return nil
+		}
+		seenIDs[student.StudentID] = row.Line
+		if student.Email != nil {
+			seenEmails[*student.Email] = row.Line
+		}
+
+		if err := saveStudentTx(tx, student, true); err != nil {
+			switch {
+			case errors.Is(err, ErrStudentExists):
+				report.AddError(row.Line, "student_id", err.Error())
+			case errors.Is(err, ErrStudentEmailTaken):
+				report.AddError(row.Line, "email", err.Error())
+			case errors.Is(err, ErrUnknownDepartment):
+				report.AddError(row.Line, "department_id", err.Error())
+			default:
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// enrollmentImporter imports rows enrolling students in a subject for a term.
+func enrollmentImporter(tx *sql.Tx) rowImporter {
+	seen := map[string]int{}
+	lookups := newImportLookups(tx)
+	return func(row model.ImportRow, report *model.ImportReport) error {
+		studentID, subjectCode := row.Values["student_id"], row.Values["subject_code"]
+		termID, ok := parseImportID(row, "term_id", report)
+		if !ok {
+			return nil
+		}
+		valid, err := lookups.checkStudentAndTerm(row, report, studentID, termID)
+		if err != nil {
+			return err
+		}
+		subjectFound, err := lookups.exists(`SELECT COUNT(*) FROM Subjects WHERE subject_Code = ?`, subjectCode)
+		if err != nil {
+			return fmt.Errorf("error checking subject: %v", err)
+		}
+		if !subjectFound {
+			report.AddError(row.Line, "subject_code", fmt.Sprintf("unknown subject %q", subjectCode))
+		}
+		if !valid || !subjectFound {
+			return nil
+		}
+
+		key := fmt.Sprintf("%s|%s|%d", studentID, subjectCode, termID)
+		if line, ok := seen[key]; ok {
+			report.AddError(row.Line, "", fmt.Sprintf("duplicate of line %d", line))
+			return nil
+		}
+		seen[key] = row.Line
+
+		var enrolled int
+		err = tx.QueryRow(`
+		SELECT COUNT(*) FROM Enrollments
+		WHERE student_ID = ? AND subject_Code = ? AND term_id = ?
+		`, studentID, subjectCode, termID).Scan(&enrolled)
+		if err != nil {
+			return fmt.Errorf("error checking enrollment: %v", err)
+		}
+		if enrolled > 0 {
+			report.AddError(row.Line, "", fmt.Sprintf("student %s is already enrolled in %s for term %d", studentID, subjectCode, termID))
+			return nil
+		}
+
+		if _, err := tx.Exec(`
+		INSERT INTO Enrollments (student_ID, subject_Code, term_id)
+		VALUES (?, ?, ?)
+		`, studentID, subjectCode, termID); err != nil {
+			return fmt.Errorf("error inserting enrollment: %v", err)
+		}
+		return nil
+	}
+}
+
+// cardImporter imports rows issuing a card to a student, replacing the student's active card.
+func cardImporter(tx *sql.Tx) rowImporter {
+	seenCards := map[string]int{}
+	seenStudents := map[string]int{}
+	return func(row model.ImportRow, report *model.ImportReport) error {
+		cardUID, studentID := row.Values["card_uid"], row.Values["student_id"]
+		if cardUID == "" {
+			report.AddError(row.Line, "card_uid", "is required")
+		}
+		if studentID == "" {
+			report.AddError(row.Line, "student_id", "is required")
+		}
+		if cardUID == "" || studentID == "" {
+			return nil
+		}
+		if line, ok := seenCards[cardUID]; ok {
+			report.AddError(row.Line, "card_uid", fmt.Sprintf("duplicate of line %d", line))
+			return nil
+		}
+		if line, ok := seenStudents[studentID]; ok {
+			report.AddError(row.Line, "student_id", fmt.Sprintf("student is also issued a card on line %d", line))
+			return nil
+		}
+		seenCards[cardUID] = row.Line
+		seenStudents[studentID] = row.Line
+
+		var owner string
+		err := tx.QueryRow(`SELECT student_ID FROM StudentCards WHERE card_uid = ?`, cardUID).Scan(&owner)
+		if err == nil {
+			report.AddError(row.Line, "card_uid", fmt.Sprintf("card %s is already registered to %s", cardUID, owner))
+			return nil
+		}
+		if err != sql.ErrNoRows {
+			return fmt.Errorf("error checking card: %v", err)
+		}
+
+		if _, err := issueCard(tx, cardUID, studentID); err != nil {
+			if errors.Is(err, ErrStudentNotFound) {
+				report.AddError(row.Line, "student_id", fmt.Sprintf("unknown student %s", studentID))
+				return nil
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+// paymentImporter imports rows of payments against a student's assessment for a
+// term, each recorded with an official receipt like a payment recorded by a cashier.
+func paymentImporter(tx *sql.Tx, recordedBy string, receiptKey []byte) rowImporter {
+	seenReferences := map[string]int{}
+	lookups := newImportLookups(tx)
+	return func(row model.ImportRow, report *model.ImportReport) error {
+		values := row.Values
+		studentID := values["student_id"]
+		termID, ok := parseImportID(row, "term_id", report)
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(values["amount"], ",", ""), 64)
+		if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) || amount <= 0 {
+			report.AddError(row.Line, "amount", "must be an amount greater than zero")
+			ok = false
+		}
+		if values["payment_method"] == "" {
+			report.AddError(row.Line, "payment_method", "is required")
+			ok = false
+		}
+		reference := values["reference_number"]
+		if line, seen := seenReferences[reference]; seen && reference != "" {
+			report.AddError(row.Line, "reference_number", fmt.Sprintf("duplicate of line %d", line))
+			ok = false
+		}
+		if !ok {
+			return nil
+		}
+		valid, err := lookups.checkStudentAndTerm(row, report, studentID, termID)
+		if err != nil || !valid {
+			return err
+		}
+
+		var assessmentNumber int64
+		err = tx.QueryRow(`
+		SELECT assessment_Number FROM Assessment
+		WHERE student_ID = ? AND term_id = ?
+		ORDER BY assessment_Number DESC
+		LIMIT 1
+		`, studentID, termID).Scan(&assessmentNumber)
+		if err == sql.ErrNoRows {
+			report.AddError(row.Line, "", fmt.Sprintf("student %s has no assessment for term %d", studentID, termID))
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error querying assessment: %v", err)
+		}
+
+		payment := model.NewPayment{
+			AssessmentNumber: assessmentNumber,
+			Amount:           amount,
+			PaymentMethod:    values["payment_method"],
+			RecordedBy:       recordedBy,
+		}
+		if reference != "" {
+			seenReferences[reference] = row.Line
+			payment.ReferenceNumber = &reference
+		}
+		if description := values["description"]; description != "" {
+			payment.Description = &description
+		}
+		if _, err := recordPayment(tx, payment, receiptKey); err != nil {
+			switch {
+			case errors.Is(err, ErrPaymentExceedsBalance):
+				report.AddError(row.Line, "amount", err.Error())
+			case errors.Is(err, ErrDuplicatePaymentReference):
+				report.AddError(row.Line, "reference_number", err.Error())
+			default:
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// importLookups remembers which students, subjects and terms exist during an import,
+// so files that repeat them are not looked up on every row.
+type importLookups struct {
+	tx    *sql.Tx
+	found map[string]bool
+}
+
+func newImportLookups(tx *sql.Tx) *importLookups {
+	return &importLookups{tx: tx, found: map[string]bool{}}
+}
+
+// exists reports whether the query, a COUNT(*) of the rows matching arg, finds any.
+func (l *importLookups) exists(query string, arg any) (bool, error) {
+	key := fmt.Sprintf("%s|%v", query, arg)
+	if found, ok := l.found[key]; ok {
+		return found, nil
+	}
+	var count int
+	if err := l.tx.QueryRow(query, arg).Scan(&count); err != nil {
+		return false, err
+	}
+	l.found[key] = count > 0
+	return count > 0, nil
+}
+
+// checkStudentAndTerm reports a row's student or term if either does not exist.
+// It returns whether both exist.
+func (l *importLookups) checkStudentAndTerm(row model.ImportRow, report *model.ImportReport, studentID string, termID int64) (bool, error) {
+	studentFound, err := l.exists(`SELECT COUNT(*) FROM Students WHERE student_ID = ?`, studentID)
+	if err != nil {
+		return false, fmt.Errorf("error checking student: %v", err)
+	}
+	if !studentFound {
+		report.AddError(row.Line, "student_id", fmt.Sprintf("unknown student %q", studentID))
+	}
+	termFound, err := l.exists(`SELECT COUNT(*) FROM AcademicTerms WHERE term_id = ?`, termID)
+	if err != nil {
+		return false, fmt.Errorf("error checking term: %v", err)
+	}
+	if !termFound {
+		report.AddError(row.Line, "term_id", fmt.Sprintf("unknown term %d", termID))
+	}
+	return studentFound && termFound, nil
+}
+
+// parseImportID parses a positive ID in a column of a row, reporting it if it is invalid.
+func parseImportID(row model.ImportRow, column string, report *model.ImportReport) (int64, bool) {
+	id, err := strconv.ParseInt(row.Values[column], 10, 64)
+	if err != nil || id <= 0 {
+		report.AddError(row.Line, column, "must be a positive whole number")
+		return 0, false
+	}
+	return id, true
+}
+
+// addFieldErrors adds field validation messages to the report in column order.
+func addFieldErrors(report *model.ImportReport, line int, errs map[string]string) {
+	columns := make([]string, 0, len(errs))
+	for column := range errs {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		report.AddError(line, column, errs[column])
+	}
+}
@@ -0,0 +1,215 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"rfidsystem/internal/model"
+	"time"
+)
+
+// Payment Recording Related Functions
+// ------------------------------------------------------------------
+
+// ErrPaymentExceedsBalance is returned when a payment is larger than the
+// remaining balance of its assessment.
+var ErrPaymentExceedsBalance = errors.New("payment exceeds the remaining balance")
+
+// ErrDuplicatePaymentReference is returned when a payment reuses the reference
+// number of a payment that was already recorded.
+var ErrDuplicatePaymentReference = errors.New("a payment with this reference number was already recorded")
+
+// RecordPayment records a payment against an assessment in a single transaction:
+// it inserts the payment, adds it to the assessment's total payment amount and
+// remaining balance, settles the payment schedule installments now covered by
+// the total paid and issues the payment's official receipt. It returns nil if
+// the assessment does not exist.
+func (r *RFIDRepository) RecordPayment(p model.NewPayment) (*model.RecordedPayment, error) {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin payment transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	recorded, err := recordPayment(tx, p, r.receiptKey)
+	if err != nil || recorded == nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit payment transaction: %v", err)
+	}
+	return recorded, nil
+}
+
+// recordPayment records a payment inside tx, as RecordPayment, signing its
+// receipt with receiptKey.
+func recordPayment(tx *sql.Tx, p model.NewPayment, receiptKey []byte) (*model.RecordedPayment, error) {
+	amount := roundAmount(p.Amount)
+	if amount <= 0 {
+		return nil, fmt.Errorf("payment amount must be positive")
+	}
+
+	// Lock the assessment row so concurrent payments are applied one at a time
+	assessment, err := lockAssessment(tx, p.AssessmentNumber)
+	if err != nil || assessment == nil {
+		return nil, err
+	}
+	if amount > roundAmount(assessment.RemainingBalance) {
+		return nil, ErrPaymentExceedsBalance
+	}
+
+	// Payments recorded at the same time on other assessments are caught by the
+	// unique index on reference_number
+	if p.ReferenceNumber != nil {
+		var duplicates int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM Payments WHERE reference_number = ?`, *p.ReferenceNumber).Scan(&duplicates)
+		if err != nil {
+			return nil, fmt.Errorf("error checking payment reference: %v", err)
+		}
+		if duplicates > 0 {
+			return nil, ErrDuplicatePaymentReference
+		}
+	}
+
+	now := time.Now()
+	res, err := tx.Exec(`
+	INSERT INTO Payments (assessment_number, payment_date, description, amount, payment_method, reference_number, recorded_by)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, assessment.ID, now, p.Description, amount, p.PaymentMethod, p.ReferenceNumber, p.RecordedBy)
+	if isDuplicateKey(err) {
+		return nil, ErrDuplicatePaymentReference
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error inserting payment: %v", err)
+	}
+	paymentID, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error reading payment id: %v", err)
+	}
+
+	assessment.TotalPaymentAmount = roundAmount(assessment.TotalPaymentAmount + amount)
+	assessment.RemainingBalance = roundAmount(assessment.RemainingBalance - amount)
+	if _, err := tx.Exec(`
+	UPDATE Assessment
+	SET total_payment_amount = ?, remaining_Balance = ?
+	WHERE assessment_Number = ?
+	`, assessment.TotalPaymentAmount, assessment.RemainingBalance, assessment.ID); err != nil {
+		return nil, fmt.Errorf("error updating assessment totals: %v", err)
+	}
+
+	settled, err := settlePaymentSchedules(tx, assessment.ID, assessment.TotalPaymentAmount, now)
+	if err != nil {
+		return nil, err
+	}
+
+	method := p.PaymentMethod
+	payment := model.Payment{
+		ID:               paymentID,
+		AssessmentNumber: assessment.ID,
+		PaymentDate:      now.Format("2006-01-02 15:04:05"),
+		Amount:           amount,
+		Description:      p.Description,
+		PaymentMethod:    &method,
+		ReferenceNumber:  p.ReferenceNumber,
+	}
+	receipt, err := issueReceipt(tx, payment, assessment, p.RecordedBy, receiptKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RecordedPayment{
+		Payment:          payment,
+		Receipt:          receipt,
+		Assessment:       assessment,
+		SettledSchedules: settled,
+	}, nil
+}
+
+// lockAssessment reads an assessment and locks its row until the transaction
+// ends. It returns nil if the assessment does not exist.
+func lockAssessment(tx *sql.Tx, assessmentNumber int64) (*model.Assessment, error) {
+	assessment := &model.Assessment{}
+	err := tx.QueryRow(`
+	SELECT assessment_Number, student_ID, term_id, total_fee_amount, total_discount_amount,
+		net_assessment_amount, initial_Payment, total_payment_amount, full_pmt_if_b4_prelim,
+		remaining_Balance, per_Exam_Fee
+	FROM Assessment
+	WHERE assessment_Number = ?
+	FOR UPDATE
+	`, assessmentNumber).Scan(
+		&assessment.ID,
+		&assessment.StudentID,
+		&assessment.TermID,
+		&assessment.TotalFeeAmount,
+		&assessment.TotalDiscountAmount,
+		&assessment.NetAssessmentAmount,
+		&assessment.InitialPayment,
+		&assessment.TotalPaymentAmount,
+		&assessment.FullPmtIfB4Prelim,
+		&assessment.RemainingBalance,
+		&assessment.PerExamFee,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying assessment: %v", err)
+	}
+	return assessment, nil
+}
+
+// settlePaymentSchedules marks the unsettled installments of an assessment whose
+// cumulative expected amount, in sort order, is covered by totalPaid.
+// It returns the IDs of the installments it settled.
+func settlePaymentSchedules(tx *sql.Tx, assessmentNumber int64, totalPaid float64, settledAt time.Time) ([]int64, error) {
+	rows, err := tx.Query(`
+	SELECT schedule_id, expected_amount, settled_at IS NOT NULL
+	FROM PaymentSchedule
+	WHERE assessment_number = ?
+	ORDER BY sort_order
+	FOR UPDATE
+	`, assessmentNumber)
+	if err != nil {
+		return nil, fmt.Errorf("error querying payment schedules: %v", err)
+	}
+
+	var toSettle []int64
+	var cumulative float64
+	for rows.Next() {
+		var id int64
+		var expected float64
+		var settled bool
+		if err := rows.Scan(&id, &expected, &settled); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("error scanning payment schedule row: %v", err)
+		}
+		cumulative = roundAmount(cumulative + expected)
+		if cumulative > totalPaid {
+			break
+		}
+		if !settled {
+			toSettle = append(toSettle, id)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("error reading payment schedule rows: %v", err)
+	}
+	rows.Close()
+
+	for _, id := range toSettle {
+		if _, err := tx.Exec(`UPDATE PaymentSchedule SET settled_at = ? WHERE schedule_id = ?`, settledAt, id); err != nil {
+			return nil, fmt.Errorf("error settling payment schedule %d: %v", id, err)
+		}
+	}
+	if toSettle == nil {
+		toSettle = []int64{}
+	}
+	return toSettle, nil
+}
+
+// roundAmount rounds a peso amount to centavos.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"rfidsystem/internal/model"
+	"time"
+)
+
+// Official Receipt Related Functions
+// ------------------------------------------------------------------
+
+// ErrReceiptKeyMissing is returned when a payment is recorded before the receipt
+// signing key is set.
+var ErrReceiptKeyMissing = errors.New("official receipts cannot be issued without a signing key")
+
+// receiptSequenceName is the ReceiptSequence row official receipt numbers are drawn from.
+const receiptSequenceName = "official_receipt"
+
+// issueReceipt assigns the next official receipt number to a payment and stores
+// the receipt within the payment transaction. The sequence row stays locked
+// until the transaction ends, so concurrent payments get consecutive numbers
+// and a number is only consumed if its payment is committed. The receipt is
+// signed with key.
+func issueReceipt(tx *sql.Tx, payment model.Payment, assessment *model.Assessment, issuedBy string, key []byte) (*model.OfficialReceipt, error) {
+	if len(key) == 0 {
+		return nil, ErrReceiptKeyMissing
+	}
+	var number int64
+	err := tx.QueryRow(`SELECT next_value FROM ReceiptSequence WHERE name = ? FOR UPDATE`, receiptSequenceName).Scan(&number)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("receipt sequence %q is missing", receiptSequenceName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading receipt sequence: %v", err)
+	}
+	if _, err := tx.Exec(`UPDATE ReceiptSequence SET next_value = next_value + 1 WHERE name = ?`, receiptSequenceName); err != nil {
+		return nil, fmt.Errorf("error advancing receipt sequence: %v", err)
+	}
+
+	receipt := &model.OfficialReceipt{
+		Number:           number,
+		PaymentID:        payment.ID,
+		AssessmentNumber: assessment.ID,
+		Amount:           payment.Amount,
+		ReferenceNumber:  payment.ReferenceNumber,
+		Description:      payment.Description,
+		BalanceAfter:     assessment.RemainingBalance,
+		IssuedAt:         time.Now().Truncate(time.Second),
+		IssuedBy:         issuedBy,
+	}
+	if payment.PaymentMethod != nil {
+		receipt.PaymentMethod = *payment.PaymentMethod
+	}
+	if assessment.StudentID != nil {
+		receipt.StudentID = *assessment.StudentID
+	}
+
+	err = tx.QueryRow(`
+	SELECT CONCAT_WS(' ', first_Name, middle_Name, last_Name)
+	FROM Students
+	WHERE student_ID = ?
+	`, receipt.StudentID).Scan(&receipt.StudentName)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying student for receipt: %v", err)
+	}
+
+	if assessment.TermID != nil {
+		var term string
+		err = tx.QueryRow(`
+		SELECT CONCAT(semester, ' ', academic_year)
+		FROM AcademicTerms
+		WHERE term_id = ?
+		`, *assessment.TermID).Scan(&term)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("error querying term for receipt: %v", err)
+		}
+		if err == nil {
+			receipt.Term = &term
+		}
+	}
+
+	receipt.ContentHash = receipt.ComputeHash(key)
+	if _, err := tx.Exec(`
+	INSERT INTO OfficialReceipts (or_number, payment_id, assessment_number, student_ID, student_name, term,
+		amount, payment_method, reference_number, description, balance_after, issued_at, issued_by, content_hash)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, receipt.Number, receipt.PaymentID, receipt.AssessmentNumber, receipt.StudentID, receipt.StudentName, receipt.Term,
+		receipt.Amount, receipt.PaymentMethod, receipt.ReferenceNumber, receipt.Description, receipt.BalanceAfter,
+		receipt.IssuedAt, receipt.IssuedBy, receipt.ContentHash); err != nil {
+		return nil, fmt.Errorf("error inserting receipt: %v", err)
+	}
+	return receipt, nil
+}
+
+// GetReceipt retrieves an official receipt by its OR number.
+// It returns nil if no receipt has that number.
+func (r *RFIDRepository) GetReceipt(number int64) (*model.OfficialReceipt, error) {
+	receipt := &model.OfficialReceipt{}
+	err := r.dbClient.DB.QueryRow(`
+	SELECT or_number, payment_id, assessment_number, student_ID, student_name, term, amount, payment_method,
+		reference_number, description, balance_after, issued_at, issued_by, content_hash
+	FROM OfficialReceipts
+	WHERE or_number = ?
+	`, number).Scan(
+		&receipt.Number,
+		&receipt.PaymentID,
+		&receipt.AssessmentNumber,
+		&receipt.StudentID,
+		&receipt.StudentName,
+		&receipt.Term,
+		&receipt.Amount,
+		&receipt.PaymentMethod,
+		&receipt.ReferenceNumber,
+		&receipt.Description,
+		&receipt.BalanceAfter,
+		&receipt.IssuedAt,
+		&receipt.IssuedBy,
+		&receipt.ContentHash,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying receipt: %v", err)
+	}
+	return receipt, nil
+}
@@ -4,6 +4,8 @@ package repositories
 // It encapsulates database operations related to students, bills, grades but
 type RFIDRepository struct {
 	dbClient *DatabaseClient
+	// receiptKey signs official receipts; see SetReceiptKey.
+	receiptKey []byte
 }
 
 // NewRFIDRepository creates a new RFIDRepository with the provided DatabaseClient.
@@ -11,6 +13,12 @@ func NewRFIDRepository(dbClient *DatabaseClient) *RFIDRepository {
 	return &RFIDRepository{dbClient: dbClient}
 }
 
+// SetReceiptKey sets the secret official receipts are signed with. Payments
+// cannot be recorded until it is set.
+func (r *RFIDRepository) SetReceiptKey(key []byte) {
+	r.receiptKey = key
+}
+
 // Things to consider soon for readability and maintainability
 // Break this down into sub repos
 // student_repo.go
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"rfidsystem/internal/model"
+	"strings"
 	"time"
 )
 
@@ -45,6 +46,24 @@ func (r *RFIDRepository) getPaymentSchedules(assessmentId int64) ([]model.Paymen
 	return schedules, nil
 }
 
+// formatScheduleDueDates formats the due dates of payment schedules as MM-DD-YYYY.
+func formatScheduleDueDates(schedules []model.PaymentSchedule) {
+	for i, ps := range schedules {
+		var t time.Time
+		var err error
+		t, err = time.Parse(time.RFC3339, ps.DueDate)
+		if err != nil {
+			// Fallback to date-only
+			t, err = time.Parse("2006-01-02", ps.DueDate)
+		}
+		if err != nil {
+			log.Printf("Invalid due_date format for schedule ID %d: %v", ps.ID, err)
+		} else {
+			schedules[i].DueDate = t.Format("01-02-2006")
+		}
+	}
+}
+
 // Bills Related Functions
 // ------------------------------------------------------------------
 
@@ -96,6 +115,53 @@ func (r *RFIDRepository) GetStudentBillsByRFID(studentId string) (*model.Bills,
 	}, nil
 }
 
+// GetStatementOfAccount retrieves a student's details, bills and payment schedule
+// for printing a statement of account. It returns nil if the student or their
+// assessment does not exist.
+func (r *RFIDRepository) GetStatementOfAccount(studentId string) (*model.StatementOfAccount, error) {
+	student, err := r.GetStudent(studentId)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, nil
+	}
+
+	bills, err := r.GetStudentBillsByRFID(studentId)
+	if err != nil {
+		return nil, err
+	}
+	if bills == nil {
+		return nil, nil
+	}
+
+	schedules, err := r.getPaymentSchedules(bills.Assessment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting payment schedules: %v", err)
+	}
+	formatScheduleDueDates(schedules)
+
+	statement := &model.StatementOfAccount{
+		Student:          student,
+		Bills:            bills,
+		PaymentSchedules: schedules,
+		GeneratedAt:      time.Now(),
+	}
+	if bills.Assessment.TermID != nil {
+		var term string
+		err := r.dbClient.DB.QueryRow(`
+		SELECT CONCAT(semester, ' ', academic_year) FROM AcademicTerms WHERE term_id = ?
+		`, *bills.Assessment.TermID).Scan(&term)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("error getting term: %v", err)
+		}
+		if err == nil {
+			statement.Term = &term
+		}
+	}
+	return statement, nil
+}
+
 func (r *RFIDRepository) getAssessment(studentId string) (*model.Assessment, error) {
 	log.Printf("Getting assessment for student ID: %s\n", studentId)
 
@@ -272,3 +338,137 @@ func (r *RFIDRepository) getPaymentHistory(assessmentId int64) ([]model.PaymentR
 
 	return payments, nil
 }
+
+// GetOutstandingAssessments retrieves every assessment with a remaining balance,
+// optionally limited to one term, together with its student and payment schedule.
+func (r *RFIDRepository) GetOutstandingAssessments(termID *int64) ([]model.AssessmentStanding, error) {
+	filter := ""
+	args := []any{}
+	if termID != nil {
+		filter = " AND a.term_id = ?"
+		args = append(args, *termID)
+	}
+
+	rows, err := r.dbClient.DB.Query(`
+	SELECT a.assessment_Number, a.student_ID, CONCAT_WS(' ', s.first_Name, s.last_Name), s.program, s.block_section,
+		a.total_payment_amount, a.remaining_Balance, a.per_Exam_Fee
+	FROM Assessment a
+	JOIN Students s ON s.student_ID = a.student_ID
+	WHERE a.remaining_Balance > 0`+filter+`
+	ORDER BY a.assessment_Number
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying outstanding assessments: %v", err)
+	}
+	defer rows.Close()
+
+	assessments := []model.AssessmentStanding{}
+	for rows.Next() {
+		var a model.AssessmentStanding
+		if err := rows.Scan(&a.AssessmentNumber, &a.StudentID, &a.StudentName, &a.Program, &a.BlockSection,
+			&a.TotalPaymentAmount, &a.RemainingBalance, &a.PerExamFee); err != nil {
+			return nil, fmt.Errorf("error scanning outstanding assessment row: %v", err)
+		}
+		assessments = append(assessments, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading outstanding assessment rows: %v", err)
+	}
+
+	if err := r.loadStandingSchedules(assessments); err != nil {
+		return nil, err
+	}
+	return assessments, nil
+}
+
+// GetBlockSectionStandings retrieves every student of a block section with their
+// latest assessment, optionally limited to one term, and its payment schedule.
+// Students without an assessment are included with an AssessmentNumber of 0.
+func (r *RFIDRepository) GetBlockSectionStandings(blockSection string, termID *int64) ([]model.AssessmentStanding, error) {
+	filter := ""
+	args := []any{}
+	if termID != nil {
+		filter = " AND latest.term_id = ?"
+		args = append(args, *termID)
+	}
+	args = append(args, blockSection)
+
+	rows, err := r.dbClient.DB.Query(`
+	SELECT COALESCE(a.assessment_Number, 0), s.student_ID, CONCAT_WS(' ', s.first_Name, s.last_Name), s.program, s.block_section,
+		COALESCE(a.total_payment_amount, 0), COALESCE(a.remaining_Balance, 0), a.per_Exam_Fee
+	FROM Students s
+	LEFT JOIN Assessment a ON a.assessment_Number = (
+		SELECT MAX(latest.assessment_Number) FROM Assessment latest
+		WHERE latest.student_ID = s.student_ID`+filter+`
+	)
+	WHERE s.block_section = ?
+	ORDER BY s.last_Name, s.first_Name
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying block section assessments: %v", err)
+	}
+	defer rows.Close()
+
+	standings := []model.AssessmentStanding{}
+	for rows.Next() {
+		var a model.AssessmentStanding
+		if err := rows.Scan(&a.AssessmentNumber, &a.StudentID, &a.StudentName, &a.Program, &a.BlockSection,
+			&a.TotalPaymentAmount, &a.RemainingBalance, &a.PerExamFee); err != nil {
+			return nil, fmt.Errorf("error scanning block section assessment row: %v", err)
+		}
+		standings = append(standings, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading block section assessment rows: %v", err)
+	}
+
+	if err := r.loadStandingSchedules(standings); err != nil {
+		return nil, err
+	}
+	return standings, nil
+}
+
+// loadStandingSchedules fills in the payment schedules of the given assessments.
+func (r *RFIDRepository) loadStandingSchedules(standings []model.AssessmentStanding) error {
+	index := make(map[int64]int)
+	placeholders := []string{}
+	args := []any{}
+	for i, a := range standings {
+		if a.AssessmentNumber == 0 {
+			continue
+		}
+		index[a.AssessmentNumber] = i
+		placeholders = append(placeholders, "?")
+		args = append(args, a.AssessmentNumber)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	rows, err := r.dbClient.DB.Query(`
+	SELECT schedule_id, assessment_number, term_description, DATE_FORMAT(due_date, '%Y-%m-%d'),
+		expected_amount, sort_order
+	FROM PaymentSchedule
+	WHERE assessment_number IN (`+strings.Join(placeholders, ", ")+`)
+	ORDER BY assessment_number, sort_order
+	`, args...)
+	if err != nil {
+		return fmt.Errorf("error querying payment schedules: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schedule model.PaymentSchedule
+		if err := rows.Scan(&schedule.ID, &schedule.AssessmentNumber, &schedule.TermDescription, &schedule.DueDate,
+			&schedule.ExpectedAmount, &schedule.SortOrder); err != nil {
+			return fmt.Errorf("error scanning payment schedule row: %v", err)
+		}
+		if i, ok := index[schedule.AssessmentNumber]; ok {
+			standings[i].Schedules = append(standings[i].Schedules, schedule)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading payment schedule rows: %v", err)
+	}
+	return nil
+}
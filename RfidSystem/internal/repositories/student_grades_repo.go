@@ -5,81 +5,83 @@ import (
 	"fmt"
 	"log"
 	"rfidsystem/internal/model"
+	"time"
 )
 
 // Grades Related Functions
 // ------------------------------------------------------------------
 
 // GetStudentGradesByRFID retrieves grades for a student for the current academic term.
-// It uses the current date to determine the current term.
+// Between terms it falls back to the latest term the student has records in, and
+// returns no term at all if there is none.
 func (r *RFIDRepository) GetStudentGradesByRFID(studentId string) (*model.Grades, error) {
 	currentTerm, err := r.GetCurrentTerm()
 	if err != nil {
 		return nil, fmt.Errorf("error getting current term: %v", err)
 	}
+	if currentTerm != nil {
+		return r.GetStudentGradesByTerm(studentId, currentTerm.ID)
+	}
 
-	return r.GetStudentGradesByRFIDAndSemester(studentId, currentTerm.AcademicYear, currentTerm.Semester)
+	// Between terms, show the student's latest term instead
+	terms, err := r.GetStudentTerms(studentId)
+	if err != nil {
+		return nil, err
+	}
+	if len(terms) > 0 {
+		return r.GetStudentGradesByTerm(studentId, terms[len(terms)-1].ID)
+	}
+	student, err := r.GetStudentByRFID(studentId)
+	if err != nil || student == nil {
+		return nil, err
+	}
+	return &model.Grades{Student: student, Terms: terms}, nil
 }
 
-// GetStudentGradesByRFIDAndSemester retrieves grades for a student for a specific academic year and semester.
-// It returns a Grades struct containing student information, the term, and a list of grade records.
-func (r *RFIDRepository) GetStudentGradesByRFIDAndSemester(studentId, academicYear, semesterName string) (*model.Grades, error) {
+// GetStudentGradesByTerm retrieves a student's grades for an academic term, along
+// with the terms the student has records in for choosing another one.
+// It returns nil if the student or the term does not exist.
+func (r *RFIDRepository) GetStudentGradesByTerm(studentId string, termID int64) (*model.Grades, error) {
 	student, err := r.GetStudentByRFID(studentId)
 	if err != nil {
-		log.Printf("Error getting student: %v\n", err)
 		return nil, fmt.Errorf("error getting student: %v", err)
 	}
 	if student == nil {
 		log.Printf("Student not found: %s\n", studentId)
-		return nil, fmt.Errorf("student not found")
+		return nil, nil
 	}
 
-	// Get term ID for the specified academic year and semester
-	var termId int64
-	termQuery := `
-    SELECT term_id
-    FROM AcademicTerms
-    WHERE academic_year = ? AND semester = ?
-    LIMIT 1`
-
-	stmtTerm, err := r.dbClient.DB.Prepare(termQuery)
+	term, err := r.GetAcademicTerm(termID)
 	if err != nil {
-		return nil, fmt.Errorf("prepare term query: %v", err)
+		return nil, err
 	}
-	defer stmtTerm.Close()
-
-	err = stmtTerm.QueryRow(academicYear, semesterName).Scan(&termId)
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("no term found for academic year %s and semester %s", academicYear, semesterName)
+	if term == nil {
+		return nil, nil
 	}
+
+	terms, err := r.GetStudentTerms(studentId)
 	if err != nil {
-		return nil, fmt.Errorf("error finding term: %v", err)
+		return nil, err
 	}
 
 	query := `
     SELECT
         e.subject_Code,
         s.subject_name,
+        s.units,
         e.prelim_grade,
         e.midterm_grade,
         e.prefinal_grade,
         e.final_term_grade,
-        e.final_grade
+        e.final_grade,
+        COALESCE(e.grade_remark, '')
     FROM Enrollments e
     JOIN Subjects s ON e.subject_Code = s.subject_Code
-    JOIN AcademicTerms at ON e.term_id = at.term_id
     WHERE e.student_id = ?
-        AND at.academic_year = ?
-        AND at.semester = ?
+        AND e.term_id = ?
     ORDER BY s.subject_code`
 
-	stmt, err := r.dbClient.DB.Prepare(query)
-	if err != nil {
-		return nil, fmt.Errorf("prepare grades query: %v", err)
-	}
-	defer stmt.Close()
-
-	rows, err := stmt.Query(studentId, academicYear, semesterName)
+	rows, err := r.dbClient.DB.Query(query, studentId, termID)
 	if err != nil {
 		return nil, fmt.Errorf("error querying grades: %v", err)
 	}
@@ -91,33 +93,84 @@ func (r *RFIDRepository) GetStudentGradesByRFIDAndSemester(studentId, academicYe
 		err := rows.Scan(
 			&grade.SubjectCode,
 			&grade.SubjectName,
+			&grade.Units,
 			&grade.PrelimGrade,
 			&grade.MidtermGrade,
 			&grade.PrefinalGrade,
 			&grade.FinalTermGrade,
 			&grade.FinalGrade,
+			&grade.GradeRemark,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning grade record: %v", err)
 		}
 		gradeRecords = append(gradeRecords, grade)
 	}
-
-	term := &model.AcademicTerm{
-		ID:           termId,
-		AcademicYear: academicYear,
-		Semester:     semesterName,
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading grade records: %v", err)
 	}
 
 	return &model.Grades{
 		Student:     student,
 		CurrentTerm: term,
+		Terms:       terms,
 		Grades:      gradeRecords,
 	}, nil
 }
 
-// GetCurrentTerm retrieves the current academic term based on the current date.
-// It queries the AcademicTerms table to find the term whose date range includes the current date.
+// GetAcademicTerm retrieves an academic term by its ID.
+// It returns nil if the term does not exist.
+func (r *RFIDRepository) GetAcademicTerm(termID int64) (*model.AcademicTerm, error) {
+	term := &model.AcademicTerm{}
+	err := r.dbClient.DB.QueryRow(`
+	SELECT term_id, academic_year, semester,
+		DATE_FORMAT(start_date, '%Y-%m-%d'), DATE_FORMAT(end_date, '%Y-%m-%d'), is_active
+	FROM AcademicTerms
+	WHERE term_id = ?
+	`, termID).Scan(&term.ID, &term.AcademicYear, &term.Semester, &term.StartDate, &term.EndDate, &term.IsActive)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying academic term: %v", err)
+	}
+	return term, nil
+}
+
+// GetStudentTerms retrieves the academic terms a student has enrollment records in,
+// in chronological order. Any kind of term is listed, including summer terms and trimesters.
+func (r *RFIDRepository) GetStudentTerms(studentId string) ([]model.AcademicTerm, error) {
+	rows, err := r.dbClient.DB.Query(`
+	SELECT DISTINCT at.term_id, at.academic_year, at.semester,
+		DATE_FORMAT(at.start_date, '%Y-%m-%d'), DATE_FORMAT(at.end_date, '%Y-%m-%d'), at.start_date
+	FROM Enrollments e
+	JOIN AcademicTerms at ON e.term_id = at.term_id
+	WHERE e.student_id = ?
+	ORDER BY at.start_date, at.term_id
+	`, studentId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying student terms: %v", err)
+	}
+	defer rows.Close()
+
+	terms := []model.AcademicTerm{}
+	for rows.Next() {
+		var term model.AcademicTerm
+		var startDate time.Time
+		if err := rows.Scan(&term.ID, &term.AcademicYear, &term.Semester, &term.StartDate, &term.EndDate, &startDate); err != nil {
+			return nil, fmt.Errorf("error scanning student term: %v", err)
+		}
+		terms = append(terms, term)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading student terms: %v", err)
+	}
+	return terms, nil
+}
+
+// GetCurrentTerm retrieves the current academic term: the term set as active, or
+// else the term whose date range includes the current date.
+// It returns nil between terms, when neither exists.
 func (r *RFIDRepository) GetCurrentTerm() (*model.AcademicTerm, error) {
 	query := `
 	SELECT
@@ -125,9 +178,11 @@ func (r *RFIDRepository) GetCurrentTerm() (*model.AcademicTerm, error) {
 		academic_year,
 		semester,
 		DATE_FORMAT(start_date, '%Y-%m-%d') as start_date,
-        DATE_FORMAT(end_date, '%Y-%m-%d') as end_date
+        DATE_FORMAT(end_date, '%Y-%m-%d') as end_date,
+		is_active
 	FROM AcademicTerms
-	WHERE CURRENT_DATE BETWEEN start_date AND end_date
+	WHERE is_active = 1 OR CURRENT_DATE BETWEEN start_date AND end_date
+	ORDER BY is_active DESC, start_date DESC
 	LIMIT 1
 	`
 
@@ -145,6 +200,7 @@ func (r *RFIDRepository) GetCurrentTerm() (*model.AcademicTerm, error) {
 		&term.Semester,
 		&term.StartDate,
 		&term.EndDate,
+		&term.IsActive,
 	)
 
 	if err == sql.ErrNoRows {
@@ -160,3 +216,88 @@ func (r *RFIDRepository) GetCurrentTerm() (*model.AcademicTerm, error) {
 		term.ID, term.AcademicYear, term.Semester)
 	return term, nil
 }
+
+// GetTranscript retrieves a student's grades in every term they were enrolled in,
+// ordered by term start date. It returns nil if the student does not exist.
+func (r *RFIDRepository) GetTranscript(studentId string) (*model.Transcript, error) {
+	student, err := r.GetStudent(studentId)
+	if err != nil {
+		return nil, err
+	}
+	if student == nil {
+		return nil, nil
+	}
+
+	terms, err := r.getStudentTermGrades(studentId)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Transcript{
+		Student:     student,
+		Terms:       terms,
+		GeneratedAt: time.Now(),
+	}, nil
+}
+
+// getStudentTermGrades retrieves a student's grades grouped by term, in chronological order.
+func (r *RFIDRepository) getStudentTermGrades(studentId string) ([]model.TranscriptTerm, error) {
+	rows, err := r.dbClient.DB.Query(`
+	SELECT
+		at.term_id,
+		at.academic_year,
+		at.semester,
+		DATE_FORMAT(at.start_date, '%Y-%m-%d'),
+		DATE_FORMAT(at.end_date, '%Y-%m-%d'),
+		e.subject_Code,
+		s.subject_name,
+		s.units,
+		e.prelim_grade,
+		e.midterm_grade,
+		e.prefinal_grade,
+		e.final_term_grade,
+		e.final_grade,
+		COALESCE(e.grade_remark, '')
+	FROM Enrollments e
+	JOIN Subjects s ON e.subject_Code = s.subject_Code
+	JOIN AcademicTerms at ON e.term_id = at.term_id
+	WHERE e.student_id = ?
+	ORDER BY at.start_date, at.term_id, e.subject_Code
+	`, studentId)
+	if err != nil {
+		return nil, fmt.Errorf("error querying term grades: %v", err)
+	}
+	defer rows.Close()
+
+	terms := []model.TranscriptTerm{}
+	for rows.Next() {
+		var term model.AcademicTerm
+		var grade model.GradesRecord
+		if err := rows.Scan(
+			&term.ID,
+			&term.AcademicYear,
+			&term.Semester,
+			&term.StartDate,
+			&term.EndDate,
+			&grade.SubjectCode,
+			&grade.SubjectName,
+			&grade.Units,
+			&grade.PrelimGrade,
+			&grade.MidtermGrade,
+			&grade.PrefinalGrade,
+			&grade.FinalTermGrade,
+			&grade.FinalGrade,
+			&grade.GradeRemark,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning term grades row: %v", err)
+		}
+		if n := len(terms); n == 0 || terms[n-1].Term.ID != term.ID {
+			terms = append(terms, model.TranscriptTerm{Term: term})
+		}
+		last := &terms[len(terms)-1]
+		last.Grades = append(last.Grades, model.TranscriptGrade{GradesRecord: grade})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading term grades rows: %v", err)
+	}
+	return terms, nil
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"rfidsystem/internal/config"
 	"rfidsystem/internal/model"
 	"rfidsystem/internal/services"
 	"time"
@@ -16,7 +17,7 @@ import (
 // It returns nil if no student is found with the given RFID.
 func (r *RFIDRepository) GetStudentByRFID(studentId string) (*model.Student, error) {
 	query := `
-	SELECT student_ID, department_ID, first_Name, last_Name, middle_Name, birthday, contact_number, email, year_Level, program, block_section, first_access_timestamp, last_access_timestamp
+	SELECT student_ID, department_ID, first_Name, last_Name, middle_Name, birthday, contact_number, email, year_Level, program, block_section, first_access_timestamp, last_access_timestamp, photo_updated_at
 	FROM Students
 	WHERE student_ID = ?
 	`
@@ -45,6 +46,7 @@ func (r *RFIDRepository) GetStudentByRFID(studentId string) (*model.Student, err
 		&student.BlockSection,
 		&firstAccessRaw,
 		&lastAccessRaw,
+		&student.PhotoUpdatedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -118,9 +120,10 @@ func (r *RFIDRepository) GetStudentByRFID(studentId string) (*model.Student, err
 
 // GetStudentSummaryData retrieves comprehensive summary data for a student by their RFID.
 // This includes basic student information, grades summary, assessment details, and payment schedules.
+// The grades summary is computed with the given grading rules.
 // It returns a StudentInfoViewModel struct or an error.
 // It returns nil if no student is found with the given RFID.
-func (r *RFIDRepository) GetStudentSummaryData(studentId string) (*model.StudentInfoViewModel, error) {
+func (r *RFIDRepository) GetStudentSummaryData(studentId string, grading config.GradingConfig) (*model.StudentInfoViewModel, error) {
 	student, err := r.GetStudentByRFID(studentId)
 	if err != nil {
 		log.Printf("Error getting student summary data: %v\n", err)
@@ -151,7 +154,7 @@ func (r *RFIDRepository) GetStudentSummaryData(studentId string) (*model.Student
 		yearLevel = services.GetYearLevelString(*student.YearLevel)
 	}
 
-	gradesSummary, err := r.getStudentGradesSummary(studentId)
+	gradesSummary, err := r.getStudentGradesSummary(studentId, grading)
 	if err != nil {
 		log.Printf("Error getting grades summary: %v", err)
 		// Continue with empty grades
@@ -181,21 +184,7 @@ func (r *RFIDRepository) GetStudentSummaryData(studentId string) (*model.Student
 	log.Printf("Payment Schedules Type: %T", paymentSchedules)
 	// log.Printf("Payment Schedules First Element Type: %T", paymentSchedules[0])
 
-	// Format due_date in payment schedules to MM-DD-YYYY
-	for i, ps := range paymentSchedules {
-		var t time.Time
-		var err error
-		t, err = time.Parse(time.RFC3339, ps.DueDate)
-		if err != nil {
-			// Fallback to date-only
-			t, err = time.Parse("2006-01-02", ps.DueDate)
-		}
-		if err != nil {
-			log.Printf("Invalid due_date format for schedule ID %d: %v", ps.ID, err)
-		} else {
-			paymentSchedules[i].DueDate = t.Format("01-02-2006")
-		}
-	}
+	formatScheduleDueDates(paymentSchedules)
 
 	return &model.StudentInfoViewModel{
 		Student:          student,
@@ -207,125 +196,30 @@ func (r *RFIDRepository) GetStudentSummaryData(studentId string) (*model.Student
 
 }
 
-func (r *RFIDRepository) getStudentGradesSummary(studentId string) ([]model.YearGradeSummary, error) {
-	log.Printf("Getting grades summary for student ID: %s", studentId)
-
-	// Get current term to get the academic year
-	currentTerm, err := r.GetCurrentTerm()
-	if err != nil {
-		log.Printf("Error getting current term: %v", err)
-		return nil, err
-	}
-	if currentTerm == nil {
-		log.Printf("No current term found")
-		return nil, nil
-	}
-
-	log.Printf("Current academic year: %s", currentTerm.AcademicYear)
-
-	// Add debug logs for query execution
-	log.Printf("Executing grades summary query for academic year: %s", currentTerm.AcademicYear)
-	query := `
-	    SELECT
-	    at.academic_year,
-        at.semester,
-        AVG(e.final_grade) as average_grade
-    FROM
-        Enrollments e
-    JOIN
-        AcademicTerms at ON e.term_id = at.term_id
-    WHERE
-        e.student_id = ?
-        AND at.academic_year = ?
-        AND e.final_grade IS NOT NULL
-    GROUP BY
-        at.academic_year, at.semester
-    ORDER BY
-        at.semester
-    `
-
-	stmt2, err := r.dbClient.DB.Prepare(query)
-	if err != nil {
-		log.Printf("Error preparing grades summary query: %v", err)
-		return nil, err
-	}
-	defer stmt2.Close()
-	rows, err := stmt2.Query(studentId, currentTerm.AcademicYear)
+// getStudentGradesSummary computes the student's GWA for each term they have
+// records in, grouped by academic year, with the same grading rules as the
+// grades screen and the transcript.
+func (r *RFIDRepository) getStudentGradesSummary(studentId string, grading config.GradingConfig) ([]model.YearGradeSummary, error) {
+	terms, err := r.getStudentTermGrades(studentId)
 	if err != nil {
 		log.Printf("Error querying grades summary: %v", err)
 		return nil, err
 	}
-	defer rows.Close()
-
-	log.Printf("Query executed successfully, processing rows")
-
-	// Create the year summary with empty grades
-	yearSummary := &model.YearGradeSummary{
-		YearName:  currentTerm.AcademicYear,
-		FirstSem:  nil,
-		SecondSem: nil,
-	}
+	transcript := &model.Transcript{Terms: terms}
+	services.SummarizeTranscript(transcript, grading)
 
-	log.Printf("Created year summary with empty grades: %+v", yearSummary)
-
-	for rows.Next() {
-		var academicYear string
-		var semester string
-		var avgGrade float64
-
-		if err := rows.Scan(&academicYear, &semester, &avgGrade); err != nil {
-			log.Printf("Error scanning row: %v", err)
-			return nil, err
+	var summary []model.YearGradeSummary
+	for _, term := range transcript.Terms {
+		if n := len(summary); n == 0 || summary[n-1].YearName != term.Term.AcademicYear {
+			summary = append(summary, model.YearGradeSummary{YearName: term.Term.AcademicYear})
 		}
-
-		log.Printf("Scanned row - Year: %s, Semester: [%s], Grade: %f", academicYear, semester, avgGrade)
-
-		// Format the grade as a string with 2 decimal places
-		gradeStr := fmt.Sprintf("%.2f", avgGrade)
-		// Create a new copy of the string to ensure it's not being shared/overwritten
-		gradeCopy := string(gradeStr)
-		log.Printf("Formatted grade: %s (copy: %s)", gradeStr, gradeCopy)
-
-		// Log the semester value and its length for debugging
-		log.Printf("Comparing semester value [%s] (length: %d)", semester, len(semester))
-
-		// Set the semester grade based on exact string match
-		switch semester {
-		case "First Semester":
-			log.Printf("Matched 'First' semester")
-			var oldGrade string
-			if yearSummary.FirstSem != nil {
-				oldGrade = *yearSummary.FirstSem
-			}
-			log.Printf("Setting First semester grade from %v to %s", oldGrade, gradeCopy)
-			yearSummary.FirstSem = &gradeCopy
-		case "Second Semester":
-			log.Printf("Matched 'Second' semester")
-			var oldGrade string
-			if yearSummary.SecondSem != nil {
-				oldGrade = *yearSummary.SecondSem
-			}
-			log.Printf("Setting Second semester grade from %v to %s", oldGrade, gradeCopy)
-			yearSummary.SecondSem = &gradeCopy
-		default:
-			log.Printf("Unknown semester value: %s", semester)
+		termSummary := model.TermGradeSummary{TermID: term.Term.ID, Semester: term.Term.Semester}
+		if term.Summary.GWA != nil {
+			gwa := services.FormatGrade(term.Summary.GWA)
+			termSummary.GWA = &gwa
 		}
+		year := &summary[len(summary)-1]
+		year.Terms = append(year.Terms, termSummary)
 	}
-	// Final summary log with detailed grade values
-	log.Printf("Final grades state - Academic Year: %s", yearSummary.YearName)
-	if yearSummary.FirstSem != nil {
-		log.Printf("First Semester: [%s]", *yearSummary.FirstSem)
-	} else {
-		log.Printf("First Semester: nil")
-	}
-	if yearSummary.SecondSem != nil {
-		log.Printf("Second Semester: [%s]", *yearSummary.SecondSem)
-	} else {
-		log.Printf("Second Semester: nil")
-	}
-
-	// Create and return the result slice
-	result := []model.YearGradeSummary{*yearSummary}
-	log.Printf("Returning %d grade summaries", len(result))
-	return result, nil
+	return summary, nil
 }
@@ -0,0 +1,407 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"rfidsystem/internal/model"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Student Records Related Functions
+// ------------------------------------------------------------------
+
+// ErrStudentNotFound is returned when a student record does not exist.
+var ErrStudentNotFound = errors.New("student not found")
+
+// ErrStudentExists is returned when creating a student whose student ID is already registered.
+var ErrStudentExists = errors.New("student ID is already registered")
+
+// ErrStudentEmailTaken is returned when saving a student with the email of another student.
+var ErrStudentEmailTaken = errors.New("email is already used by another student")
+
+// ErrUnknownDepartment is returned when saving a student in a department that does not exist.
+var ErrUnknownDepartment = errors.New("department does not exist")
+
+// ErrInvalidCursor is returned when a student search cursor does not fit its sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// studentColumns lists the Students columns scanned by scanStudent, birthdays as YYYY-MM-DD.
+const studentColumns = `
+	student_ID, department_ID, first_Name, last_Name, middle_Name,
+	DATE_FORMAT(birthday, '%Y-%m-%d'), contact_number, email, year_Level, program, block_section,
+	first_access_timestamp, last_access_timestamp, status, photo_updated_at`
+
+func scanStudent(row interface{ Scan(...any) error }) (*model.Student, error) {
+	student := &model.Student{}
+	err := row.Scan(
+		&student.StudentID,
+		&student.DepartmentID,
+		&student.FirstName,
+		&student.LastName,
+		&student.MiddleName,
+		&student.Birthday,
+		&student.ContactNumber,
+		&student.Email,
+		&student.YearLevel,
+		&student.Program,
+		&student.BlockSection,
+		&student.FirstAccessTimestamp,
+		&student.LastAccessTimestamp,
+		&student.Status,
+		&student.PhotoUpdatedAt,
+	)
+	return student, err
+}
+
+// GetStudent retrieves a student record by student ID, including its status.
+// Unlike GetStudentByRFID it does not count as an access by the student.
+// It returns nil if the student does not exist.
+func (r *RFIDRepository) GetStudent(studentID string) (*model.Student, error) {
+	student, err := scanStudent(r.dbClient.DB.QueryRow(`SELECT `+studentColumns+` FROM Students WHERE student_ID = ?`, studentID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying student: %v", err)
+	}
+	return student, nil
+}
+
+// studentSortColumns lists the columns each student search sort orders by, before
+// the student ID that breaks ties. NULLs are sorted as empty values so they can be
+// compared in cursors; migration 017 indexes these exact expressions.
+var studentSortColumns = map[string][]string{
+	model.StudentSortName:         {"COALESCE(last_Name, '')", "COALESCE(first_Name, '')"},
+	model.StudentSortID:           {},
+	model.StudentSortProgram:      {"COALESCE(program, '')"},
+	model.StudentSortYearLevel:    {"COALESCE(year_Level, 0)"},
+	model.StudentSortBlockSection: {"COALESCE(block_section, '')"},
+}
+
+// IsStudentSort reports whether a student search can be sorted by column.
+func IsStudentSort(column string) bool {
+	_, ok := studentSortColumns[column]
+	return ok
+}
+
+// studentSortKeys returns the values of a student's sort columns, as compared in cursors.
+func studentSortKeys(sort string, student *model.Student) []any {
+	text := func(s *string) any {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	switch sort {
+	case model.StudentSortName:
+		return []any{text(student.LastName), text(student.FirstName)}
+	case model.StudentSortProgram:
+		return []any{text(student.Program)}
+	case model.StudentSortYearLevel:
+		if student.YearLevel == nil {
+			return []any{0}
+		}
+		return []any{*student.YearLevel}
+	case model.StudentSortBlockSection:
+		return []any{text(student.BlockSection)}
+	}
+	return []any{}
+}
+
+// SearchStudents retrieves a page of the students matching filter and the cursors of
+// the pages around it. Pages are found from the student before (or after) them in the
+// sort order rather than by offset, so paging stays stable while records change.
+// Each word of filter.Query must start the student ID or, through the full-text
+// index, words of the first, middle or last name or email. A cursor's sort order
+// takes precedence over the filter's.
+func (r *RFIDRepository) SearchStudents(filter model.StudentFilter) (*model.PaginatedStudentResponse, error) {
+	var where []string
+	var args []any
+	for _, word := range strings.Fields(filter.Query) {
+		prefix := escapeLike(word) + "%"
+		if terms := fullTextPrefixTerms(word); terms != "" {
+			where = append(where, "(MATCH(first_Name, middle_Name, last_Name, email) AGAINST (? IN BOOLEAN MODE) OR student_ID LIKE ?)")
+			args = append(args, terms, prefix)
+		} else {
+			where = append(where, "student_ID LIKE ?")
+			args = append(args, prefix)
+		}
+	}
+	if filter.Program != "" {
+		where = append(where, "program = ?")
+		args = append(args, filter.Program)
+	}
+	if filter.YearLevel != 0 {
+		where = append(where, "year_Level = ?")
+		args = append(args, filter.YearLevel)
+	}
+	if filter.BlockSection != "" {
+		where = append(where, "block_section = ?")
+		args = append(args, filter.BlockSection)
+	}
+	if filter.DepartmentID != 0 {
+		where = append(where, "department_ID = ?")
+		args = append(args, filter.DepartmentID)
+	}
+	if filter.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filter.Status)
+	}
+
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+	var total int
+	if err := r.dbClient.DB.QueryRow(`SELECT COUNT(*) FROM Students`+whereClause, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("error counting students: %v", err)
+	}
+
+	sort, descending, page := filter.Sort, filter.Descending, 1
+	cursor := filter.Cursor
+	if cursor != nil {
+		sort, descending, page = cursor.Sort, cursor.Descending, cursor.Page
+	}
+	sortColumns, ok := studentSortColumns[sort]
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	sortColumns = append(append([]string{}, sortColumns...), "student_ID")
+
+	// Going back a page reads the rows before the cursor in reverse order
+	backward := cursor != nil && cursor.Before
+	reverse := descending != backward
+	if cursor != nil {
+		if len(cursor.Keys) != len(sortColumns)-1 {
+			return nil, ErrInvalidCursor
+		}
+		op := ">"
+		if reverse {
+			op = "<"
+		}
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(sortColumns)), ", ")
+		where = append(where, fmt.Sprintf("(%s) %s (%s)", strings.Join(sortColumns, ", "), op, placeholders))
+		args = append(append(args, cursor.Keys...), cursor.StudentID)
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+	direction := " ASC"
+	if reverse {
+		direction = " DESC"
+	}
+	orderBy := strings.Join(sortColumns, direction+", ") + direction
+
+	rows, err := r.dbClient.DB.Query(`SELECT `+studentColumns+` FROM Students`+whereClause+`
+	ORDER BY `+orderBy+`
+	LIMIT ?`, append(args, filter.PageSize+1)...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying students: %v", err)
+	}
+	defer rows.Close()
+
+	students := []*model.Student{}
+	for rows.Next() {
+		student, err := scanStudent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning student row: %v", err)
+		}
+		students = append(students, student)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading student rows: %v", err)
+	}
+
+	more := len(students) > filter.PageSize
+	if more {
+		students = students[:filter.PageSize]
+	}
+	if backward {
+		for i, j := 0, len(students)-1; i < j; i, j = i+1, j-1 {
+			students[i], students[j] = students[j], students[i]
+		}
+		if !more {
+			// Nothing before this page, whatever the cursor said
+			page = 1
+		}
+	}
+
+	response := &model.PaginatedStudentResponse{
+		Data: students,
+		Pagination: model.PaginationMetadata{
+			CurrentPage: page,
+			PageSize:    filter.PageSize,
+			TotalItems:  total,
+			TotalPages:  (total + filter.PageSize - 1) / filter.PageSize,
+		},
+	}
+	if len(students) == 0 {
+		return response, nil
+	}
+	if more || backward {
+		last := students[len(students)-1]
+		response.Pagination.NextCursor = model.StudentCursor{
+			Page: page + 1, Sort: sort, Descending: descending,
+			Keys: studentSortKeys(sort, last), StudentID: last.StudentID,
+		}.Encode()
+	}
+	if page > 1 {
+		first := students[0]
+		response.Pagination.PrevCursor = model.StudentCursor{
+			Page: page - 1, Sort: sort, Descending: descending,
+			Keys: studentSortKeys(sort, first), StudentID: first.StudentID, Before: true,
+		}.Encode()
+	}
+	return response, nil
+}
+
+// CreateStudent inserts a new, active student record. It returns ErrStudentExists if
+// the student ID is taken, ErrStudentEmailTaken if another student has the email and
+// ErrUnknownDepartment if the department does not exist.
+func (r *RFIDRepository) CreateStudent(student *model.Student) error {
+	return r.saveStudent(student, true)
+}
+
+// UpdateStudent updates the details of a student record; its status and access
+// timestamps are left as they are. It returns ErrStudentNotFound if the student does
+// not exist, and ErrStudentEmailTaken or ErrUnknownDepartment like CreateStudent.
+func (r *RFIDRepository) UpdateStudent(student *model.Student) error {
+	return r.saveStudent(student, false)
+}
+
+func (r *RFIDRepository) saveStudent(student *model.Student, create bool) error {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin student transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveStudentTx(tx, student, create); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit student transaction: %v", err)
+	}
+	return nil
+}
+
+// saveStudentTx inserts or updates a student record inside tx, as CreateStudent and UpdateStudent.
+func saveStudentTx(tx *sql.Tx, student *model.Student, create bool) error {
+	var exists int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM Students WHERE student_ID = ? FOR UPDATE`, student.StudentID).Scan(&exists); err != nil {
+		return fmt.Errorf("error checking student: %v", err)
+	}
+	if create && exists > 0 {
+		return ErrStudentExists
+	}
+	if !create && exists == 0 {
+		return ErrStudentNotFound
+	}
+
+	if student.DepartmentID != nil {
+		var found bool
+		if err := tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM Departments WHERE department_ID = ?)`, *student.DepartmentID).Scan(&found); err != nil {
+			return fmt.Errorf("error checking department: %v", err)
+		}
+		if !found {
+			return fmt.Errorf("%w: %d", ErrUnknownDepartment, *student.DepartmentID)
+		}
+	}
+
+	if student.Email != nil {
+		var owner string
+		err := tx.QueryRow(`
+		SELECT student_ID FROM Students
+		WHERE email = ? AND student_ID <> ?
+		LIMIT 1
+		FOR UPDATE
+		`, *student.Email, student.StudentID).Scan(&owner)
+		if err == nil {
+			return fmt.Errorf("%w: %s", ErrStudentEmailTaken, owner)
+		}
+		if err != sql.ErrNoRows {
+			return fmt.Errorf("error checking student email: %v", err)
+		}
+	}
+
+	if create {
+		status := model.StudentStatusActive
+		student.Status = &status
+		_, err := tx.Exec(`
+		INSERT INTO Students (student_ID, department_ID, first_Name, last_Name, middle_Name,
+			birthday, contact_number, email, year_Level, program, block_section, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, student.StudentID, student.DepartmentID, student.FirstName, student.LastName, student.MiddleName,
+			student.Birthday, student.ContactNumber, student.Email, student.YearLevel, student.Program, student.BlockSection, status)
+		if err != nil {
+			return fmt.Errorf("error inserting student: %v", err)
+		}
+	} else {
+		_, err := tx.Exec(`
+		UPDATE Students
+		SET department_ID = ?, first_Name = ?, last_Name = ?, middle_Name = ?, birthday = ?,
+			contact_number = ?, email = ?, year_Level = ?, program = ?, block_section = ?
+		WHERE student_ID = ?
+		`, student.DepartmentID, student.FirstName, student.LastName, student.MiddleName, student.Birthday,
+			student.ContactNumber, student.Email, student.YearLevel, student.Program, student.BlockSection, student.StudentID)
+		if err != nil {
+			return fmt.Errorf("error updating student: %v", err)
+		}
+	}
+	return nil
+}
+
+// SetStudentStatus sets the status of a student record, e.g. to deactivate a student
+// who left. Their grades, bills and cards are kept, but scans of their cards are
+// rejected while they are inactive. It returns ErrStudentNotFound if
+// the student does not exist.
+func (r *RFIDRepository) SetStudentStatus(studentID, status string) error {
+	var exists bool
+	if err := r.dbClient.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM Students WHERE student_ID = ?)`, studentID).Scan(&exists); err != nil {
+		return fmt.Errorf("error checking student: %v", err)
+	}
+	if !exists {
+		return ErrStudentNotFound
+	}
+	if _, err := r.dbClient.DB.Exec(`UPDATE Students SET status = ? WHERE student_ID = ?`, status, studentID); err != nil {
+		return fmt.Errorf("error updating student status: %v", err)
+	}
+	return nil
+}
+
+// SetStudentPhotoUpdated records when a student's photo was uploaded, or that they
+// have none when updatedAt is nil. It returns ErrStudentNotFound if the student does
+// not exist.
+func (r *RFIDRepository) SetStudentPhotoUpdated(studentID string, updatedAt *time.Time) error {
+	var exists bool
+	if err := r.dbClient.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM Students WHERE student_ID = ?)`, studentID).Scan(&exists); err != nil {
+		return fmt.Errorf("error checking student: %v", err)
+	}
+	if !exists {
+		return ErrStudentNotFound
+	}
+	if _, err := r.dbClient.DB.Exec(`UPDATE Students SET photo_updated_at = ? WHERE student_ID = ?`, updatedAt, studentID); err != nil {
+		return fmt.Errorf("error updating student photo: %v", err)
+	}
+	return nil
+}
+
+// fullTextPrefixTerms turns a search word into a boolean full-text query requiring
+// every run of letters and digits in it as a word prefix, e.g. "dela-cruz" into
+// "+dela* +cruz*". Other characters are dropped, so the word cannot carry
+// full-text operators. It returns "" if the word has no letters or digits.
+func fullTextPrefixTerms(word string) string {
+	tokens := strings.FieldsFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, token := range tokens {
+		tokens[i] = "+" + token + "*"
+	}
+	return strings.Join(tokens, " ")
+}
+
+// escapeLike escapes the LIKE wildcards in s so it is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
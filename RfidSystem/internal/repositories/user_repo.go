@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"rfidsystem/internal/model"
+	"time"
+)
+
+// User and Session Related Functions
+// ------------------------------------------------------------------
+
+// GetUserByEmail retrieves a user account by email address.
+// It returns nil if no account exists for the email.
+func (r *RFIDRepository) GetUserByEmail(email string) (*model.User, error) {
+	user := &model.User{}
+	err := r.dbClient.DB.QueryRow(`
+	SELECT user_id, email, password_hash, role, is_active, created_at
+	FROM Users
+	WHERE email = ?
+	`, email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.Role, &user.IsActive, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying user: %v", err)
+	}
+	return user, nil
+}
+
+// CreateUser inserts a new user account with an already hashed password and the given role.
+func (r *RFIDRepository) CreateUser(email, passwordHash, role string) (*model.User, error) {
+	now := time.Now()
+	res, err := r.dbClient.DB.Exec(`
+	INSERT INTO Users (email, password_hash, role, is_active, created_at)
+	VALUES (?, ?, ?, TRUE, ?)
+	`, email, passwordHash, role, now)
+	if err != nil {
+		return nil, fmt.Errorf("error creating user: %v", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("error reading user id: %v", err)
+	}
+	return &model.User{ID: id, Email: email, PasswordHash: passwordHash, Role: role, IsActive: true, CreatedAt: now}, nil
+}
+
+// UpdateUserPassword replaces the password hash of an existing user account.
+func (r *RFIDRepository) UpdateUserPassword(email, passwordHash string) error {
+	res, err := r.dbClient.DB.Exec(`UPDATE Users SET password_hash = ? WHERE email = ?`, passwordHash, email)
+	if err != nil {
+		return fmt.Errorf("error updating password: %v", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// UpdateUserRole changes the role of an existing user account and signs it out
+// of every session, so the new role applies from its next login.
+func (r *RFIDRepository) UpdateUserRole(email, role string) error {
+	tx, err := r.dbClient.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("begin role transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var userID int64
+	err = tx.QueryRow(`SELECT user_id FROM Users WHERE email = ? FOR UPDATE`, email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("user not found")
+	}
+	if err != nil {
+		return fmt.Errorf("error querying user: %v", err)
+	}
+	if _, err := tx.Exec(`UPDATE Users SET role = ? WHERE user_id = ?`, role, userID); err != nil {
+		return fmt.Errorf("error updating role: %v", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM Sessions WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("error revoking sessions: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit role transaction: %v", err)
+	}
+	return nil
+}
+
+// CreateSession persists a new login session.
+func (r *RFIDRepository) CreateSession(session *model.Session) error {
+	_, err := r.dbClient.DB.Exec(`
+	INSERT INTO Sessions (token_hash, user_id, created_at, last_seen_at, expires_at)
+	VALUES (?, ?, ?, ?, ?)
+	`, session.TokenHash, session.UserID, session.CreatedAt, session.LastSeenAt, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error creating session: %v", err)
+	}
+	return nil
+}
+
+// GetSession retrieves a session by token hash together with its user's email and role.
+// It returns nil if the session does not exist or its user was deactivated.
+func (r *RFIDRepository) GetSession(tokenHash string) (*model.Session, error) {
+	session := &model.Session{}
+	err := r.dbClient.DB.QueryRow(`
+	SELECT s.token_hash, s.user_id, u.email, u.role, s.created_at, s.last_seen_at, s.expires_at
+	FROM Sessions s
+	JOIN Users u ON u.user_id = s.user_id
+	WHERE s.token_hash = ? AND u.is_active = TRUE
+	`, tokenHash).Scan(&session.TokenHash, &session.UserID, &session.Email, &session.Role, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error querying session: %v", err)
+	}
+	return session, nil
+}
+
+// TouchSession records activity on a session and extends its expiry.
+func (r *RFIDRepository) TouchSession(tokenHash string, lastSeen, expiresAt time.Time) error {
+	_, err := r.dbClient.DB.Exec(`
+	UPDATE Sessions SET last_seen_at = ?, expires_at = ? WHERE token_hash = ?
+	`, lastSeen, expiresAt, tokenHash)
+	if err != nil {
+		return fmt.Errorf("error updating session: %v", err)
+	}
+	return nil
+}
+
+// DeleteSession removes a session.
+func (r *RFIDRepository) DeleteSession(tokenHash string) error {
+	if _, err := r.dbClient.DB.Exec(`DELETE FROM Sessions WHERE token_hash = ?`, tokenHash); err != nil {
+		return fmt.Errorf("error deleting session: %v", err)
+	}
+	return nil
+}
+
+// DeleteExpiredSessions removes every session whose expiry has passed.
+func (r *RFIDRepository) DeleteExpiredSessions() error {
+	res, err := r.dbClient.DB.Exec(`DELETE FROM Sessions WHERE expires_at < ?`, time.Now())
+	if err != nil {
+		return fmt.Errorf("error deleting expired sessions: %v", err)
+	}
+	if affected, _ := res.RowsAffected(); affected > 0 {
+		log.Printf("Deleted %d expired sessions", affected)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"math"
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+	"sort"
+	"time"
+)
+
+// InitialPaymentDescription is the term description of the payment schedule
+// installment due at the start of the term.
+const InitialPaymentDescription = "Initial Payment"
+
+// defaultExamPeriods are the exam periods a term's payment schedule is split
+// across when no exam window is configured within the term.
+var defaultExamPeriods = []string{"Prelim", "Midterm", "Pre-Final", "Final"}
+
+// ComputeAssessment computes a student's assessment for a term. Per-unit fees
+// are charged on the total units of the enrolled subjects and the other fees
+// as is; the discounts are then taken off the total fees. After the initial
+// payment, the net amount is split evenly across the term's exam periods, with
+// the last installment absorbing any rounding difference.
+func ComputeAssessment(input model.AssessmentInput, policy config.AssessmentConfig, windows []config.ExamWindow) model.GeneratedAssessment {
+	generated := model.GeneratedAssessment{
+		Subjects:         input.Subjects,
+		Fees:             []model.AssessmentFeeLine{},
+		Discounts:        []model.AssessmentDiscount{},
+		PaymentSchedules: []model.PaymentSchedule{},
+	}
+	for _, subject := range input.Subjects {
+		generated.Units += subject.Units
+	}
+
+	var totalFees float64
+	for _, fee := range input.Fees {
+		amount := fee.Amount
+		if fee.PerUnit {
+			amount *= generated.Units
+		}
+		amount = roundCentavos(amount)
+		if amount <= 0 {
+			continue
+		}
+		generated.Fees = append(generated.Fees, model.AssessmentFeeLine{
+			FeeTypeID: fee.FeeTypeID,
+			Name:      fee.Name,
+			Category:  fee.Category,
+			Amount:    amount,
+		})
+		totalFees += amount
+	}
+	totalFees = roundCentavos(totalFees)
+
+	var totalDiscount float64
+	for _, discountType := range input.DiscountTypes {
+		applied, basis := DiscountAmount(discountType, totalFees)
+		applied = math.Min(applied, roundCentavos(totalFees-totalDiscount))
+		if applied <= 0 {
+			continue
+		}
+		generated.Discounts = append(generated.Discounts, model.AssessmentDiscount{
+			DiscountTypeID:   discountType.ID,
+			AppliedAmount:    applied,
+			CalculationBasis: basis,
+		})
+		totalDiscount = roundCentavos(totalDiscount + applied)
+	}
+
+	net := roundCentavos(totalFees - totalDiscount)
+	initialPayment := roundCentavos(math.Min(policy.InitialPayment, net))
+	fullPayment := roundCentavos(net * (1 - math.Min(policy.FullPaymentDiscountRate, 100)/100))
+
+	sortOrder := 0
+	if initialPayment > 0 {
+		generated.PaymentSchedules = append(generated.PaymentSchedules, model.PaymentSchedule{
+			TermDescription: InitialPaymentDescription,
+			DueDate:         input.TermStart.Format("2006-01-02"),
+			ExpectedAmount:  initialPayment,
+			SortOrder:       sortOrder,
+		})
+	}
+	periods := examPeriods(windows, input.TermStart, input.TermEnd)
+	perExam := roundCentavos((net - initialPayment) / float64(len(periods)))
+	scheduled := initialPayment
+	for i, period := range periods {
+		amount := perExam
+		if i == len(periods)-1 {
+			amount = roundCentavos(net - scheduled)
+		}
+		sortOrder++
+		generated.PaymentSchedules = append(generated.PaymentSchedules, model.PaymentSchedule{
+			TermDescription: period.Name,
+			DueDate:         period.Start.Format("2006-01-02"),
+			ExpectedAmount:  amount,
+			SortOrder:       sortOrder,
+		})
+		scheduled = roundCentavos(scheduled + amount)
+	}
+
+	generated.Assessment = model.Assessment{
+		TotalFeeAmount:      totalFees,
+		TotalDiscountAmount: totalDiscount,
+		NetAssessmentAmount: net,
+		InitialPayment:      &initialPayment,
+		FullPmtIfB4Prelim:   &fullPayment,
+		RemainingBalance:    net,
+		PerExamFee:          &perExam,
+	}
+	return generated
+}
+
+// DiscountAmount computes the amount of a discount on an assessment with the
+// given total fees. Percentage discounts are computed on the total fees, which
+// is returned as the calculation basis; fixed discounts have no basis.
+func DiscountAmount(discountType model.DiscountType, totalFees float64) (float64, *float64) {
+	if !discountType.IsPercentage {
+		return roundCentavos(discountType.Value), nil
+	}
+	basis := roundCentavos(totalFees)
+	return roundCentavos(basis * discountType.Value / 100), &basis
+}
+
+// examPeriods returns the exam periods of a term, each due on its first day:
+// the configured exam windows that start within the term, or else the default
+// exam periods spread evenly over the term with the last one due on its last day.
+func examPeriods(windows []config.ExamWindow, termStart, termEnd time.Time) []config.ExamWindow {
+	start, end := truncateDay(termStart), truncateDay(termEnd)
+	var periods []config.ExamWindow
+	for _, window := range windows {
+		if !window.Start.Before(start) && !window.Start.After(end) {
+			periods = append(periods, window)
+		}
+	}
+	if len(periods) > 0 {
+		sort.Slice(periods, func(i, j int) bool { return periods[i].Start.Before(periods[j].Start) })
+		return periods
+	}
+
+	days := int(end.Sub(start).Hours() / 24)
+	for i, name := range defaultExamPeriods {
+		due := start.AddDate(0, 0, days*(i+1)/len(defaultExamPeriods))
+		periods = append(periods, config.ExamWindow{Name: name, Start: due, End: due})
+	}
+	return periods
+}
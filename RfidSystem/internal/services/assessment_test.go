@@ -0,0 +1,214 @@
+package services
+
+import (
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+	"testing"
+)
+
+// scheduleLine is the part of a generated payment schedule installment the tests check.
+type scheduleLine struct {
+	description string
+	dueDate     string
+	amount      float64
+}
+
+func TestComputeAssessment(t *testing.T) {
+	subjects := []model.EnrolledSubject{
+		{SubjectCode: "IT101", Units: 3},
+		{SubjectCode: "IT102", Units: 3},
+		{SubjectCode: "PE1", Units: 1.5},
+	}
+	fees := []model.FeeScheduleItem{
+		{FeeTypeID: 1, Name: "Tuition", Amount: 1000, PerUnit: true},
+		{FeeTypeID: 2, Name: "Miscellaneous", Amount: 1500.50},
+		{FeeTypeID: 3, Name: "Laboratory", Amount: 0},
+	}
+	windows := []config.ExamWindow{
+		{Name: "Final", Start: examDay("2025-12-01"), End: examDay("2025-12-05")},
+		{Name: "Prelim", Start: examDay("2025-08-18"), End: examDay("2025-08-23")},
+		{Name: "Midterm", Start: examDay("2025-09-22"), End: examDay("2025-09-27")},
+		{Name: "Pre-Final", Start: examDay("2025-10-27"), End: examDay("2025-10-31")},
+		{Name: "Next Prelim", Start: examDay("2026-01-19"), End: examDay("2026-01-24")},
+	}
+	policy := config.AssessmentConfig{InitialPayment: 3000, FullPaymentDiscountRate: 5}
+
+	tests := []struct {
+		name          string
+		discounts     []model.DiscountType
+		policy        config.AssessmentConfig
+		wantFees      float64
+		wantDiscounts []float64
+		wantNet       float64
+		wantFull      float64
+		wantPerExam   float64
+		wantSchedule  []scheduleLine
+	}{
+		{
+			name: "discounts and a rounding remainder on the last installment",
+			discounts: []model.DiscountType{
+				{ID: 1, IsPercentage: true, Value: 10},
+				{ID: 2, Value: 500},
+			},
+			policy:        policy,
+			wantFees:      9000.50,
+			wantDiscounts: []float64{900.05, 500},
+			wantNet:       7600.45,
+			wantFull:      7220.43,
+			wantPerExam:   1150.11,
+			wantSchedule: []scheduleLine{
+				{InitialPaymentDescription, "2025-08-01", 3000},
+				{"Prelim", "2025-08-18", 1150.11},
+				{"Midterm", "2025-09-22", 1150.11},
+				{"Pre-Final", "2025-10-27", 1150.11},
+				{"Final", "2025-12-01", 1150.12},
+			},
+		},
+		{
+			name:          "discounts capped at the total fees",
+			discounts:     []model.DiscountType{{ID: 1, Value: 10000}, {ID: 2, IsPercentage: true, Value: 20}},
+			policy:        policy,
+			wantFees:      9000.50,
+			wantDiscounts: []float64{9000.50},
+			wantSchedule: []scheduleLine{
+				{"Prelim", "2025-08-18", 0},
+				{"Midterm", "2025-09-22", 0},
+				{"Pre-Final", "2025-10-27", 0},
+				{"Final", "2025-12-01", 0},
+			},
+		},
+		{
+			name:          "initial payment capped at the net amount",
+			discounts:     []model.DiscountType{{ID: 1, Value: 7000.50}},
+			policy:        config.AssessmentConfig{InitialPayment: 3000},
+			wantFees:      9000.50,
+			wantDiscounts: []float64{7000.50},
+			wantNet:       2000,
+			wantFull:      2000,
+			wantPerExam:   0,
+			wantSchedule: []scheduleLine{
+				{InitialPaymentDescription, "2025-08-01", 2000},
+				{"Prelim", "2025-08-18", 0},
+				{"Midterm", "2025-09-22", 0},
+				{"Pre-Final", "2025-10-27", 0},
+				{"Final", "2025-12-01", 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := model.AssessmentInput{
+				Subjects:      subjects,
+				Fees:          fees,
+				DiscountTypes: tt.discounts,
+				TermStart:     examDay("2025-08-01"),
+				TermEnd:       examDay("2025-12-15"),
+			}
+			generated := ComputeAssessment(input, tt.policy, windows)
+
+			if generated.Units != 7.5 || len(generated.Fees) != 2 {
+				t.Errorf("got %.1f units and %d fee lines, want 7.5 units and 2 fee lines", generated.Units, len(generated.Fees))
+			}
+			assessment := generated.Assessment
+			if assessment.TotalFeeAmount != tt.wantFees || assessment.NetAssessmentAmount != tt.wantNet ||
+				assessment.RemainingBalance != tt.wantNet || *assessment.FullPmtIfB4Prelim != tt.wantFull ||
+				*assessment.PerExamFee != tt.wantPerExam {
+				t.Errorf("got fees %.2f, net %.2f, balance %.2f, full payment %.2f, per exam %.2f; want %.2f, %.2f, %.2f, %.2f, %.2f",
+					assessment.TotalFeeAmount, assessment.NetAssessmentAmount, assessment.RemainingBalance,
+					*assessment.FullPmtIfB4Prelim, *assessment.PerExamFee,
+					tt.wantFees, tt.wantNet, tt.wantNet, tt.wantFull, tt.wantPerExam)
+			}
+
+			if len(generated.Discounts) != len(tt.wantDiscounts) {
+				t.Fatalf("got %d discounts, want %d", len(generated.Discounts), len(tt.wantDiscounts))
+			}
+			var totalDiscount float64
+			for i, discount := range generated.Discounts {
+				if discount.AppliedAmount != tt.wantDiscounts[i] {
+					t.Errorf("discount %d = %.2f, want %.2f", i, discount.AppliedAmount, tt.wantDiscounts[i])
+				}
+				totalDiscount += discount.AppliedAmount
+			}
+			if roundCentavos(totalDiscount) != assessment.TotalDiscountAmount {
+				t.Errorf("total discount = %.2f, want the sum of the discounts %.2f", assessment.TotalDiscountAmount, totalDiscount)
+			}
+
+			if len(generated.PaymentSchedules) != len(tt.wantSchedule) {
+				t.Fatalf("got %d installments, want %d", len(generated.PaymentSchedules), len(tt.wantSchedule))
+			}
+			var scheduled float64
+			for i, schedule := range generated.PaymentSchedules {
+				got := scheduleLine{schedule.TermDescription, schedule.DueDate, schedule.ExpectedAmount}
+				// Installments after a skipped initial payment keep their sort order
+				wantOrder := i
+				if tt.wantSchedule[0].description != InitialPaymentDescription {
+					wantOrder++
+				}
+				if got != tt.wantSchedule[i] || schedule.SortOrder != wantOrder {
+					t.Errorf("installment %d = %+v (sort order %d), want %+v", i, got, schedule.SortOrder, tt.wantSchedule[i])
+				}
+				scheduled += schedule.ExpectedAmount
+			}
+			if roundCentavos(scheduled) != tt.wantNet {
+				t.Errorf("installments add up to %.2f, want the net amount %.2f", scheduled, tt.wantNet)
+			}
+		})
+	}
+}
+
+func TestExamPeriods(t *testing.T) {
+	tests := []struct {
+		name    string
+		windows []config.ExamWindow
+		want    []scheduleLine
+	}{
+		{
+			name: "default periods spread over the term",
+			want: []scheduleLine{
+				{"Prelim", "2025-08-31", 0},
+				{"Midterm", "2025-10-01", 0},
+				{"Pre-Final", "2025-10-31", 0},
+				{"Final", "2025-12-01", 0},
+			},
+		},
+		{
+			name: "windows on the first and last day of the term",
+			windows: []config.ExamWindow{
+				{Name: "Final", Start: examDay("2025-12-01"), End: examDay("2025-12-03")},
+				{Name: "Summer", Start: examDay("2025-12-02"), End: examDay("2025-12-04")},
+				{Name: "Orientation", Start: examDay("2025-07-31"), End: examDay("2025-08-02")},
+				{Name: "Prelim", Start: examDay("2025-08-01"), End: examDay("2025-08-02")},
+			},
+			want: []scheduleLine{
+				{"Prelim", "2025-08-01", 0},
+				{"Final", "2025-12-01", 0},
+			},
+		},
+		{
+			name: "no window within the term",
+			windows: []config.ExamWindow{
+				{Name: "Next Prelim", Start: examDay("2026-01-19"), End: examDay("2026-01-24")},
+			},
+			want: []scheduleLine{
+				{"Prelim", "2025-08-31", 0},
+				{"Midterm", "2025-10-01", 0},
+				{"Pre-Final", "2025-10-31", 0},
+				{"Final", "2025-12-01", 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods := examPeriods(tt.windows, examDay("2025-08-01"), examDay("2025-12-01"))
+			if len(periods) != len(tt.want) {
+				t.Fatalf("got %d periods, want %d", len(periods), len(tt.want))
+			}
+			for i, period := range periods {
+				got := scheduleLine{period.Name, period.Start.Format("2006-01-02"), 0}
+				if got != tt.want[i] {
+					t.Errorf("period %d = %+v, want %+v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
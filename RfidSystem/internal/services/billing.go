@@ -0,0 +1,67 @@
+package services
+
+import (
+	"fmt"
+	"rfidsystem/internal/model"
+	"strings"
+)
+
+// FeesByCategory returns the fees of the given category, in their original order.
+func FeesByCategory(fees []model.FeeBreakdown, category string) []model.FeeBreakdown {
+	var filtered []model.FeeBreakdown
+	for _, fee := range fees {
+		if fee.Category == category {
+			filtered = append(filtered, fee)
+		}
+	}
+	return filtered
+}
+
+// FeeCategories returns the distinct fee categories in the order they first appear.
+func FeeCategories(fees []model.FeeBreakdown) []string {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, fee := range fees {
+		if !seen[fee.Category] {
+			seen[fee.Category] = true
+			categories = append(categories, fee.Category)
+		}
+	}
+	return categories
+}
+
+// SumFees returns the total amount of the given fees.
+func SumFees(fees []model.FeeBreakdown) float64 {
+	var total float64
+	for _, fee := range fees {
+		total += fee.Amount
+	}
+	return total
+}
+
+// FormatPeso formats an amount in pesos for PDF documents, e.g. "PHP 12,345.60".
+func FormatPeso(amount float64) string {
+	formatted := FormatAmount(amount)
+	if strings.HasPrefix(formatted, "-") {
+		return "-PHP " + formatted[1:]
+	}
+	return "PHP " + formatted
+}
+
+// FormatAmount formats an amount with thousands separators and two decimals, e.g. "12,345.60".
+func FormatAmount(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	whole, cents, _ := strings.Cut(fmt.Sprintf("%.2f", amount), ".")
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return fmt.Sprintf("%s%s.%s", sign, grouped.String(), cents)
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"fmt"
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+	"strings"
+	"unicode"
+)
+
+// EvaluateExamPermit decides whether a student is cleared to take the exam of
+// the given window. The installment due for the exam is the payment schedule
+// installment named after the exam ("Pre-Final" matches a "Prefinal" window);
+// if there is none, every installment due by the last day of the exam counts.
+// The student is cleared once that installment and all earlier ones are paid.
+// If no installment applies, e.g. because the assessment has no payment schedule,
+// the per-exam fees of this exam and the exam windows before it must be paid
+// instead, or the whole balance if there is no per-exam fee.
+func EvaluateExamPermit(standing model.AssessmentStanding, window config.ExamWindow, windows []config.ExamWindow) model.ExamPermit {
+	permit := model.ExamPermit{
+		StudentID:    standing.StudentID,
+		StudentName:  standing.StudentName,
+		BlockSection: standing.BlockSection,
+		Exam:         window.Name,
+		PerExamFee:   standing.PerExamFee,
+	}
+	if standing.AssessmentNumber == 0 {
+		permit.Reason = "No assessment on record"
+		return permit
+	}
+
+	installments := EvaluateInstallments(standing.Schedules, standing.TotalPaymentAmount, window.Start, config.LatePenaltyConfig{})
+	last := -1
+	for i, installment := range installments {
+		if examKey(installment.TermDescription) == examKey(window.Name) {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		examEnd := truncateDay(window.End)
+		for i, installment := range installments {
+			if dueDate, ok := parseDueDate(installment.DueDate); ok && !dueDate.After(examEnd) {
+				last = i
+			}
+		}
+	}
+
+	if last < 0 {
+		permit.AmountDue = roundCentavos(standing.RemainingBalance)
+		if standing.PerExamFee != nil && *standing.PerExamFee > 0 {
+			feesDue := float64(examsThrough(window, windows)) * *standing.PerExamFee
+			permit.AmountDue = roundCentavos(max(min(feesDue-standing.TotalPaymentAmount, standing.RemainingBalance), 0))
+		}
+		permit.Cleared = permit.AmountDue <= 0
+		if !permit.Cleared {
+			permit.Reason = fmt.Sprintf("No installment is scheduled for the %s exam; %s due before it", window.Name, FormatPeso(permit.AmountDue))
+		}
+		return permit
+	}
+
+	for _, installment := range installments[:last+1] {
+		permit.AmountDue += installment.Outstanding
+	}
+	permit.AmountDue = roundCentavos(permit.AmountDue)
+	permit.Cleared = permit.AmountDue <= 0
+	if !permit.Cleared {
+		permit.Reason = fmt.Sprintf("%s due before the %s exam", FormatPeso(permit.AmountDue), window.Name)
+	}
+	return permit
+}
+
+// examsThrough counts the exams up to and including window: window itself and
+// the other windows that start before it.
+func examsThrough(window config.ExamWindow, windows []config.ExamWindow) int {
+	count := 1
+	for _, other := range windows {
+		if other.Start.Before(window.Start) && !strings.EqualFold(other.Name, window.Name) {
+			count++
+		}
+	}
+	return count
+}
+
+// examKey normalizes an exam or installment name for comparison by keeping
+// only its letters, in lower case.
+func examKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if !unicode.IsLetter(r) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, name)
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+	"testing"
+	"time"
+)
+
+func examDay(value string) time.Time {
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return day
+}
+
+func TestEvaluateExamPermit(t *testing.T) {
+	prelim := config.ExamWindow{Name: "Prelim", Start: examDay("2025-08-18"), End: examDay("2025-08-23")}
+	midterm := config.ExamWindow{Name: "Midterm", Start: examDay("2025-09-22"), End: examDay("2025-09-27")}
+	windows := []config.ExamWindow{prelim, midterm}
+	fee := 2000.0
+	schedules := []model.PaymentSchedule{
+		{TermDescription: "Initial Payment", DueDate: "2025-08-01", ExpectedAmount: 1000, SortOrder: 0},
+		{TermDescription: "Prelim", DueDate: "2025-08-18", ExpectedAmount: 2000, SortOrder: 1},
+		{TermDescription: "Pre-Final", DueDate: "2025-10-20", ExpectedAmount: 2000, SortOrder: 2},
+	}
+
+	tests := []struct {
+		name        string
+		standing    model.AssessmentStanding
+		window      config.ExamWindow
+		wantCleared bool
+		wantDue     float64
+	}{
+		{
+			name:     "no assessment",
+			standing: model.AssessmentStanding{},
+			window:   prelim,
+		},
+		{
+			name:        "installments paid through the exam",
+			standing:    model.AssessmentStanding{AssessmentNumber: 1, TotalPaymentAmount: 3000, RemainingBalance: 2000, Schedules: schedules},
+			window:      prelim,
+			wantCleared: true,
+		},
+		{
+			name:     "installment partially paid",
+			standing: model.AssessmentStanding{AssessmentNumber: 1, TotalPaymentAmount: 1500.25, RemainingBalance: 3499.75, Schedules: schedules},
+			window:   prelim,
+			wantDue:  1499.75,
+		},
+		{
+			name:     "installments due by the end of an exam without its own installment",
+			standing: model.AssessmentStanding{AssessmentNumber: 1, TotalPaymentAmount: 1000, RemainingBalance: 4000, Schedules: schedules},
+			window:   midterm,
+			wantDue:  2000,
+		},
+		{
+			name:        "per-exam fee paid for the first exam",
+			standing:    model.AssessmentStanding{AssessmentNumber: 1, TotalPaymentAmount: 2000, RemainingBalance: 8000, PerExamFee: &fee},
+			window:      prelim,
+			wantCleared: true,
+		},
+		{
+			name:     "per-exam fee of the second exam unpaid",
+			standing: model.AssessmentStanding{AssessmentNumber: 1, TotalPaymentAmount: 2000, RemainingBalance: 8000, PerExamFee: &fee},
+			window:   midterm,
+			wantDue:  2000,
+		},
+		{
+			name:     "no per-exam fee paid yet",
+			standing: model.AssessmentStanding{AssessmentNumber: 1, RemainingBalance: 10000, PerExamFee: &fee},
+			window:   midterm,
+			wantDue:  4000,
+		},
+		{
+			name:     "per-exam fees capped at the remaining balance",
+			standing: model.AssessmentStanding{AssessmentNumber: 1, TotalPaymentAmount: 2500, RemainingBalance: 500, PerExamFee: &fee},
+			window:   midterm,
+			wantDue:  500,
+		},
+		{
+			name:     "no per-exam fee charges the remaining balance",
+			standing: model.AssessmentStanding{AssessmentNumber: 1, TotalPaymentAmount: 2000, RemainingBalance: 8000},
+			window:   prelim,
+			wantDue:  8000,
+		},
+		{
+			name:        "fully paid without a schedule",
+			standing:    model.AssessmentStanding{AssessmentNumber: 1, TotalPaymentAmount: 10000, PerExamFee: &fee},
+			window:      midterm,
+			wantCleared: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			permit := EvaluateExamPermit(tt.standing, tt.window, windows)
+			if permit.Cleared != tt.wantCleared || permit.AmountDue != tt.wantDue {
+				t.Errorf("got cleared %v, amount due %.2f; want cleared %v, amount due %.2f (reason %q)",
+					permit.Cleared, permit.AmountDue, tt.wantCleared, tt.wantDue, permit.Reason)
+			}
+			if !permit.Cleared && permit.Reason == "" {
+				t.Error("a permit that is not cleared has no reason")
+			}
+		})
+	}
+}
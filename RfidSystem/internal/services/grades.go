@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+)
+
+// ValidateGrade checks that a period grade is within the configured range.
+func ValidateGrade(grade float64, grading config.GradingConfig) error {
+	if math.IsNaN(grade) || grade < grading.MinGrade || grade > grading.MaxGrade {
+		return fmt.Errorf("grade %g is outside the range %g to %g", grade, grading.MinGrade, grading.MaxGrade)
+	}
+	return nil
+}
+
+// ComputeFinalGrade computes the final grade of an enrollment as the weighted
+// average of its period grades. It returns nil until every period with a
+// weight has been graded.
+func ComputeFinalGrade(entry model.RosterEntry, weights map[string]float64) *float64 {
+	var sum, totalWeight float64
+	for _, period := range model.GradingPeriods {
+		weight := weights[period]
+		if weight <= 0 {
+			continue
+		}
+		grade := entry.PeriodGrade(period)
+		if grade == nil {
+			return nil
+		}
+		sum += *grade * weight
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return nil
+	}
+	final := math.Round(sum/totalWeight*100) / 100
+	return &final
+}
+
+// GradeRemark returns the remark of an enrollment: its INC or DRP remark if it
+// was marked so, Passed or Failed once it has a final grade, and "" before that.
+func GradeRemark(record model.GradesRecord, grading config.GradingConfig) string {
+	if record.GradeRemark != "" {
+		return record.GradeRemark
+	}
+	if record.FinalGrade == nil {
+		return ""
+	}
+	if grading.AtLeast(*record.FinalGrade, grading.PassingGrade) {
+		return model.RemarkPassed
+	}
+	return model.RemarkFailed
+}
+
+// SummarizeGrades computes the GWA of a set of grades, the average of their final
+// grades weighted by subject units. Subjects without a final grade or marked INC
+// or DRP are left out. A Latin honor is only awarded when every subject has been
+// passed, and is the highest one whose threshold the GWA meets.
+func SummarizeGrades(records []model.GradesRecord, grading config.GradingConfig) model.GradeSummary {
+	var summary model.GradeSummary
+	var weighted float64
+	honorable := len(records) > 0
+	for _, record := range records {
+		if GradeRemark(record, grading) != model.RemarkPassed {
+			honorable = false
+		}
+		if record.FinalGrade == nil || record.GradeRemark != "" || record.Units <= 0 {
+			continue
+		}
+		weighted += *record.FinalGrade * record.Units
+		summary.Units += record.Units
+	}
+	if summary.Units == 0 {
+		return summary
+	}
+	gwa := math.Round(weighted/summary.Units*100) / 100
+	summary.GWA = &gwa
+
+	if honorable {
+		for _, honor := range grading.Honors {
+			if grading.AtLeast(gwa, honor.GWA) {
+				summary.Honors = honor.Name
+				break
+			}
+		}
+	}
+	return summary
+}
+
+// FormatGrade formats a grade with two decimal places, or "-" if it has not been given yet.
+func FormatGrade(grade *float64) string {
+	if grade == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f", *grade)
+}
+
+// SummarizeTranscript fills in the remark of every subject of a transcript, the
+// GWA of each term and the cumulative GWA over all terms.
+func SummarizeTranscript(transcript *model.Transcript, grading config.GradingConfig) {
+	var all []model.GradesRecord
+	for i := range transcript.Terms {
+		term := &transcript.Terms[i]
+		records := make([]model.GradesRecord, len(term.Grades))
+		for j := range term.Grades {
+			term.Grades[j].Remark = GradeRemark(term.Grades[j].GradesRecord, grading)
+			records[j] = term.Grades[j].GradesRecord
+		}
+		term.Summary = SummarizeGrades(records, grading)
+		all = append(all, records...)
+	}
+	transcript.Cumulative = SummarizeGrades(all, grading)
+}
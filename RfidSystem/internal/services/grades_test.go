@@ -0,0 +1,205 @@
+package services
+
+import (
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+	"testing"
+)
+
+func grade(value float64) *float64 {
+	return &value
+}
+
+func TestComputeFinalGrade(t *testing.T) {
+	full := model.RosterEntry{PrelimGrade: grade(1.5), MidtermGrade: grade(1.75), PrefinalGrade: grade(2.0), FinalTermGrade: grade(1.25)}
+
+	tests := []struct {
+		name    string
+		entry   model.RosterEntry
+		weights map[string]float64
+		want    *float64
+	}{
+		{
+			name:    "weighted average",
+			entry:   full,
+			weights: map[string]float64{model.PeriodPrelim: 0.2, model.PeriodMidterm: 0.2, model.PeriodPrefinal: 0.2, model.PeriodFinal: 0.4},
+			want:    grade(1.55),
+		},
+		{
+			name:    "weights normalized to their sum",
+			entry:   full,
+			weights: map[string]float64{model.PeriodPrelim: 1, model.PeriodFinal: 3},
+			want:    grade(1.31),
+		},
+		{
+			name:    "period without a weight may be ungraded",
+			entry:   model.RosterEntry{PrelimGrade: grade(2.0), FinalTermGrade: grade(1.0)},
+			weights: map[string]float64{model.PeriodPrelim: 1, model.PeriodMidterm: 0, model.PeriodFinal: 1},
+			want:    grade(1.5),
+		},
+		{
+			name:    "weighted period not graded yet",
+			entry:   model.RosterEntry{PrelimGrade: grade(1.5), MidtermGrade: grade(1.75), PrefinalGrade: grade(2.0)},
+			weights: map[string]float64{model.PeriodPrelim: 1, model.PeriodMidterm: 1, model.PeriodPrefinal: 1, model.PeriodFinal: 1},
+		},
+		{
+			name:    "percent grades rounded to two decimals",
+			entry:   model.RosterEntry{PrelimGrade: grade(85), MidtermGrade: grade(90.5), PrefinalGrade: grade(88), FinalTermGrade: grade(91.25)},
+			weights: map[string]float64{model.PeriodPrelim: 1, model.PeriodMidterm: 1, model.PeriodPrefinal: 1, model.PeriodFinal: 1},
+			want:    grade(88.69),
+		},
+		{
+			name:  "no weights",
+			entry: full,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeFinalGrade(tt.entry, tt.weights)
+			if FormatGrade(got) != FormatGrade(tt.want) {
+				t.Errorf("got %s, want %s", FormatGrade(got), FormatGrade(tt.want))
+			}
+		})
+	}
+}
+
+func TestSummarizeGrades(t *testing.T) {
+	point := config.GradingConfig{
+		Scale:        config.ScalePoint,
+		PassingGrade: 3.0,
+		Honors: []config.HonorThreshold{
+			{Name: "Summa Cum Laude", GWA: 1.20},
+			{Name: "Magna Cum Laude", GWA: 1.45},
+			{Name: "Cum Laude", GWA: 1.75},
+		},
+	}
+	percent := config.GradingConfig{
+		Scale:        config.ScalePercent,
+		PassingGrade: 75,
+		Honors: []config.HonorThreshold{
+			{Name: "Summa Cum Laude", GWA: 97},
+			{Name: "Magna Cum Laude", GWA: 94},
+			{Name: "Cum Laude", GWA: 90},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		grading    config.GradingConfig
+		records    []model.GradesRecord
+		wantGWA    *float64
+		wantUnits  float64
+		wantHonors string
+	}{
+		{
+			name:    "no grades",
+			grading: point,
+		},
+		{
+			name:    "weighted by units, honor on its threshold",
+			grading: point,
+			records: []model.GradesRecord{
+				{Units: 3, FinalGrade: grade(1.25)},
+				{Units: 2, FinalGrade: grade(1.75)},
+			},
+			wantGWA:    grade(1.45),
+			wantUnits:  5,
+			wantHonors: "Magna Cum Laude",
+		},
+		{
+			name:    "GWA rounded to two decimals",
+			grading: point,
+			records: []model.GradesRecord{
+				{Units: 3, FinalGrade: grade(1.25)},
+				{Units: 3, FinalGrade: grade(1.5)},
+				{Units: 1, FinalGrade: grade(1.75)},
+			},
+			wantGWA:    grade(1.43),
+			wantUnits:  7,
+			wantHonors: "Magna Cum Laude",
+		},
+		{
+			name:    "INC and DRP left out of the GWA and the honors",
+			grading: point,
+			records: []model.GradesRecord{
+				{Units: 3, FinalGrade: grade(1.0)},
+				{Units: 3, FinalGrade: grade(2.5), GradeRemark: model.RemarkIncomplete},
+				{Units: 3, GradeRemark: model.RemarkDropped},
+			},
+			wantGWA:   grade(1.0),
+			wantUnits: 3,
+		},
+		{
+			name:    "failed subject counts but bars honors",
+			grading: point,
+			records: []model.GradesRecord{
+				{Units: 3, FinalGrade: grade(1.0)},
+				{Units: 3, FinalGrade: grade(1.0)},
+				{Units: 3, FinalGrade: grade(3.25)},
+			},
+			wantGWA:   grade(1.75),
+			wantUnits: 9,
+		},
+		{
+			name:    "ungraded subject bars honors",
+			grading: point,
+			records: []model.GradesRecord{
+				{Units: 3, FinalGrade: grade(1.0)},
+				{Units: 3},
+			},
+			wantGWA:   grade(1.0),
+			wantUnits: 3,
+		},
+		{
+			name:    "subject without units left out of the GWA",
+			grading: point,
+			records: []model.GradesRecord{
+				{Units: 3, FinalGrade: grade(1.5)},
+				{Units: 0, FinalGrade: grade(2.75)},
+			},
+			wantGWA:    grade(1.5),
+			wantUnits:  3,
+			wantHonors: "Cum Laude",
+		},
+		{
+			name:    "percent scale, higher is better",
+			grading: percent,
+			records: []model.GradesRecord{
+				{Units: 3, FinalGrade: grade(96)},
+				{Units: 3, FinalGrade: grade(98)},
+			},
+			wantGWA:    grade(97),
+			wantUnits:  6,
+			wantHonors: "Summa Cum Laude",
+		},
+		{
+			name:    "percent scale below every honor",
+			grading: percent,
+			records: []model.GradesRecord{
+				{Units: 3, FinalGrade: grade(89.99)},
+			},
+			wantGWA:   grade(89.99),
+			wantUnits: 3,
+		},
+		{
+			name:    "percent scale failing grade",
+			grading: percent,
+			records: []model.GradesRecord{
+				{Units: 3, FinalGrade: grade(99)},
+				{Units: 3, FinalGrade: grade(74)},
+			},
+			wantGWA:   grade(86.5),
+			wantUnits: 6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := SummarizeGrades(tt.records, tt.grading)
+			if FormatGrade(summary.GWA) != FormatGrade(tt.wantGWA) || summary.Units != tt.wantUnits || summary.Honors != tt.wantHonors {
+				t.Errorf("got GWA %s, %g units, honors %q; want GWA %s, %g units, honors %q",
+					FormatGrade(summary.GWA), summary.Units, summary.Honors,
+					FormatGrade(tt.wantGWA), tt.wantUnits, tt.wantHonors)
+			}
+		})
+	}
+}
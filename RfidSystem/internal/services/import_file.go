@@ -0,0 +1,270 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"rfidsystem/internal/model"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// importColumns lists the columns each kind of import file must have.
+var importColumns = map[string][]string{
+	model.ImportStudents:    {"student_id", "first_name", "last_name", "year_level", "program"},
+	model.ImportEnrollments: {"student_id", "subject_code", "term_id"},
+	model.ImportCards:       {"card_uid", "student_id"},
+	model.ImportPayments:    {"student_id", "term_id", "amount", "payment_method"},
+}
+
+// MissingImportColumns returns the required columns of importType that table lacks.
+func MissingImportColumns(importType string, table *model.ImportTable) []string {
+	present := make(map[string]bool, len(table.Columns))
+	for _, column := range table.Columns {
+		present[column] = true
+	}
+	var missing []string
+	for _, column := range importColumns[importType] {
+		if !present[column] {
+			missing = append(missing, column)
+		}
+	}
+	return missing
+}
+
+// ReadImportFile reads a CSV or XLSX file, chosen by the file name's extension.
+// The first non-blank row names the columns; names are lowercased and spaces become
+// underscores, so "Student ID" is read as "student_id". Blank rows are skipped.
+// Only the first worksheet of an XLSX workbook is read.
+func ReadImportFile(fileName string, data []byte) (*model.ImportTable, error) {
+	var records [][]string
+	var err error
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		records, err = readCSV(data)
+	case ".xlsx":
+		records, err = readXLSX(data)
+	default:
+		return nil, fmt.Errorf("unsupported file type %q, expected .csv or .xlsx", filepath.Ext(fileName))
+	}
+	if err != nil {
+		return nil, err
+	}
+	header := 0
+	for header < len(records) && strings.TrimSpace(strings.Join(records[header], "")) == "" {
+		header++
+	}
+	if header == len(records) {
+		return nil, fmt.Errorf("the file is empty")
+	}
+
+	table := &model.ImportTable{}
+	for _, name := range records[header] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		table.Columns = append(table.Columns, strings.ReplaceAll(name, " ", "_"))
+	}
+	for i := header + 1; i < len(records); i++ {
+		record := records[i]
+		row := model.ImportRow{Line: i + 1, Values: make(map[string]string, len(table.Columns))}
+		blank := true
+		for j, column := range table.Columns {
+			if j < len(record) && column != "" {
+				row.Values[column] = strings.TrimSpace(record[j])
+				blank = blank && row.Values[column] == ""
+			}
+		}
+		if !blank {
+			table.Rows = append(table.Rows, row)
+		}
+	}
+	return table, nil
+}
+
+// ParseImportDate parses a date from an import file, in YYYY-MM-DD or as the day
+// number spreadsheets store dates as, and returns it in YYYY-MM-DD.
+func ParseImportDate(value string) (string, error) {
+	if serial, err := strconv.Atoi(value); err == nil && serial > 0 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, serial).Format("2006-01-02"), nil
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return "", fmt.Errorf("must be a date in YYYY-MM-DD format")
+	}
+	return value, nil
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV: %v", err)
+	}
+	return records, nil
+}
+
+type xlsxText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxText) String() string {
+	s := t.T
+	for _, run := range t.Runs {
+		s += run.T
+	}
+	return s
+}
+
+// readXLSX reads the cell values of the first worksheet of an XLSX workbook as text,
+// one slice per spreadsheet row.
+func readXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading XLSX: %v", err)
+	}
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+	decode := func(name string, v any) error {
+		f, ok := files[name]
+		if !ok {
+			return fmt.Errorf("error reading XLSX: %s is missing", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("error reading XLSX %s: %v", name, err)
+		}
+		defer rc.Close()
+		if err := xml.NewDecoder(io.LimitReader(rc, 64<<20)).Decode(v); err != nil {
+			return fmt.Errorf("error reading XLSX %s: %v", name, err)
+		}
+		return nil
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			RelID string `xml:"id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decode("xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("error reading XLSX: the workbook has no worksheets")
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	sheetPath := ""
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RelID {
+			sheetPath = rel.Target
+			if strings.HasPrefix(sheetPath, "/") {
+				sheetPath = strings.TrimPrefix(sheetPath, "/")
+			} else {
+				sheetPath = path.Join("xl", sheetPath)
+			}
+		}
+	}
+	if sheetPath == "" {
+		return nil, fmt.Errorf("error reading XLSX: the first worksheet is missing")
+	}
+
+	var sharedStrings struct {
+		Items []xlsxText `xml:"si"`
+	}
+	if _, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decode("xl/sharedStrings.xml", &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Number int `xml:"r,attr"`
+			Cells  []struct {
+				Ref    string    `xml:"r,attr"`
+				Type   string    `xml:"t,attr"`
+				Value  string    `xml:"v"`
+				Inline *xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := decode(sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+
+	var records [][]string
+	for _, row := range sheet.Rows {
+		number := row.Number
+		if number == 0 {
+			number = len(records) + 1
+		}
+		// Keep the spreadsheet's row numbers so errors can point to them
+		for len(records) < number-1 {
+			records = append(records, nil)
+		}
+		var record []string
+		for _, cell := range row.Cells {
+			col := xlsxColumn(cell.Ref)
+			if col < 0 {
+				col = len(record)
+			}
+			for len(record) <= col {
+				record = append(record, "")
+			}
+			switch cell.Type {
+			case "s":
+				i, err := strconv.Atoi(cell.Value)
+				if err != nil || i < 0 || i >= len(sharedStrings.Items) {
+					return nil, fmt.Errorf("error reading XLSX: cell %s refers to a missing string", cell.Ref)
+				}
+				record[col] = sharedStrings.Items[i].String()
+			case "inlineStr":
+				if cell.Inline != nil {
+					record[col] = cell.Inline.String()
+				}
+			case "b":
+				record[col] = map[string]string{"1": "TRUE", "0": "FALSE"}[cell.Value]
+			case "", "n":
+				// Numbers are stored in full precision; read 1500.0 as 1500
+				if f, err := strconv.ParseFloat(cell.Value, 64); err == nil {
+					record[col] = strconv.FormatFloat(f, 'f', -1, 64)
+				} else {
+					record[col] = cell.Value
+				}
+			default:
+				record[col] = cell.Value
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// xlsxColumn returns the zero-based column index of a cell reference such as "AB12".
+func xlsxColumn(ref string) int {
+	col := 0
+	for _, c := range ref {
+		if c < 'A' || c > 'Z' {
+			break
+		}
+		col = col*26 + int(c-'A'+1)
+	}
+	return col - 1
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"math"
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+	"sort"
+	"time"
+)
+
+// dueDateLayouts are the formats payment schedule due dates come in, raw from
+// the database or already formatted for display.
+var dueDateLayouts = []string{time.RFC3339, "2006-01-02", "01-02-2006"}
+
+// EvaluateInstallments determines the status of each installment of a payment
+// schedule as of the given date. The total paid on the assessment is applied to
+// the installments in sort order; an installment that is not fully covered after
+// its due date is overdue, and is charged the late penalty once the grace period
+// has passed.
+func EvaluateInstallments(schedules []model.PaymentSchedule, totalPaid float64, asOf time.Time, penalty config.LatePenaltyConfig) []model.InstallmentStatus {
+	ordered := make([]model.PaymentSchedule, len(schedules))
+	copy(ordered, schedules)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].SortOrder < ordered[j].SortOrder })
+
+	today := truncateDay(asOf)
+	remaining := totalPaid
+	statuses := make([]model.InstallmentStatus, 0, len(ordered))
+	for _, schedule := range ordered {
+		paid := math.Max(0, math.Min(remaining, schedule.ExpectedAmount))
+		remaining -= paid
+
+		status := model.InstallmentStatus{
+			ScheduleID:      schedule.ID,
+			TermDescription: schedule.TermDescription,
+			DueDate:         schedule.DueDate,
+			ExpectedAmount:  schedule.ExpectedAmount,
+			PaidAmount:      roundCentavos(paid),
+			Outstanding:     roundCentavos(schedule.ExpectedAmount - paid),
+		}
+
+		dueDate, hasDueDate := parseDueDate(schedule.DueDate)
+		switch {
+		case status.Outstanding <= 0:
+			status.Outstanding = 0
+			status.Status = model.InstallmentPaid
+		case hasDueDate && today.After(dueDate):
+			status.Status = model.InstallmentOverdue
+			status.DaysOverdue = int(today.Sub(dueDate).Hours() / 24)
+			if status.DaysOverdue > penalty.GraceDays {
+				status.Penalty = roundCentavos(status.Outstanding*penalty.Rate/100 + penalty.Flat)
+			}
+		case status.PaidAmount > 0:
+			status.Status = model.InstallmentPartial
+		default:
+			status.Status = model.InstallmentUpcoming
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// SummarizeDelinquency builds the delinquent accounts report row of an assessment.
+// ok is false if none of its installments are overdue.
+func SummarizeDelinquency(assessment model.AssessmentStanding, asOf time.Time, penalty config.LatePenaltyConfig) (account model.DelinquentAccount, ok bool) {
+	account = model.DelinquentAccount{
+		AssessmentNumber: assessment.AssessmentNumber,
+		StudentID:        assessment.StudentID,
+		StudentName:      assessment.StudentName,
+		Program:          assessment.Program,
+		BlockSection:     assessment.BlockSection,
+		RemainingBalance: assessment.RemainingBalance,
+	}
+	for _, installment := range EvaluateInstallments(assessment.Schedules, assessment.TotalPaymentAmount, asOf, penalty) {
+		if installment.Status != model.InstallmentOverdue {
+			continue
+		}
+		account.OverdueInstallments++
+		account.OverdueAmount = roundCentavos(account.OverdueAmount + installment.Outstanding)
+		account.Penalty = roundCentavos(account.Penalty + installment.Penalty)
+		if installment.DaysOverdue > account.DaysOverdue {
+			account.DaysOverdue = installment.DaysOverdue
+			account.OldestDueDate = installment.DueDate
+		}
+	}
+	return account, account.OverdueInstallments > 0
+}
+
+// parseDueDate parses a payment schedule due date in any of the known formats.
+func parseDueDate(value string) (time.Time, bool) {
+	for _, layout := range dueDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return truncateDay(t), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+func roundCentavos(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
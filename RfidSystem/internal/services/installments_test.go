@@ -0,0 +1,238 @@
+package services
+
+import (
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+	"testing"
+	"time"
+)
+
+func at(value string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// installment is the part of an InstallmentStatus the tests check.
+type installment struct {
+	status      string
+	paid        float64
+	outstanding float64
+	daysOverdue int
+	penalty     float64
+}
+
+func TestEvaluateInstallments(t *testing.T) {
+	// Listed out of order; installments are paid in sort order
+	schedules := []model.PaymentSchedule{
+		{ID: 3, TermDescription: "Finals", DueDate: "2025-10-01T00:00:00Z", ExpectedAmount: 1999.50, SortOrder: 2},
+		{ID: 1, TermDescription: "Initial Payment", DueDate: "2025-08-01", ExpectedAmount: 1000, SortOrder: 0},
+		{ID: 2, TermDescription: "Midterm", DueDate: "09-01-2025", ExpectedAmount: 2000.50, SortOrder: 1},
+	}
+	penalty := config.LatePenaltyConfig{Rate: 2, Flat: 50, GraceDays: 3}
+
+	tests := []struct {
+		name      string
+		schedules []model.PaymentSchedule
+		totalPaid float64
+		asOf      time.Time
+		want      []installment
+	}{
+		{
+			name:      "nothing paid before the first due date",
+			schedules: schedules,
+			asOf:      at("2025-07-15 09:00"),
+			want: []installment{
+				{status: model.InstallmentUpcoming, outstanding: 1000},
+				{status: model.InstallmentUpcoming, outstanding: 2000.50},
+				{status: model.InstallmentUpcoming, outstanding: 1999.50},
+			},
+		},
+		{
+			name:      "partial payment before the due date",
+			schedules: schedules,
+			totalPaid: 400,
+			asOf:      at("2025-07-31 09:00"),
+			want: []installment{
+				{status: model.InstallmentPartial, paid: 400, outstanding: 600},
+				{status: model.InstallmentUpcoming, outstanding: 2000.50},
+				{status: model.InstallmentUpcoming, outstanding: 1999.50},
+			},
+		},
+		{
+			name:      "exact payoff of the first installment",
+			schedules: schedules,
+			totalPaid: 1000,
+			asOf:      at("2025-08-20 09:00"),
+			want: []installment{
+				{status: model.InstallmentPaid, paid: 1000},
+				{status: model.InstallmentUpcoming, outstanding: 2000.50},
+				{status: model.InstallmentUpcoming, outstanding: 1999.50},
+			},
+		},
+		{
+			name:      "due later the same day",
+			schedules: schedules,
+			totalPaid: 1000,
+			asOf:      at("2025-09-01 23:30"),
+			want: []installment{
+				{status: model.InstallmentPaid, paid: 1000},
+				{status: model.InstallmentUpcoming, outstanding: 2000.50},
+				{status: model.InstallmentUpcoming, outstanding: 1999.50},
+			},
+		},
+		{
+			name:      "overdue on the last day of the grace period",
+			schedules: schedules,
+			totalPaid: 1500,
+			asOf:      at("2025-09-04 08:00"),
+			want: []installment{
+				{status: model.InstallmentPaid, paid: 1000},
+				{status: model.InstallmentOverdue, paid: 500, outstanding: 1500.50, daysOverdue: 3},
+				{status: model.InstallmentUpcoming, outstanding: 1999.50},
+			},
+		},
+		{
+			name:      "penalty the day after the grace period",
+			schedules: schedules,
+			totalPaid: 1500,
+			asOf:      at("2025-09-05 08:00"),
+			want: []installment{
+				{status: model.InstallmentPaid, paid: 1000},
+				// 2% of 1500.50 is 30.01, plus the flat 50
+				{status: model.InstallmentOverdue, paid: 500, outstanding: 1500.50, daysOverdue: 4, penalty: 80.01},
+				{status: model.InstallmentUpcoming, outstanding: 1999.50},
+			},
+		},
+		{
+			name:      "rounding remainders of a fractional payment",
+			schedules: schedules,
+			totalPaid: 1000.333,
+			asOf:      at("2025-08-20 09:00"),
+			want: []installment{
+				{status: model.InstallmentPaid, paid: 1000},
+				{status: model.InstallmentPartial, paid: 0.33, outstanding: 2000.17},
+				{status: model.InstallmentUpcoming, outstanding: 1999.50},
+			},
+		},
+		{
+			name:      "overpayment covers every installment",
+			schedules: schedules,
+			totalPaid: 6000,
+			asOf:      at("2025-12-01 09:00"),
+			want: []installment{
+				{status: model.InstallmentPaid, paid: 1000},
+				{status: model.InstallmentPaid, paid: 2000.50},
+				{status: model.InstallmentPaid, paid: 1999.50},
+			},
+		},
+		{
+			name: "installment without a readable due date is never overdue",
+			schedules: []model.PaymentSchedule{
+				{ID: 1, TermDescription: "Balance", DueDate: "TBA", ExpectedAmount: 500},
+			},
+			asOf: at("2026-01-01 09:00"),
+			want: []installment{
+				{status: model.InstallmentUpcoming, outstanding: 500},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			statuses := EvaluateInstallments(tt.schedules, tt.totalPaid, tt.asOf, penalty)
+			if len(statuses) != len(tt.want) {
+				t.Fatalf("got %d installments, want %d", len(statuses), len(tt.want))
+			}
+			for i, status := range statuses {
+				got := installment{status.Status, status.PaidAmount, status.Outstanding, status.DaysOverdue, status.Penalty}
+				if got != tt.want[i] {
+					t.Errorf("installment %d (%s) = %+v, want %+v", i, status.TermDescription, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSummarizeDelinquency(t *testing.T) {
+	penalty := config.LatePenaltyConfig{Rate: 1, GraceDays: 5}
+	standing := model.AssessmentStanding{
+		AssessmentNumber: 12,
+		StudentID:        "2023-001",
+		StudentName:      "Ana Cruz",
+		RemainingBalance: 4500,
+		Schedules: []model.PaymentSchedule{
+			{ID: 1, DueDate: "2025-08-01", ExpectedAmount: 1500, SortOrder: 0},
+			{ID: 2, DueDate: "2025-09-01", ExpectedAmount: 1500, SortOrder: 1},
+			{ID: 3, DueDate: "2025-10-01", ExpectedAmount: 1500, SortOrder: 2},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		totalPaid float64
+		asOf      time.Time
+		want      model.DelinquentAccount
+		wantOK    bool
+	}{
+		{
+			name: "nothing overdue yet",
+			asOf: at("2025-08-01 17:00"),
+		},
+		{
+			name:      "paid up to date",
+			totalPaid: 3000,
+			asOf:      at("2025-09-20 09:00"),
+		},
+		{
+			name: "two installments overdue",
+			asOf: at("2025-09-03 09:00"),
+			want: model.DelinquentAccount{
+				OverdueInstallments: 2,
+				OverdueAmount:       3000,
+				// Only the first is past its grace period: 1% of 1500
+				Penalty:       15,
+				OldestDueDate: "2025-08-01",
+				DaysOverdue:   33,
+			},
+			wantOK: true,
+		},
+		{
+			name:      "partly paid installment overdue",
+			totalPaid: 1750.25,
+			asOf:      at("2025-09-10 09:00"),
+			want: model.DelinquentAccount{
+				OverdueInstallments: 1,
+				OverdueAmount:       1249.75,
+				Penalty:             12.50,
+				OldestDueDate:       "2025-09-01",
+				DaysOverdue:         9,
+			},
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standing := standing
+			standing.TotalPaymentAmount = tt.totalPaid
+			account, ok := SummarizeDelinquency(standing, tt.asOf, penalty)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if account.AssessmentNumber != 12 || account.StudentID != "2023-001" || account.RemainingBalance != 4500 {
+				t.Errorf("account %+v does not describe the assessment", account)
+			}
+			got := model.DelinquentAccount{
+				OverdueInstallments: account.OverdueInstallments,
+				OverdueAmount:       account.OverdueAmount,
+				Penalty:             account.Penalty,
+				OldestDueDate:       account.OldestDueDate,
+				DaysOverdue:         account.DaysOverdue,
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Sizes of the stored student photos: a thumbnail for admin lists and the photo
+// shown on the kiosk profile view.
+const (
+	PhotoThumb = "thumb"
+	PhotoKiosk = "kiosk"
+)
+
+// photoSides holds the side in pixels of each photo size. Photos are cropped square.
+var photoSides = map[string]int{
+	PhotoThumb: 96,
+	PhotoKiosk: 320,
+}
+
+// maxPhotoPixels bounds the size of an uploaded image, so that a small file that
+// decodes to a huge image cannot exhaust memory.
+const maxPhotoPixels = 24_000_000
+
+// IsPhotoSize reports whether size is one of the stored photo sizes.
+func IsPhotoSize(size string) bool {
+	_, ok := photoSides[size]
+	return ok
+}
+
+// ResizeStudentPhoto decodes an uploaded JPEG, PNG or GIF photo and returns it as a
+// JPEG in every photo size, keyed by size. The image is cropped to a centered square
+// and turned upright according to its EXIF orientation. Re-encoding it drops the EXIF
+// data and any other metadata, such as where a phone photo was taken.
+func ResizeStudentPhoto(data []byte) (map[string][]byte, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, fmt.Errorf("unsupported image type %s, expected a JPEG, PNG or GIF image", contentType)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("the image cannot be read: %v", err)
+	}
+	if cfg.Width < 1 || cfg.Height < 1 || int64(cfg.Width)*int64(cfg.Height) > maxPhotoPixels {
+		return nil, fmt.Errorf("the image must be at most %d megapixels", maxPhotoPixels/1_000_000)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("the image cannot be read: %v", err)
+	}
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	// Cropping and scaling a square commute with turning it, so the slow steps
+	// run on the full image only once and turning is left to the small copies
+	bounds := img.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	origin := image.Pt(bounds.Min.X+(bounds.Dx()-side)/2, bounds.Min.Y+(bounds.Dy()-side)/2)
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	// Transparent areas of PNG and GIF images become white, as JPEG has no transparency
+	draw.Draw(square, square.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(square, square.Bounds(), img, origin, draw.Over)
+
+	photos := make(map[string][]byte, len(photoSides))
+	for size, photoSide := range photoSides {
+		var buf bytes.Buffer
+		photo := orient(scaleSquare(square, photoSide), orientation)
+		if err := jpeg.Encode(&buf, photo, &jpeg.Options{Quality: 85}); err != nil {
+			return nil, fmt.Errorf("error encoding %s photo: %v", size, err)
+		}
+		photos[size] = buf.Bytes()
+	}
+	return photos, nil
+}
+
+// scaleSquare resizes a square image to side pixels per side, averaging the source
+// pixels each output pixel covers.
+func scaleSquare(src *image.RGBA, side int) *image.RGBA {
+	srcSide := src.Bounds().Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	for y := 0; y < side; y++ {
+		sy0 := y * srcSide / side
+		sy1 := max((y+1)*srcSide/side, sy0+1)
+		for x := 0; x < side; x++ {
+			sx0 := x * srcSide / side
+			sx1 := max((x+1)*srcSide/side, sx0+1)
+			var r, g, b, a, n int
+			for sy := sy0; sy < sy1; sy++ {
+				i := src.PixOffset(sx0, sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					n++
+					i += 4
+				}
+			}
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / n)
+			dst.Pix[j+1] = uint8(g / n)
+			dst.Pix[j+2] = uint8(b / n)
+			dst.Pix[j+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// orient turns and flips a square image so that it is upright, given its EXIF
+// orientation from 1 (already upright) to 8.
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+	n := src.Bounds().Dx() - 1
+	dst := image.NewRGBA(src.Bounds())
+	for y := 0; y <= n; y++ {
+		for x := 0; x <= n; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // flip horizontally
+				sx, sy = n-x, y
+			case 3: // turn half way
+				sx, sy = n-x, n-y
+			case 4: // flip vertically
+				sx, sy = x, n-y
+			case 5: // flip along the diagonal
+				sx, sy = y, x
+			case 6: // turn right
+				sx, sy = y, n-x
+			case 7: // flip along the other diagonal
+				sx, sy = n-y, n-x
+			case 8: // turn left
+				sx, sy = n-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG image, or 1 (upright) if
+// it has none.
+func jpegOrientation(data []byte) int {
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			// The image data starts at SOS; EXIF always comes before it
+			break
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of EXIF TIFF data.
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		entry := ifd + 2 + e*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 1
+}
+
+// StudentPhotoPath returns the file a student's photo of the given size is stored in.
+// Photo files are named by student ID, so IDs that are not safe file names are refused.
+func StudentPhotoPath(dir, studentID, size string) (string, error) {
+	if !studentIDPattern.MatchString(studentID) || !IsPhotoSize(size) {
+		return "", fmt.Errorf("no photo can be stored for student ID %q", studentID)
+	}
+	return filepath.Join(dir, fmt.Sprintf("%s_%s.jpg", studentID, size)), nil
+}
+
+// SaveStudentPhotos stores the photos returned by ResizeStudentPhoto for a student,
+// replacing their previous photos. Each file is written in full before it replaces
+// the old one, so kiosks never read a partly written photo.
+func SaveStudentPhotos(dir, studentID string, photos map[string][]byte) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating photo directory: %v", err)
+	}
+	for size, photo := range photos {
+		path, err := StudentPhotoPath(dir, studentID, size)
+		if err != nil {
+			return err
+		}
+		tmp, err := os.CreateTemp(dir, ".upload-*")
+		if err != nil {
+			return fmt.Errorf("error creating photo file: %v", err)
+		}
+		_, err = tmp.Write(photo)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), path)
+		}
+		if err != nil {
+			os.Remove(tmp.Name())
+			return fmt.Errorf("error writing %s photo: %v", size, err)
+		}
+	}
+	return nil
+}
+
+// DeleteStudentPhotos removes every stored photo of a student.
+func DeleteStudentPhotos(dir, studentID string) error {
+	for size := range photoSides {
+		path, err := StudentPhotoPath(dir, studentID, size)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error deleting %s photo: %v", size, err)
+		}
+	}
+	return nil
+}
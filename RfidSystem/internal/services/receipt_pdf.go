@@ -0,0 +1,77 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// RenderReceiptPDF writes an official receipt as a single page PDF. The receipt's
+// content hash is printed at the bottom so a printed or downloaded copy can be
+// checked against the stored receipt.
+func RenderReceiptPDF(w io.Writer, receipt *model.OfficialReceipt, billing config.BillingConfig) error {
+	pdf := fpdf.New("P", "mm", "A5", "")
+	pdf.SetTitle(fmt.Sprintf("Official Receipt %s", receipt.FormattedNumber()), false)
+	pdf.SetCreator("RFID System", false)
+	pdf.SetMargins(12, 12, 12)
+	pdf.AddPage()
+
+	if billing.InstitutionName != "" {
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 8, billing.InstitutionName, "", 1, "C", false, 0, "")
+	}
+	if billing.InstitutionAddress != "" {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(0, 5, billing.InstitutionAddress, "", 1, "C", false, 0, "")
+	}
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "OFFICIAL RECEIPT", "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.CellFormat(0, 6, fmt.Sprintf("No. %s", receipt.FormattedNumber()), "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+
+	row := func(label, value string) {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(42, 7, label, "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(0, 7, value, "", "L", false)
+	}
+	optional := func(s *string) string {
+		if s == nil {
+			return "-"
+		}
+		return *s
+	}
+
+	row("Date issued", receipt.IssuedAt.Format("January 2, 2006 3:04 PM"))
+	row("Student ID", receipt.StudentID)
+	row("Student name", receipt.StudentName)
+	row("Term", optional(receipt.Term))
+	row("Assessment no.", fmt.Sprintf("%d", receipt.AssessmentNumber))
+	pdf.Ln(2)
+	row("Description", optional(receipt.Description))
+	row("Payment method", receipt.PaymentMethod)
+	row("Reference no.", optional(receipt.ReferenceNumber))
+	pdf.Ln(2)
+
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(42, 9, "Amount paid", "TB", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 9, FormatPeso(receipt.Amount), "TB", 1, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.CellFormat(42, 7, "Remaining balance", "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 7, FormatPeso(receipt.BalanceAfter), "", 1, "R", false, 0, "")
+	pdf.Ln(8)
+
+	pdf.CellFormat(0, 6, fmt.Sprintf("Received by: %s", receipt.IssuedBy), "", 1, "L", false, 0, "")
+	pdf.Ln(6)
+
+	pdf.SetFont("Courier", "", 7)
+	pdf.MultiCell(0, 4, fmt.Sprintf("Verification: %s", receipt.ContentHash), "", "L", false)
+	pdf.SetFont("Helvetica", "I", 7)
+	pdf.MultiCell(0, 4, "This receipt is valid only if the verification code matches the receipt on record.", "", "L", false)
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// RenderStatementPDF writes a student's statement of account as a PDF. Every page
+// carries the school header, and the footer shows the page number and the time
+// the statement was generated.
+func RenderStatementPDF(w io.Writer, statement *model.StatementOfAccount, billing config.BillingConfig) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("Statement of Account %s", statement.Student.StudentID), false)
+	pdf.SetCreator("RFID System", false)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("")
+
+	pdf.SetHeaderFunc(func() {
+		if billing.InstitutionName != "" {
+			pdf.SetFont("Helvetica", "B", 14)
+			pdf.CellFormat(0, 7, billing.InstitutionName, "", 1, "C", false, 0, "")
+		}
+		if billing.InstitutionAddress != "" {
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.CellFormat(0, 5, billing.InstitutionAddress, "", 1, "C", false, 0, "")
+		}
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(0, 8, "STATEMENT OF ACCOUNT", "B", 1, "C", false, 0, "")
+		pdf.Ln(3)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Generated %s", statement.GeneratedAt.Format("January 2, 2006 3:04 PM")), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	student := statement.Student
+	bills := statement.Bills
+	assessment := bills.Assessment
+
+	info := func(label, value string) {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(35, 6, label, "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 6, value, "", 1, "L", false, 0, "")
+	}
+	info("Student ID", student.StudentID)
+	info("Name", studentFullName(student))
+	if student.Program != nil {
+		program := *student.Program
+		if student.YearLevel != nil {
+			program = fmt.Sprintf("%s - %s Year", program, GetYearLevelString(*student.YearLevel))
+		}
+		info("Program", program)
+	}
+	if statement.Term != nil {
+		info("Term", *statement.Term)
+	}
+	info("Assessment no.", fmt.Sprintf("%d", assessment.ID))
+	pdf.Ln(4)
+
+	section := func(title string) {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetFillColor(230, 230, 230)
+		pdf.CellFormat(0, 7, title, "", 1, "L", true, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+	}
+	line := func(label, amount string, bold bool) {
+		style := ""
+		border := ""
+		if bold {
+			style = "B"
+			border = "T"
+		}
+		pdf.SetFont("Helvetica", style, 10)
+		pdf.CellFormat(130, 6, label, border, 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, amount, border, 1, "R", false, 0, "")
+	}
+
+	section("Assessed Fees")
+	for _, category := range FeeCategories(bills.FeeBreakdown) {
+		fees := FeesByCategory(bills.FeeBreakdown, category)
+		pdf.SetFont("Helvetica", "BI", 10)
+		pdf.CellFormat(0, 6, category, "", 1, "L", false, 0, "")
+		for _, fee := range fees {
+			line("    "+fee.Name, FormatPeso(fee.Amount), false)
+		}
+		line(fmt.Sprintf("Total %s", category), FormatPeso(SumFees(fees)), true)
+	}
+	line("Total Fee Amount", FormatPeso(assessment.TotalFeeAmount), true)
+	pdf.Ln(3)
+
+	section("Discounts")
+	if len(bills.Discounts) == 0 {
+		line("No discounts applied", "", false)
+	}
+	for _, discount := range bills.Discounts {
+		label := discount.Name
+		if discount.IsPercentage {
+			label = fmt.Sprintf("%s (%g%%)", discount.Name, discount.Value)
+		}
+		line(label, "-"+FormatPeso(discount.AppliedAmount), false)
+	}
+	line("Total Discounts", "-"+FormatPeso(assessment.TotalDiscountAmount), true)
+	line("Net Assessment", FormatPeso(assessment.NetAssessmentAmount), true)
+	pdf.Ln(3)
+
+	section("Payment History")
+	if len(bills.PaymentHistory) == 0 {
+		line("No payments recorded", "", false)
+	}
+	for _, payment := range bills.PaymentHistory {
+		description := "Payment"
+		if payment.Description != nil {
+			description = *payment.Description
+		}
+		if payment.ReferenceNumber != nil {
+			description = fmt.Sprintf("%s (Ref. %s)", description, *payment.ReferenceNumber)
+		}
+		line(fmt.Sprintf("%s  %s", payment.PaymentDate, description), FormatPeso(payment.Amount), false)
+	}
+	line("Total Payments", FormatPeso(assessment.TotalPaymentAmount), true)
+	pdf.Ln(3)
+
+	section("Payment Schedule")
+	if len(statement.PaymentSchedules) == 0 {
+		line("No payment schedule", "", false)
+	}
+	for _, schedule := range statement.PaymentSchedules {
+		line(fmt.Sprintf("%s  (due %s)", schedule.TermDescription, schedule.DueDate), FormatPeso(schedule.ExpectedAmount), false)
+	}
+	pdf.Ln(3)
+
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(130, 9, "REMAINING BALANCE", "TB", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 9, FormatPeso(assessment.RemainingBalance), "TB", 1, "R", false, 0, "")
+
+	return pdf.Output(w)
+}
+
+// studentFullName returns "Last, First Middle" for the parts of the name that are set.
+func studentFullName(student *model.Student) string {
+	var given []string
+	for _, part := range []*string{student.FirstName, student.MiddleName} {
+		if part != nil && *part != "" {
+			given = append(given, *part)
+		}
+	}
+	if student.LastName == nil || *student.LastName == "" {
+		return strings.Join(given, " ")
+	}
+	if len(given) == 0 {
+		return *student.LastName
+	}
+	return *student.LastName + ", " + strings.Join(given, " ")
+}
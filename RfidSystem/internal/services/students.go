@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"rfidsystem/internal/model"
+	"strings"
+	"time"
+)
+
+var (
+	studentIDPattern    = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]{0,49}$`)
+	contactPattern      = regexp.MustCompile(`^\+?[0-9]{7,15}$`)
+	programPattern      = regexp.MustCompile(`^[A-Z][A-Z0-9-]{1,19}$`)
+	blockSectionPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 -]{0,19}$`)
+)
+
+// NormalizeStudent tidies the fields of a student record entered by hand: text is
+// trimmed and empty optional fields become NULL, the program code is uppercased,
+// the email lowercased and spaces and hyphens are removed from the contact number.
+func NormalizeStudent(student *model.Student) {
+	optional := func(s **string, transform func(string) string) {
+		if *s == nil {
+			return
+		}
+		v := transform(strings.TrimSpace(**s))
+		if v == "" {
+			*s = nil
+			return
+		}
+		*s = &v
+	}
+	same := func(s string) string { return s }
+	student.StudentID = strings.TrimSpace(student.StudentID)
+	optional(&student.FirstName, same)
+	optional(&student.LastName, same)
+	optional(&student.MiddleName, same)
+	optional(&student.Birthday, same)
+	optional(&student.ContactNumber, strings.NewReplacer(" ", "", "-", "").Replace)
+	optional(&student.Email, strings.ToLower)
+	optional(&student.Program, strings.ToUpper)
+	optional(&student.BlockSection, same)
+}
+
+// ValidateStudent checks the fields of a student record and returns a message for
+// every invalid field, keyed by its JSON name. It returns nil if the record is valid.
+// Whether the department exists is checked by the repository.
+func ValidateStudent(student *model.Student) map[string]string {
+	errs := map[string]string{}
+	if !studentIDPattern.MatchString(student.StudentID) {
+		errs["student_id"] = "must be 1 to 50 letters, digits or hyphens"
+	}
+	if isBlank(student.FirstName) {
+		errs["first_name"] = "is required"
+	}
+	if isBlank(student.LastName) {
+		errs["last_name"] = "is required"
+	}
+	if student.Email != nil {
+		if addr, err := mail.ParseAddress(*student.Email); err != nil || addr.Address != *student.Email {
+			errs["email"] = "must be a valid email address"
+		}
+	}
+	if student.ContactNumber != nil && !contactPattern.MatchString(*student.ContactNumber) {
+		errs["contact_number"] = "must be 7 to 15 digits, optionally starting with +"
+	}
+	if student.YearLevel == nil || *student.YearLevel < 1 || *student.YearLevel > model.MaxYearLevel {
+		errs["year_level"] = fmt.Sprintf("must be between 1 and %d", model.MaxYearLevel)
+	}
+	if student.Program == nil || !programPattern.MatchString(*student.Program) {
+		errs["program"] = "must be a program code of 2 to 20 uppercase letters, digits or hyphens, e.g. BSIT"
+	}
+	if student.BlockSection != nil && !blockSectionPattern.MatchString(*student.BlockSection) {
+		errs["block_section"] = "must be up to 20 letters, digits, spaces or hyphens"
+	}
+	if student.DepartmentID != nil && *student.DepartmentID <= 0 {
+		errs["department_id"] = "must be a department ID"
+	}
+	if student.Birthday != nil {
+		if birthday, err := time.Parse("2006-01-02", *student.Birthday); err != nil {
+			errs["birthday"] = "must be in YYYY-MM-DD format"
+		} else if birthday.After(time.Now()) {
+			errs["birthday"] = "must not be in the future"
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func isBlank(s *string) bool {
+	return s == nil || *s == ""
+}
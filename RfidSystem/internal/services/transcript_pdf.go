@@ -0,0 +1,95 @@
+package services
+
+import (
+	"fmt"
+	"io"
+	"rfidsystem/internal/config"
+	"rfidsystem/internal/model"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// RenderTranscriptPDF writes a student's unofficial transcript of records as a PDF,
+// one table of subjects per term followed by the cumulative GWA.
+func RenderTranscriptPDF(w io.Writer, transcript *model.Transcript, billing config.BillingConfig) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle(fmt.Sprintf("Unofficial Transcript %s", transcript.Student.StudentID), false)
+	pdf.SetCreator("RFID System", false)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("")
+
+	pdf.SetHeaderFunc(func() {
+		if billing.InstitutionName != "" {
+			pdf.SetFont("Helvetica", "B", 14)
+			pdf.CellFormat(0, 7, billing.InstitutionName, "", 1, "C", false, 0, "")
+		}
+		if billing.InstitutionAddress != "" {
+			pdf.SetFont("Helvetica", "", 9)
+			pdf.CellFormat(0, 5, billing.InstitutionAddress, "", 1, "C", false, 0, "")
+		}
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(0, 8, "UNOFFICIAL TRANSCRIPT OF RECORDS", "B", 1, "C", false, 0, "")
+		pdf.Ln(3)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("Not valid without the registrar's seal. Generated %s", transcript.GeneratedAt.Format("January 2, 2006 3:04 PM")), "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	student := transcript.Student
+	info := func(label, value string) {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(35, 6, label, "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 6, value, "", 1, "L", false, 0, "")
+	}
+	info("Student ID", student.StudentID)
+	info("Name", studentFullName(student))
+	if student.Program != nil {
+		info("Program", *student.Program)
+	}
+	pdf.Ln(4)
+
+	widths := []float64{28, 92, 14, 22, 24}
+	row := func(cells []string, style, border string) {
+		pdf.SetFont("Helvetica", style, 9)
+		for i, cell := range cells {
+			align := "C"
+			if i == 1 {
+				align = "L"
+			}
+			pdf.CellFormat(widths[i], 6, cell, border, 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	if len(transcript.Terms) == 0 {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(0, 6, "No enrollments recorded", "", 1, "L", false, 0, "")
+	}
+	for _, term := range transcript.Terms {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.SetFillColor(230, 230, 230)
+		pdf.CellFormat(0, 7, fmt.Sprintf("%s, %s", term.Term.Semester, term.Term.AcademicYear), "", 1, "L", true, 0, "")
+		row([]string{"Code", "Subject", "Units", "Final Grade", "Remarks"}, "B", "B")
+		for _, grade := range term.Grades {
+			row([]string{grade.SubjectCode, grade.SubjectName, fmt.Sprintf("%g", grade.Units), FormatGrade(grade.FinalGrade), grade.Remark}, "", "")
+		}
+		row([]string{"", "Term GWA", fmt.Sprintf("%g", term.Summary.Units), FormatGrade(term.Summary.GWA), ""}, "B", "T")
+		pdf.Ln(3)
+	}
+
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.CellFormat(120, 9, "CUMULATIVE GWA", "TB", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 9, fmt.Sprintf("%s (%g units)", FormatGrade(transcript.Cumulative.GWA), transcript.Cumulative.Units), "TB", 1, "R", false, 0, "")
+	if transcript.Cumulative.Honors != "" {
+		pdf.SetFont("Helvetica", "I", 10)
+		pdf.CellFormat(0, 7, transcript.Cumulative.Honors, "", 1, "R", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
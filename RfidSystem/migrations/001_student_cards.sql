@@ -0,0 +1,18 @@
+-- Card registry: maps physical RFID card UIDs to students so that cards can be
+-- reissued without changing student numbers.
+CREATE TABLE IF NOT EXISTS StudentCards (
+    card_uid     VARCHAR(64)  NOT NULL,
+    student_ID   VARCHAR(50)  NOT NULL,
+    issued_date  DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_date DATETIME     NULL,
+    card_state   VARCHAR(20)  NOT NULL DEFAULT 'active',
+    PRIMARY KEY (card_uid),
+    KEY idx_student_cards_student (student_ID),
+    CONSTRAINT fk_student_cards_student FOREIGN KEY (student_ID) REFERENCES Students (student_ID)
+);
+
+-- Existing cards were encoded with the student number as their UID.
+-- Register them so current cards keep working after the upgrade.
+INSERT IGNORE INTO StudentCards (card_uid, student_ID, issued_date, card_state)
+SELECT student_ID, student_ID, COALESCE(first_access_timestamp, CURRENT_TIMESTAMP), 'active'
+FROM Students;
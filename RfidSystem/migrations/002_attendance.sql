@@ -0,0 +1,23 @@
+-- Attendance: IN/OUT events recorded when a card is scanned at a configured gate.
+CREATE TABLE IF NOT EXISTS AttendanceEvents (
+    attendance_id BIGINT       NOT NULL AUTO_INCREMENT,
+    student_ID    VARCHAR(50)  NOT NULL,
+    card_uid      VARCHAR(64)  NOT NULL,
+    reader_id     VARCHAR(64)  NOT NULL,
+    direction     VARCHAR(3)   NOT NULL,
+    event_time    DATETIME     NOT NULL,
+    PRIMARY KEY (attendance_id),
+    KEY idx_attendance_student_time (student_ID, event_time),
+    KEY idx_attendance_time (event_time),
+    CONSTRAINT fk_attendance_student FOREIGN KEY (student_ID) REFERENCES Students (student_ID)
+);
+
+-- Current presence state per student, toggled by every gate scan.
+CREATE TABLE IF NOT EXISTS AttendancePresence (
+    student_ID      VARCHAR(50) NOT NULL,
+    is_present      BOOLEAN     NOT NULL DEFAULT FALSE,
+    last_event_time DATETIME    NOT NULL,
+    last_reader_id  VARCHAR(64) NOT NULL,
+    PRIMARY KEY (student_ID),
+    CONSTRAINT fk_presence_student FOREIGN KEY (student_ID) REFERENCES Students (student_ID)
+);
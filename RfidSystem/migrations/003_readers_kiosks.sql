@@ -0,0 +1,22 @@
+-- Kiosk screens that subscribe to /stream?kiosk=<kiosk_id>.
+CREATE TABLE IF NOT EXISTS Kiosks (
+    kiosk_id   VARCHAR(64)  NOT NULL,
+    name       VARCHAR(100) NOT NULL,
+    location   VARCHAR(255) NULL,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (kiosk_id)
+);
+
+-- RFID readers posting to /card-scan and /card-scan-ws. A reader is paired with
+-- the kiosk whose screen displays the student tapped on it.
+CREATE TABLE IF NOT EXISTS Readers (
+    reader_id  VARCHAR(64)  NOT NULL,
+    kiosk_id   VARCHAR(64)  NULL,
+    name       VARCHAR(100) NOT NULL,
+    location   VARCHAR(255) NULL,
+    is_active  BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (reader_id),
+    KEY idx_readers_kiosk (kiosk_id),
+    CONSTRAINT fk_readers_kiosk FOREIGN KEY (kiosk_id) REFERENCES Kiosks (kiosk_id)
+);
@@ -0,0 +1,23 @@
+-- Admin accounts with bcrypt password hashes. Create accounts with
+-- `go run ./cmd/adduser -email <email>`.
+CREATE TABLE IF NOT EXISTS Users (
+    user_id       BIGINT       NOT NULL AUTO_INCREMENT,
+    email         VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    is_active     BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at    DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id),
+    UNIQUE KEY uq_users_email (email)
+);
+
+-- Login sessions. Only the SHA-256 hash of the session token is stored.
+CREATE TABLE IF NOT EXISTS Sessions (
+    token_hash   CHAR(64) NOT NULL,
+    user_id      BIGINT   NOT NULL,
+    created_at   DATETIME NOT NULL,
+    last_seen_at DATETIME NOT NULL,
+    expires_at   DATETIME NOT NULL,
+    PRIMARY KEY (token_hash),
+    KEY idx_sessions_expires (expires_at),
+    CONSTRAINT fk_sessions_user FOREIGN KEY (user_id) REFERENCES Users (user_id) ON DELETE CASCADE
+);
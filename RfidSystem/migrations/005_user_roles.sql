@@ -0,0 +1,5 @@
+-- Roles for admin accounts: super_admin, registrar, cashier or kiosk.
+-- Accounts created before roles existed keep full access.
+ALTER TABLE Users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'registrar' AFTER password_hash;
+
+UPDATE Users SET role = 'super_admin';
@@ -0,0 +1,5 @@
+-- Per-reader API keys for /card-scan and /card-scan-ws. Only the SHA-256 hash
+-- of the key is stored; the key itself is shown once when it is issued.
+ALTER TABLE Readers
+    ADD COLUMN api_key_hash      CHAR(64) NULL AFTER is_active,
+    ADD COLUMN api_key_issued_at DATETIME NULL AFTER api_key_hash;
@@ -0,0 +1,13 @@
+-- Payments recorded by cashiers through POST /assessments/:id/payments.
+-- Reference numbers identify a single payment across all assessments, so the
+-- database rejects a second payment with the same reference even when two are
+-- recorded at once. Duplicate references already in Payments must be corrected
+-- before this migration is applied.
+ALTER TABLE Payments
+    ADD COLUMN recorded_by VARCHAR(255) NULL,
+    ADD UNIQUE KEY idx_payments_reference (reference_number);
+
+-- Installments are settled once the payments on their assessment cover them,
+-- in sort_order.
+ALTER TABLE PaymentSchedule
+    ADD COLUMN settled_at DATETIME NULL;
@@ -0,0 +1,32 @@
+-- Official receipt (OR) numbers are drawn from this counter inside the payment
+-- transaction, so they are sequential and never reused.
+CREATE TABLE IF NOT EXISTS ReceiptSequence (
+    name       VARCHAR(32) NOT NULL,
+    next_value BIGINT      NOT NULL,
+    PRIMARY KEY (name)
+);
+
+INSERT IGNORE INTO ReceiptSequence (name, next_value) VALUES ('official_receipt', 1);
+
+-- One official receipt per payment. The printed fields are stored as they were
+-- when the receipt was issued so reprints match the original, and content_hash
+-- is the SHA-256 of those fields, printed on the receipt for verification.
+CREATE TABLE IF NOT EXISTS OfficialReceipts (
+    or_number         BIGINT        NOT NULL,
+    payment_id        BIGINT        NOT NULL,
+    assessment_number BIGINT        NOT NULL,
+    student_ID        VARCHAR(50)   NOT NULL,
+    student_name      VARCHAR(255)  NOT NULL,
+    term              VARCHAR(100)  NULL,
+    amount            DECIMAL(12,2) NOT NULL,
+    payment_method    VARCHAR(50)   NOT NULL,
+    reference_number  VARCHAR(100)  NULL,
+    description       VARCHAR(255)  NULL,
+    balance_after     DECIMAL(12,2) NOT NULL,
+    issued_at         DATETIME      NOT NULL,
+    issued_by         VARCHAR(255)  NOT NULL,
+    content_hash      CHAR(64)      NOT NULL,
+    PRIMARY KEY (or_number),
+    UNIQUE KEY uq_receipts_payment (payment_id),
+    KEY idx_receipts_student (student_ID)
+);
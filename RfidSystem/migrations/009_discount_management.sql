@@ -0,0 +1,21 @@
+-- Discount types are retired instead of deleted, so discounts already granted
+-- keep their name and rule.
+ALTER TABLE DiscountTypes
+    ADD COLUMN is_active TINYINT(1) NOT NULL DEFAULT 1;
+
+-- Every discount granted to or revoked from an assessment through the admin
+-- API, with who did it and why. Rows are never updated or deleted.
+CREATE TABLE IF NOT EXISTS DiscountAudit (
+    audit_id               BIGINT        NOT NULL AUTO_INCREMENT,
+    assessment_number      BIGINT        NOT NULL,
+    assessment_discount_id BIGINT        NOT NULL,
+    discount_type_id       BIGINT        NOT NULL,
+    action                 ENUM('granted', 'revoked') NOT NULL,
+    applied_amount         DECIMAL(12,2) NOT NULL,
+    calculation_basis      DECIMAL(12,2) NULL,
+    performed_by           VARCHAR(255)  NOT NULL,
+    reason                 VARCHAR(255)  NULL,
+    created_at             DATETIME      NOT NULL,
+    PRIMARY KEY (audit_id),
+    KEY idx_discount_audit_assessment (assessment_number, created_at)
+);
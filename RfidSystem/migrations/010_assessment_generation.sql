@@ -0,0 +1,22 @@
+-- Tuition is charged per unit of the subjects a student is enrolled in.
+ALTER TABLE Subjects
+    ADD COLUMN units DECIMAL(4,1) NOT NULL DEFAULT 3.0;
+
+-- The fees charged when an assessment is generated. A row with a NULL term_id
+-- or program applies to every term or program; the most specific row of a fee
+-- type wins. Per-unit fees (tuition) are multiplied by the enrolled units.
+CREATE TABLE IF NOT EXISTS FeeSchedules (
+    fee_schedule_id BIGINT        NOT NULL AUTO_INCREMENT,
+    fee_type_id     BIGINT        NOT NULL,
+    term_id         BIGINT        NULL,
+    program         VARCHAR(100)  NULL,
+    amount          DECIMAL(12,2) NOT NULL,
+    per_unit        TINYINT(1)    NOT NULL DEFAULT 0,
+    PRIMARY KEY (fee_schedule_id),
+    UNIQUE KEY uq_fee_schedules (fee_type_id, term_id, program)
+);
+
+-- Generated assessments record who generated them and when.
+ALTER TABLE Assessment
+    ADD COLUMN generated_by VARCHAR(255) NULL,
+    ADD COLUMN generated_at DATETIME NULL;
@@ -0,0 +1,23 @@
+-- Faculty accounts (role 'faculty') encode grades for the classes assigned to them.
+CREATE TABLE IF NOT EXISTS FacultyAssignments (
+    assignment_id BIGINT       NOT NULL AUTO_INCREMENT,
+    user_id       BIGINT       NOT NULL,
+    subject_Code  VARCHAR(50)  NOT NULL,
+    term_id       BIGINT       NOT NULL,
+    block_section VARCHAR(50)  NOT NULL,
+    PRIMARY KEY (assignment_id),
+    UNIQUE KEY uq_faculty_assignments (user_id, subject_Code, term_id, block_section),
+    KEY idx_faculty_assignments_class (subject_Code, term_id, block_section),
+    CONSTRAINT fk_faculty_assignments_user FOREIGN KEY (user_id) REFERENCES Users (user_id) ON DELETE CASCADE
+);
+
+-- A term's grades are locked once grades_locked_at is set, or after its
+-- grade_deadline has passed.
+ALTER TABLE AcademicTerms
+    ADD COLUMN grade_deadline   DATE     NULL,
+    ADD COLUMN grades_locked_at DATETIME NULL;
+
+-- Who last changed an enrollment's grades, and when.
+ALTER TABLE Enrollments
+    ADD COLUMN grades_updated_by VARCHAR(255) NULL,
+    ADD COLUMN grades_updated_at DATETIME     NULL;
@@ -0,0 +1,4 @@
+-- An enrollment marked 'INC' (incomplete) or 'DRP' (dropped) shows that remark
+-- instead of passed/failed and is left out of the student's GWA.
+ALTER TABLE Enrollments
+    ADD COLUMN grade_remark VARCHAR(3) NULL;
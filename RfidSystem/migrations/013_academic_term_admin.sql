@@ -0,0 +1,4 @@
+-- The active term is the current term. At most one term is active; when none
+-- is, the current term is the one whose dates include today.
+ALTER TABLE AcademicTerms
+    ADD COLUMN is_active TINYINT(1) NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+-- One row per committed bulk import of students, enrollments, card assignments
+-- or payments. Dry runs and rejected files are not recorded.
+CREATE TABLE IF NOT EXISTS ImportHistory (
+    import_id   BIGINT       NOT NULL AUTO_INCREMENT,
+    import_type VARCHAR(20)  NOT NULL,
+    file_name   VARCHAR(255) NOT NULL,
+    row_count   INT          NOT NULL,
+    imported_by VARCHAR(255) NOT NULL,
+    imported_at DATETIME     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (import_id),
+    KEY idx_import_history_type (import_type, imported_at)
+);
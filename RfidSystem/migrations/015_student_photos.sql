@@ -0,0 +1,4 @@
+-- When the student's photo was last uploaded; NULL if they have none and the
+-- kiosk shows the placeholder. The photo files themselves are kept in PHOTO_DIR.
+ALTER TABLE Students
+    ADD COLUMN photo_updated_at DATETIME NULL;
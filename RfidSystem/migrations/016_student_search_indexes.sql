@@ -0,0 +1,14 @@
+-- Indexes for the student search. Search words are matched against the names
+-- and email through the full-text index, so names shorter than
+-- innodb_ft_min_token_size (3 by default) or on the InnoDB stopword list are
+-- only found if the server is configured with innodb_ft_min_token_size = 1 and
+-- innodb_ft_enable_stopword = OFF before this migration is applied.
+-- The other indexes follow the sort orders of the search, NULLs sorted as
+-- empty values as the search compares them, so a page is read in index order.
+-- Indexes on expressions need MySQL 8.0.13 or later.
+ALTER TABLE Students
+    ADD FULLTEXT KEY idx_students_search (first_Name, middle_Name, last_Name, email),
+    ADD KEY idx_students_name ((COALESCE(last_Name, '')), (COALESCE(first_Name, '')), student_ID),
+    ADD KEY idx_students_program ((COALESCE(program, '')), student_ID),
+    ADD KEY idx_students_year_level ((COALESCE(year_Level, 0)), student_ID),
+    ADD KEY idx_students_block_section ((COALESCE(block_section, '')), student_ID);